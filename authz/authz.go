@@ -81,6 +81,7 @@ p, *, *, POST, /api/ott/auth-code, *, *
 p, *, *, POST, /api/ott/reset-pwd, *, *
 p, *, *, GET, /api/get-app-login, *, *
 p, *, *, POST, /api/logout, *, *
+p, *, *, GET, /api/get-frontchannel-logout-uris, *, *
 p, *, *, POST, /api/ott/logout, *, *
 p, *, *, GET, /api/get-account, *, *
 p, *, *, GET, /api/userinfo, *, *
@@ -89,6 +90,8 @@ p, *, *, GET, /api/get-application, *, *
 p, *, *, GET, /api/get-applications, *, *
 p, *, *, GET, /api/get-user, *, *
 p, *, *, GET, /api/get-user-application, *, *
+p, *, *, GET, /api/get-user-consents, *, *
+p, *, *, POST, /api/revoke-consent, *, *
 p, *, *, GET, /api/get-resources, *, *
 p, *, *, GET, /api/get-product, *, *
 p, *, *, POST, /api/buy-product, *, *
@@ -104,7 +107,9 @@ p, *, *, GET, /api/get-human-check, *, *
 p, *, *, POST, /api/reset-email-or-phone, *, *
 p, *, *, POST, /api/upload-resource, *, *
 p, *, *, GET, /.well-known/openid-configuration, *, *
+p, *, *, GET, /.well-known/oauth-authorization-server, *, *
 p, *, *, *, /.well-known/jwks, *, *
+p, *, *, *, /api/oauth/register, *, *
 p, *, *, GET, /api/get-saml-login, *, *
 p, *, *, POST, /api/acs, *, *
 p, *, *, GET, /api/saml/metadata, *, *
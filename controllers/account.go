@@ -69,6 +69,7 @@ type RequestForm struct {
 type OTTLoginRequest struct {
 	AppId            string  `json:"app_id"`                      // light-wallet for light-wallet app
 	ClientId         string  `json:"client_id"`                   // light-wallet SSO client ID
+	RedirectUri      string  `json:"redirect_uri"`                // one of the redirect URIs of the SSO client (optional)
 	Identity         string  `json:"identity"`                    // phone number or email address
 	Prefix           *string `json:"prefix,omitempty"`            // phone prefix (if use phone login)
 	Password         *string `json:"password,omitempty"`          // password (if use password login)
@@ -205,6 +206,7 @@ func (c *ApiController) Signup() {
 		Avatar:            organization.DefaultAvatar,
 		Email:             form.Email,
 		Phone:             form.Phone,
+		PhoneVerified:     checkPhone != "",
 		Address:           []string{},
 		Affiliation:       form.Affiliation,
 		IdCard:            form.IdCard,
@@ -343,6 +345,7 @@ func (c *ApiController) OTTSignup() {
 		Avatar:            organization.DefaultAvatar,
 		Email:             email,
 		Phone:             phone,
+		PhoneVerified:     phone != "",
 		Address:           []string{},
 		Affiliation:       "",
 		IdCard:            "",
@@ -411,7 +414,7 @@ func (c *ApiController) OTTSignup() {
 // Logout
 // @Title Logout
 // @Tag Login API
-// @Description logout the current user
+// @Description logout the current user, the applications that the user has signed in to are notified by the back-channel logout
 // @Success 200 {object} controllers.Response The Response object
 // @router /logout [post]
 func (c *ApiController) Logout() {
@@ -422,6 +425,9 @@ func (c *ApiController) Logout() {
 	c.SetSessionUsername("")
 	c.SetSessionData(nil)
 
+	// the front-channel logout URIs are loaded by the frontend, see GetFrontchannelLogoutUris()
+	object.LogoutAppSessions(user, c.Ctx.Request.Host)
+
 	if application == nil || application.Name == "app-built-in" || application.HomepageUrl == "" {
 		c.ResponseOk(user)
 		return
@@ -472,7 +478,7 @@ func (c *ApiController) GetAccount() {
 // UserInfo
 // @Title UserInfo
 // @Tag Account API
-// @Description return user information according to OIDC standards
+// @Description return user information according to OIDC standards, in a signed or encrypted JWT (application/jwt) if the application asks for it
 // @Success 200 {object} object.Userinfo The Response object
 // @router /userinfo [get]
 func (c *ApiController) GetUserinfo() {
@@ -487,6 +493,23 @@ func (c *ApiController) GetUserinfo() {
 		c.ResponseError(err.Error())
 		return
 	}
+
+	application := object.GetApplicationByClientId(aud)
+	if application != nil && application.HasJwtUserinfo() {
+		token, err := object.GetUserinfoJwt(application, resp)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.Ctx.Output.Header("Content-Type", "application/jwt")
+		err = c.Ctx.Output.Body([]byte(token))
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	c.Data["json"] = resp
 	c.ServeJSON()
 }
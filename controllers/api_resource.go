@@ -0,0 +1,128 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/astaxie/beego/utils/pagination"
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// GetApiResources
+// @Title GetApiResources
+// @Tag API Resource API
+// @Description get API resources
+// @Param   owner     query    string  true        "The owner of API resources"
+// @Success 200 {array} object.ApiResource The Response object
+// @router /get-api-resources [get]
+func (c *ApiController) GetApiResources() {
+	owner := c.Input().Get("owner")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+	if limit == "" || page == "" {
+		c.Data["json"] = object.GetApiResources(owner)
+		c.ServeJSON()
+	} else {
+		limit := util.ParseInt(limit)
+		paginator := pagination.SetPaginator(c.Ctx, limit, int64(object.GetApiResourceCount(owner, field, value)))
+		apiResources := object.GetPaginationApiResources(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		c.ResponseOk(apiResources, paginator.Nums())
+	}
+}
+
+// @Title GetApiResource
+// @Tag API Resource API
+// @Description get API resource
+// @Param   id    query    string  true        "The id of the API resource"
+// @Success 200 {object} object.ApiResource The Response object
+// @router /get-api-resource [get]
+func (c *ApiController) GetApiResource() {
+	id := c.Input().Get("id")
+
+	c.Data["json"] = object.GetApiResource(id)
+	c.ServeJSON()
+}
+
+// @Title UpdateApiResource
+// @Tag API Resource API
+// @Description update API resource
+// @Param   id    query    string  true        "The id of the API resource"
+// @Param   body    body   object.ApiResource  true        "The details of the API resource"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-api-resource [post]
+func (c *ApiController) UpdateApiResource() {
+	id := c.Input().Get("id")
+
+	var apiResource object.ApiResource
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &apiResource)
+	if err != nil {
+		panic(err)
+	}
+
+	err = apiResource.CheckIdentifier()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.UpdateApiResource(id, &apiResource))
+	c.ServeJSON()
+}
+
+// @Title AddApiResource
+// @Tag API Resource API
+// @Description add API resource
+// @Param   body    body   object.ApiResource  true        "The details of the API resource"
+// @Success 200 {object} controllers.Response The Response object
+// @router /add-api-resource [post]
+func (c *ApiController) AddApiResource() {
+	var apiResource object.ApiResource
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &apiResource)
+	if err != nil {
+		panic(err)
+	}
+
+	err = apiResource.CheckIdentifier()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.AddApiResource(&apiResource))
+	c.ServeJSON()
+}
+
+// @Title DeleteApiResource
+// @Tag API Resource API
+// @Description delete API resource
+// @Param   body    body   object.ApiResource  true        "The details of the API resource"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-api-resource [post]
+func (c *ApiController) DeleteApiResource() {
+	var apiResource object.ApiResource
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &apiResource)
+	if err != nil {
+		panic(err)
+	}
+
+	c.Data["json"] = wrapActionResponse(object.DeleteApiResource(&apiResource))
+	c.ServeJSON()
+}
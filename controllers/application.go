@@ -111,6 +111,30 @@ func (c *ApiController) UpdateApplication() {
 		panic(err)
 	}
 
+	err = application.CheckRedirectUris()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	err = application.CheckClaimTemplates()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	err = application.CheckUserinfoResponse()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	err = application.CheckBackchannelAuthentication()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
 	c.Data["json"] = wrapActionResponse(object.UpdateApplication(id, &application))
 	c.ServeJSON()
 }
@@ -129,6 +153,30 @@ func (c *ApiController) AddApplication() {
 		panic(err)
 	}
 
+	err = application.CheckRedirectUris()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	err = application.CheckClaimTemplates()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	err = application.CheckUserinfoResponse()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	err = application.CheckBackchannelAuthentication()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
 	c.Data["json"] = wrapActionResponse(object.AddApplication(&application))
 	c.ServeJSON()
 }
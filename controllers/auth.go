@@ -51,17 +51,44 @@ func ottCodeToResponse(code *object.Code) *OTTResponse {
 	return &OTTResponse{Code: OTT_CODE_OK, Data: CodeResponse{Code: code.Code}}
 }
 
-func tokenToResponse(token *object.Token) *Response {
-	if token.AccessToken == "" {
-		return &Response{Status: "error", Msg: "fail to get accessToken", Data: token.AccessToken}
+// getAuthenticationMethod returns the authentication method of the sign-in form,
+// or "" if the user continues as the signed-in user of the session
+func getAuthenticationMethod(form *RequestForm) string {
+	if form.Username != "" {
+		if form.Password != "" {
+			return object.AmrPassword
+		}
+		if strings.Contains(form.Username, "@") {
+			return object.AmrOtp
+		}
+		return object.AmrSms
 	}
-	return &Response{Status: "ok", Msg: "", Data: token.AccessToken}
+	if form.Provider != "" {
+		return object.AmrFederated
+	}
+	return ""
+}
 
+// getAuthentication returns the authentication of the user for the sign-in form: a new one if the user has just
+// authenticated with the form, or the one of the session if the user continues as the signed-in user
+func (c *ApiController) getAuthentication(userId string, form *RequestForm) *object.Authentication {
+	method := getAuthenticationMethod(form)
+	if method != "" {
+		return c.addSessionAuthentication(userId, method)
+	}
+
+	authentication := c.getSessionAuthentication(userId)
+	if authentication == nil {
+		authentication = &object.Authentication{User: userId}
+	}
+	authentication.IsNew = c.isReauthenticated(c.Input().Get("clientId"))
+	return authentication
 }
 
 // HandleLoggedIn ...
 func (c *ApiController) HandleLoggedIn(application *object.Application, user *object.User, form *RequestForm) (resp *Response) {
 	userId := user.GetId()
+	authentication := c.getAuthentication(userId, form)
 	if form.Type == ResponseTypeLogin {
 		c.SetSessionUsername(userId)
 		util.LogInfo(c.Ctx, "API: [%s] signed in", userId)
@@ -80,20 +107,50 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 			c.ResponseError("Challenge method should be S256")
 			return
 		}
-		code := object.GetOAuthCode(userId, clientId, responseType, redirectUri, scope, state, nonce, codeChallenge, c.Ctx.Request.Host)
+		request := &object.AuthorizationRequest{
+			ResponseType:   responseType,
+			RedirectUri:    redirectUri,
+			Scope:          scope,
+			State:          state,
+			Nonce:          nonce,
+			CodeChallenge:  codeChallenge,
+			Resources:      c.Input()["resource"],
+			Prompt:         c.Input().Get("prompt"),
+			AcrValues:      c.Input().Get("acr_values"),
+			MaxAge:         c.Input().Get("max_age"),
+			ConsentGranted: c.isConsentGranted(clientId),
+			Authentication: authentication,
+		}
+		code := object.GetOAuthCode(userId, clientId, request, c.Ctx.Request.Host, c.Input().Get("request_uri"))
 		resp = codeToResponse(code)
+		c.handleAuthorizationError(resp, userId, clientId, code.Message, authentication)
 
 		if application.EnableSigninSession || application.HasPromptPage() {
 			// The prompt page needs the user to be signed in
 			c.SetSessionUsername(userId)
 		}
-	} else if form.Type == ResponseTypeToken || form.Type == ResponseTypeIdToken { //implicit flow
-		if !object.IsGrantTypeValid(form.Type, application.GrantTypes) {
-			resp = &Response{Status: "error", Msg: fmt.Sprintf("error: grant_type: %s is not supported in this application", form.Type), Data: ""}
+	} else if object.IsImplicitResponseType(form.Type) { // implicit flow and hybrid flow
+		request := &object.AuthorizationRequest{
+			ResponseType:   form.Type,
+			ResponseMode:   c.Input().Get("response_mode"),
+			RedirectUri:    c.Input().Get("redirectUri"),
+			Scope:          c.Input().Get("scope"),
+			State:          c.Input().Get("state"),
+			Nonce:          c.Input().Get("nonce"),
+			CodeChallenge:  c.Input().Get("code_challenge"),
+			Resources:      c.Input()["resource"],
+			Prompt:         c.Input().Get("prompt"),
+			AcrValues:      c.Input().Get("acr_values"),
+			MaxAge:         c.Input().Get("max_age"),
+			ConsentGranted: c.isConsentGranted(c.Input().Get("clientId")),
+			Authentication: authentication,
+		}
+		response, responseMode, err := object.GetOAuthResponse(userId, c.Input().Get("clientId"), request, c.Ctx.Request.Host, c.Input().Get("request_uri"))
+		if err != nil {
+			resp = wrapErrorResponse(err)
+			c.handleAuthorizationError(resp, userId, c.Input().Get("clientId"), err.Error(), authentication)
 		} else {
-			scope := c.Input().Get("scope")
-			token, _ := object.GetTokenByUser(application, user, scope, c.Ctx.Request.Host)
-			resp = tokenToResponse(token)
+			resp = &Response{Status: "ok", Msg: "", Data: response, Data2: responseMode}
 		}
 
 	} else if form.Type == ResponseTypeSaml { // saml flow
@@ -124,8 +181,8 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 		resp = wrapErrorResponse(fmt.Errorf("Unknown response type: %s", form.Type))
 	}
 
-	// if user did not check auto signin
-	if resp.Status == "ok" && !form.AutoSignin {
+	// if user did not check auto signin, the session for the consent page and the step-up sign in expires as well
+	if (resp.Status == "ok" || resp.Data == object.ConsentRequired || resp.Data == object.UnmetAuthenticationRequirements) && !form.AutoSignin {
 		timestamp := time.Now().Unix()
 		timestamp += 3600 * 24
 		c.SetSessionData(&SessionData{
@@ -136,6 +193,25 @@ func (c *ApiController) HandleLoggedIn(application *object.Application, user *ob
 	return resp
 }
 
+// handleAuthorizationError lets the frontend tell the errors of an authorization request that the user can resolve:
+// the consent page and the step-up sign in need the user to be signed in, and a user who has just authenticated
+// for the request is still newly authenticated for the request that follows the consent
+func (c *ApiController) handleAuthorizationError(resp *Response, userId string, clientId string, msg string, authentication *object.Authentication) {
+	if object.IsConsentRequired(msg) {
+		c.SetSessionUsername(userId)
+		if authentication.IsNew {
+			c.SetSession("reauthenticated", clientId)
+		}
+		resp.Data = object.ConsentRequired
+	} else if loginRequired := object.GetLoginRequiredError(msg); loginRequired != "" {
+		if loginRequired == object.UnmetAuthenticationRequirements {
+			// the user signs in again with another factor on top of this one
+			c.SetSessionUsername(userId)
+		}
+		resp.Data = loginRequired
+	}
+}
+
 func (c *ApiController) OTTHandleLoggedIn(application *object.Application, user *object.User, form *OTTLoginRequest) (resp *OTTResponse) {
 	userId := user.GetId()
 	clientId := form.ClientId
@@ -149,7 +225,30 @@ func (c *ApiController) OTTHandleLoggedIn(application *object.Application, user
 		c.ResponseError("Challenge method should be S256")
 		return
 	}
-	code := object.GetOAuthCode(userId, clientId, "code", "", scope, state, nonce, codeChallenge, c.Ctx.Request.Host)
+	var authentication *object.Authentication
+	if form.Password != nil {
+		authentication = c.addSessionAuthentication(userId, object.AmrPassword)
+	} else if form.VerificationCode != nil {
+		if strings.Contains(form.Identity, "@") {
+			authentication = c.addSessionAuthentication(userId, object.AmrOtp)
+		} else {
+			authentication = c.addSessionAuthentication(userId, object.AmrSms)
+		}
+	} else {
+		authentication = c.getSessionAuthentication(userId)
+	}
+
+	request := &object.AuthorizationRequest{
+		ResponseType:   "code",
+		RedirectUri:    form.RedirectUri,
+		Scope:          scope,
+		State:          state,
+		Nonce:          nonce,
+		CodeChallenge:  codeChallenge,
+		Authentication: authentication,
+		FirstParty:     true,
+	}
+	code := object.GetOAuthCode(userId, clientId, request, c.Ctx.Request.Host, "")
 	resp = ottCodeToResponse(code)
 
 	if application.EnableSigninSession || application.HasPromptPage() {
@@ -178,6 +277,7 @@ func (c *ApiController) OTTHandleLoggedIn(application *object.Application, user
 // @Param   redirectUri    query    string  true        "redirect uri"
 // @Param   scope    query    string  true        "scope"
 // @Param   state    query    string  true        "state"
+// @Param   request_uri    query    string  false        "the request_uri of a pushed authorization request"
 // @Success 200 {object} controllers.api_controller.Response The Response object
 // @router /get-app-login [get]
 func (c *ApiController) GetApplicationLogin() {
@@ -186,14 +286,27 @@ func (c *ApiController) GetApplicationLogin() {
 	redirectUri := c.Input().Get("redirectUri")
 	scope := c.Input().Get("scope")
 	state := c.Input().Get("state")
+	requestUri := c.Input().Get("request_uri")
 
-	msg, application := object.CheckOAuthLogin(clientId, responseType, redirectUri, scope, state)
+	msg, application := object.CheckOAuthLogin(clientId, responseType, redirectUri, scope, state, requestUri)
 	application = object.GetMaskedApplication(application, "")
 	if msg != "" {
 		c.ResponseError(msg, application)
-	} else {
-		c.ResponseOk(application)
+		return
+	}
+
+	if requestUri != "" {
+		// the login page needs the pushed parameters to finish the authorization request
+		params, err := object.GetPushedAuthRequestParameters(clientId, requestUri)
+		if err != nil {
+			c.ResponseError(err.Error(), application)
+			return
+		}
+		c.ResponseOk(application, params)
+		return
 	}
+
+	c.ResponseOk(application)
 }
 
 func setHttpClient(idProvider idp.IdProvider, providerType string) {
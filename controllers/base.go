@@ -134,6 +134,38 @@ func (c *ApiController) SetSessionData(s *SessionData) {
 	c.SetSession("SessionData", util.StructToJson(s))
 }
 
+// getSessionAuthentication returns when and how the user of the session has authenticated
+func (c *ApiController) getSessionAuthentication(userId string) *object.Authentication {
+	session := c.GetSession("authentication")
+	if session == nil {
+		return nil
+	}
+
+	authentication := &object.Authentication{}
+	err := util.JsonToStruct(session.(string), authentication)
+	if err != nil {
+		panic(err)
+	}
+
+	if authentication.User != userId {
+		return nil
+	}
+	return authentication
+}
+
+// addSessionAuthentication records that the user has just authenticated with the method, on top of
+// the methods that the user has authenticated with in the session
+func (c *ApiController) addSessionAuthentication(userId string, method string) *object.Authentication {
+	var previous *object.Authentication
+	if c.GetSessionUsername() == userId {
+		previous = c.getSessionAuthentication(userId)
+	}
+
+	authentication := object.NewAuthentication(userId, method, previous)
+	c.SetSession("authentication", util.StructToJson(authentication))
+	return authentication
+}
+
 func wrapActionResponse(affected bool) *Response {
 	if affected {
 		return &Response{Status: "ok", Msg: "", Data: "Affected"}
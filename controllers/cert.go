@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/astaxie/beego/utils/pagination"
 	"github.com/casdoor/casdoor/object"
@@ -77,7 +78,18 @@ func (c *ApiController) UpdateCert() {
 		panic(err)
 	}
 
-	c.Data["json"] = wrapActionResponse(object.UpdateCert(id, &cert))
+	if !object.IsCryptoAlgorithmSupported(cert.CryptoAlgorithm) {
+		c.ResponseError(fmt.Sprintf("The crypto algorithm: %s is not supported", cert.CryptoAlgorithm))
+		return
+	}
+
+	affected, err := object.UpdateCert(id, &cert)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(affected)
 	c.ServeJSON()
 }
 
@@ -94,6 +106,11 @@ func (c *ApiController) AddCert() {
 		panic(err)
 	}
 
+	if !object.IsCryptoAlgorithmSupported(cert.CryptoAlgorithm) {
+		c.ResponseError(fmt.Sprintf("The crypto algorithm: %s is not supported", cert.CryptoAlgorithm))
+		return
+	}
+
 	c.Data["json"] = wrapActionResponse(object.AddCert(&cert))
 	c.ServeJSON()
 }
@@ -114,3 +131,41 @@ func (c *ApiController) DeleteCert() {
 	c.Data["json"] = wrapActionResponse(object.DeleteCert(&cert))
 	c.ServeJSON()
 }
+
+// @Title PrepareCertNextKey
+// @Tag Cert API
+// @Description generate the next key of the cert, it is published in the JWKS before the rotation makes it sign the tokens
+// @Param   id    query    string  true        "The id of the cert"
+// @Success 200 {object} controllers.Response The Response object
+// @router /prepare-cert-next-key [post]
+func (c *ApiController) PrepareCertNextKey() {
+	id := c.Input().Get("id")
+
+	affected, err := object.PrepareCertNextKey(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(affected)
+	c.ServeJSON()
+}
+
+// @Title RotateCert
+// @Tag Cert API
+// @Description rotate the key of the cert, the current key is kept as the previous key until its tokens expire
+// @Param   id    query    string  true        "The id of the cert"
+// @Success 200 {object} controllers.Response The Response object
+// @router /rotate-cert [post]
+func (c *ApiController) RotateCert() {
+	id := c.Input().Get("id")
+
+	affected, err := object.RotateCert(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(affected)
+	c.ServeJSON()
+}
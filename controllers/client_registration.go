@@ -0,0 +1,155 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/casdoor/casdoor/object"
+)
+
+// getBearerToken returns the token sent like "Authorization: Bearer 123"
+func (c *ApiController) getBearerToken() string {
+	header := c.Ctx.Request.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimPrefix(header, "Bearer ")
+}
+
+func (c *ApiController) responseClientRegistrationError(tokenError *object.TokenError) {
+	if tokenError.Error == "invalid_token" {
+		c.Ctx.Output.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.Ctx.Output.SetStatus(http.StatusUnauthorized)
+	} else {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+	}
+	c.Data["json"] = tokenError
+	c.ServeJSON()
+}
+
+func (c *ApiController) getClientMetadata() (*object.ClientMetadata, bool) {
+	var metadata object.ClientMetadata
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &metadata)
+	if err != nil {
+		c.responseClientRegistrationError(&object.TokenError{Error: "invalid_client_metadata", ErrorDescription: err.Error()})
+		return nil, false
+	}
+
+	return &metadata, true
+}
+
+// RegisterClient
+// @Title RegisterClient
+// @Tag Client Registration API
+// @Description register an OAuth client (application) dynamically with the initial access token of an organization, per rfc 7591.
+// @Param   Authorization     header    string  true        "Bearer {initial access token}"
+// @Param   body    body   object.ClientMetadata  true        "The client metadata"
+// @Success 201 {object} object.ClientRegistrationResponse The Response object
+// @router /oauth/register [post]
+func (c *ApiController) RegisterClient() {
+	metadata, ok := c.getClientMetadata()
+	if !ok {
+		return
+	}
+
+	resp, tokenError := object.RegisterClient(c.getBearerToken(), metadata, c.Ctx.Request.Host)
+	if tokenError != nil {
+		c.responseClientRegistrationError(tokenError)
+		return
+	}
+
+	c.Ctx.Output.Header("Cache-Control", "no-store")
+	c.Ctx.Output.SetStatus(http.StatusCreated)
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
+
+// GetRegisteredClient
+// @Title GetRegisteredClient
+// @Tag Client Registration API
+// @Description get the registered metadata of an OAuth client, per rfc 7592.
+// @Param   Authorization     header    string  true        "Bearer {registration access token}"
+// @Param   client_id     query    string  true        "OAuth client id"
+// @Success 200 {object} object.ClientRegistrationResponse The Response object
+// @router /oauth/register [get]
+func (c *ApiController) GetRegisteredClient() {
+	clientId := c.Input().Get("client_id")
+
+	resp, tokenError := object.GetRegisteredClient(clientId, c.getBearerToken(), c.Ctx.Request.Host)
+	if tokenError != nil {
+		c.responseClientRegistrationError(tokenError)
+		return
+	}
+
+	c.Ctx.Output.Header("Cache-Control", "no-store")
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
+
+// UpdateRegisteredClient
+// @Title UpdateRegisteredClient
+// @Tag Client Registration API
+// @Description replace the registered metadata of an OAuth client, per rfc 7592.
+// @Param   Authorization     header    string  true        "Bearer {registration access token}"
+// @Param   client_id     query    string  true        "OAuth client id"
+// @Param   body    body   object.ClientMetadata  true        "The client metadata"
+// @Success 200 {object} object.ClientRegistrationResponse The Response object
+// @router /oauth/register [put]
+func (c *ApiController) UpdateRegisteredClient() {
+	clientId := c.Input().Get("client_id")
+
+	metadata, ok := c.getClientMetadata()
+	if !ok {
+		return
+	}
+
+	resp, tokenError := object.UpdateRegisteredClient(clientId, c.getBearerToken(), metadata, c.Ctx.Request.Host)
+	if tokenError != nil {
+		c.responseClientRegistrationError(tokenError)
+		return
+	}
+
+	c.Ctx.Output.Header("Cache-Control", "no-store")
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
+
+// DeleteRegisteredClient
+// @Title DeleteRegisteredClient
+// @Tag Client Registration API
+// @Description delete a dynamically registered OAuth client, per rfc 7592.
+// @Param   Authorization     header    string  true        "Bearer {registration access token}"
+// @Param   client_id     query    string  true        "OAuth client id"
+// @Success 204
+// @router /oauth/register [delete]
+func (c *ApiController) DeleteRegisteredClient() {
+	clientId := c.Input().Get("client_id")
+
+	tokenError := object.DeleteRegisteredClient(clientId, c.getBearerToken())
+	if tokenError != nil {
+		c.responseClientRegistrationError(tokenError)
+		return
+	}
+
+	c.Ctx.Output.SetStatus(http.StatusNoContent)
+	err := c.Ctx.Output.Body([]byte{})
+	if err != nil {
+		panic(err)
+	}
+}
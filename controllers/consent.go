@@ -0,0 +1,148 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+// isConsentGranted tells whether the user has just consented to the authorization request of the client
+// on the consent page. The consent is used up by the request that follows it.
+func (c *ApiController) isConsentGranted(clientId string) bool {
+	consentGranted, ok := c.GetSession("consentGranted").(string)
+	if !ok {
+		return false
+	}
+
+	c.DelSession("consentGranted")
+	return clientId != "" && consentGranted == clientId
+}
+
+// isReauthenticated tells whether the user has just authenticated for the authorization request of the client
+// before consenting to it, the request that follows the consent counts as newly authenticated then
+func (c *ApiController) isReauthenticated(clientId string) bool {
+	reauthenticated, ok := c.GetSession("reauthenticated").(string)
+	if !ok {
+		return false
+	}
+
+	c.DelSession("reauthenticated")
+	return clientId != "" && reauthenticated == clientId
+}
+
+// canManageConsents tells whether the signed-in user can see and revoke the consents of the user:
+// the user, the administrators of the organization of the user and the global administrators can
+func (c *ApiController) canManageConsents(userId string) bool {
+	if c.IsGlobalAdmin() {
+		return true
+	}
+
+	sessionUserId := c.GetSessionUsername()
+	if sessionUserId == userId {
+		return true
+	}
+
+	sessionUser := object.GetUser(sessionUserId)
+	owner, _ := util.GetOwnerAndNameFromIdNoCheck(userId)
+	return sessionUser != nil && sessionUser.IsAdmin && sessionUser.Owner == owner
+}
+
+// GrantConsent
+// @Title GrantConsent
+// @Tag Consent API
+// @Description consent to the authorization request of the client as the signed-in user
+// @Param   clientId     query    string  true        "The client id of the application"
+// @Param   scope     query    string  true        "The scope of the request"
+// @Param   resource     query    string  false        "The API resource of the request, can be repeated"
+// @Param   request_uri     query    string  false        "The request_uri of the pushed authorization request"
+// @Success 200 {object} controllers.Response The Response object
+// @router /login/oauth/consent [post]
+func (c *ApiController) GrantConsent() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	clientId := c.Input().Get("clientId")
+	request := &object.AuthorizationRequest{
+		Scope:     c.Input().Get("scope"),
+		Resources: c.Input()["resource"],
+	}
+	err := object.GrantConsent(userId, clientId, c.Input().Get("request_uri"), request)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.SetSession("consentGranted", clientId)
+	c.ResponseOk()
+}
+
+// GetUserConsents
+// @Title GetUserConsents
+// @Tag Consent API
+// @Description get the consents that the user has granted the applications
+// @Param   id     query    string  false        "The id of the user, the signed-in user by default"
+// @Success 200 {array} object.Consent The Response object
+// @router /get-user-consents [get]
+func (c *ApiController) GetUserConsents() {
+	sessionUserId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	userId := c.Input().Get("id")
+	if userId == "" {
+		userId = sessionUserId
+	}
+	if !c.canManageConsents(userId) {
+		c.ResponseError("Unauthorized operation")
+		return
+	}
+
+	owner, name := util.GetOwnerAndNameFromIdNoCheck(userId)
+	c.ResponseOk(object.GetUserConsents(owner, name))
+}
+
+// RevokeConsent
+// @Title RevokeConsent
+// @Tag Consent API
+// @Description revoke a consent, along with the tokens that the user has granted the application
+// @Param   id     query    string  true        "The id of the consent"
+// @Success 200 {object} controllers.Response The Response object
+// @router /revoke-consent [post]
+func (c *ApiController) RevokeConsent() {
+	_, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+	consent := object.GetConsent(id)
+	if consent == nil {
+		c.ResponseError(fmt.Sprintf("The consent: %s doesn't exist", id))
+		return
+	}
+	if !c.canManageConsents(fmt.Sprintf("%s/%s", consent.Owner, consent.User)) {
+		c.ResponseError("Unauthorized operation")
+		return
+	}
+
+	c.Data["json"] = wrapActionResponse(object.RevokeConsent(consent))
+	c.ServeJSON()
+}
@@ -0,0 +1,33 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import "github.com/casdoor/casdoor/object"
+
+// RunJanitor
+// @Title RunJanitor
+// @Tag Token API
+// @Description remove the expired tokens, codes and verification records now, and return the number of rows removed from each table
+// @Success 200 {object} controllers.Response The Response object
+// @router /run-janitor [post]
+func (c *ApiController) RunJanitor() {
+	report, err := object.RunJanitor()
+	if err != nil {
+		c.ResponseError(err.Error(), report)
+		return
+	}
+
+	c.ResponseOk(report)
+}
@@ -0,0 +1,250 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/casdoor/casdoor/object"
+	"github.com/casdoor/casdoor/util"
+)
+
+var frontchannelLogoutTemplate = template.Must(template.New("frontchannel-logout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Logging out...</title>
+</head>
+<body>
+  <p>{{if .RedirectUri}}Logging out...{{else}}You have logged out.{{end}}</p>
+  {{range .Uris}}<iframe src="{{.}}" style="display: none" onload="done()" onerror="done()"></iframe>
+  {{end}}
+  <script>
+    var redirectUri = {{.RedirectUri}};
+    var pending = {{len .Uris}};
+    var redirected = false;
+    function redirect() {
+      if (redirected || redirectUri === "") {
+        return;
+      }
+      redirected = true;
+      window.location.replace(redirectUri);
+    }
+    function done() {
+      pending--;
+      if (pending <= 0) {
+        redirect();
+      }
+    }
+    // don't let an unresponsive application hold the logout
+    setTimeout(redirect, 5000);
+  </script>
+</body>
+</html>
+`))
+
+var logoutConfirmationTemplate = template.Must(template.New("logout-confirmation").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Log out</title>
+</head>
+<body>
+  <p>Do you want to log out of Casdoor and the applications that you have signed in to?</p>
+  <form method="post" action="{{.Action}}">
+    {{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+    {{end}}<input type="hidden" name="logout_confirmation" value="{{.Confirmation}}">
+    <button type="submit">Log out</button>
+    <button type="button" onclick="history.back()">Cancel</button>
+  </form>
+</body>
+</html>
+`))
+
+// renderLogoutConfirmation renders a page that asks the user to confirm the logout, the confirmation is posted back
+// along with the parameters of the request and a one-time value kept in the session, so that another site can't
+// log the user out with a link or a form of its own
+func (c *ApiController) renderLogoutConfirmation(params map[string]string) {
+	confirmation := util.GenerateId()
+	c.SetSession("logoutConfirmation", confirmation)
+
+	var buf bytes.Buffer
+	err := logoutConfirmationTemplate.Execute(&buf, map[string]interface{}{
+		"Action":       c.Ctx.Request.URL.Path,
+		"Params":       params,
+		"Confirmation": confirmation,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	c.Ctx.Output.Header("Content-Type", "text/html; charset=utf-8")
+	c.Ctx.Output.Header("Cache-Control", "no-store")
+	err = c.Ctx.Output.Body(buf.Bytes())
+	if err != nil {
+		panic(err)
+	}
+}
+
+// isLogoutConfirmed tells whether the user has confirmed the logout on the confirmation page.
+// The confirmation is used up by the request that carries it.
+func (c *ApiController) isLogoutConfirmed() bool {
+	confirmation, ok := c.GetSession("logoutConfirmation").(string)
+	if !ok || c.Ctx.Request.Method != http.MethodPost {
+		return false
+	}
+
+	c.DelSession("logoutConfirmation")
+	return confirmation != "" && c.Input().Get("logout_confirmation") == confirmation
+}
+
+// renderFrontchannelLogout renders a page that loads the front-channel logout URIs of the applications
+// in hidden iframes, and then goes to the redirect URI.
+func (c *ApiController) renderFrontchannelLogout(uris []string, redirectUri string) {
+	var buf bytes.Buffer
+	err := frontchannelLogoutTemplate.Execute(&buf, map[string]interface{}{
+		"Uris":        uris,
+		"RedirectUri": redirectUri,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	c.Ctx.Output.Header("Content-Type", "text/html; charset=utf-8")
+	c.Ctx.Output.Header("Cache-Control", "no-store")
+	err = c.Ctx.Output.Body(buf.Bytes())
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GetFrontchannelLogoutUris
+// @Title GetFrontchannelLogoutUris
+// @Tag Login API
+// @Description get the front-channel logout URIs of the applications that the current user has signed in to,
+//  the frontend loads them in iframes when the user logs out
+// @Success 200 {object} controllers.Response The Response object
+// @router /get-frontchannel-logout-uris [get]
+func (c *ApiController) GetFrontchannelLogoutUris() {
+	user := c.GetSessionUsername()
+	if user == "" {
+		c.ResponseOk([]string{})
+		return
+	}
+
+	c.ResponseOk(object.GetFrontchannelLogoutUris(user, c.Ctx.Request.Host))
+}
+
+// EndSession
+// @Title EndSession
+// @Tag Login API
+// @Description the RP-initiated logout endpoint of OpenID Connect (end_session_endpoint): it asks the signed-in user
+//  to confirm, then clears the Casdoor session, logs the user out of the applications, and goes back to the post_logout_redirect_uri
+// @Param   id_token_hint     query    string  false        "The ID token that the application received for the user"
+// @Param   client_id     query    string  false        "OAuth client id, to check the post_logout_redirect_uri when there is no id_token_hint"
+// @Param   post_logout_redirect_uri    query    string  false      "One of the post logout redirect URIs of the application"
+// @Param   state     query    string  false        "Passed back to the post_logout_redirect_uri"
+// @Param   logout_confirmation     formData    string  false        "Posted by the confirmation page when the user confirms the logout"
+// @Success 302
+// @router /login/oauth/end_session [get]
+func (c *ApiController) EndSession() {
+	idTokenHint := c.Input().Get("id_token_hint")
+	clientId := c.Input().Get("client_id")
+	postLogoutRedirectUri := c.Input().Get("post_logout_redirect_uri")
+	state := c.Input().Get("state")
+
+	var application *object.Application
+	var hint *object.IdTokenHint
+	if idTokenHint != "" {
+		var err error
+		hint, err = object.ParseIdTokenHint(idTokenHint)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		application = hint.Application
+		if clientId != "" && clientId != application.ClientId {
+			c.ResponseError("The id_token_hint is not issued to the client_id")
+			return
+		}
+	} else if clientId != "" {
+		application = object.GetApplicationByClientId(clientId)
+		if application == nil {
+			c.ResponseError(fmt.Sprintf("The application with client_id: %s doesn't exist", clientId))
+			return
+		}
+	}
+
+	redirectUri := ""
+	if postLogoutRedirectUri != "" {
+		if application == nil {
+			c.ResponseError("The post_logout_redirect_uri should come along with id_token_hint or client_id")
+			return
+		}
+		if !application.IsPostLogoutRedirectUriValid(postLogoutRedirectUri) {
+			c.ResponseError(fmt.Sprintf("The post_logout_redirect_uri: %s is not allowed for the application: %s", postLogoutRedirectUri, application.Name))
+			return
+		}
+
+		u, err := url.Parse(postLogoutRedirectUri)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		if state != "" {
+			query := u.Query()
+			query.Set("state", state)
+			u.RawQuery = query.Encode()
+		}
+		redirectUri = u.String()
+	}
+
+	// the signed-in user of the browser is logged out of all the applications after confirming it, if the id_token_hint
+	// is for this user. Without a browser session, the hint only ends the session of the user in the application that sent it.
+	frontchannelLogoutUris := []string{}
+	if sessionUserId := c.GetSessionUsername(); sessionUserId != "" {
+		if hint != nil && !hint.IsOfUser(sessionUserId) {
+			c.ResponseError("The id_token_hint is not issued for the signed-in user")
+			return
+		}
+		if !c.isLogoutConfirmed() {
+			c.renderLogoutConfirmation(map[string]string{
+				"id_token_hint":            idTokenHint,
+				"client_id":                clientId,
+				"post_logout_redirect_uri": postLogoutRedirectUri,
+				"state":                    state,
+			})
+			return
+		}
+
+		util.LogInfo(c.Ctx, "API: [%s] logged out", sessionUserId)
+		c.SetSessionUsername("")
+		c.SetSessionData(nil)
+		frontchannelLogoutUris = object.LogoutAppSessions(sessionUserId, c.Ctx.Request.Host)
+	} else if hint != nil {
+		object.EndAppSession(hint)
+	}
+
+	if redirectUri != "" && len(frontchannelLogoutUris) == 0 {
+		c.Ctx.Redirect(http.StatusFound, redirectUri)
+		return
+	}
+
+	c.renderFrontchannelLogout(frontchannelLogoutUris, redirectUri)
+}
@@ -14,7 +14,11 @@
 
 package controllers
 
-import "github.com/casdoor/casdoor/object"
+import (
+	"net/http"
+
+	"github.com/casdoor/casdoor/object"
+)
 
 // @Title GetOidcDiscovery
 // @Tag OIDC API
@@ -25,6 +29,44 @@ func (c *RootController) GetOidcDiscovery() {
 	c.ServeJSON()
 }
 
+// @Title GetOrganizationOidcDiscovery
+// @Tag OIDC API
+// @Description get the OpenID Provider metadata of the organization that has its own issuer
+// @Param   organization     path    string  true        "The name of the organization"
+// @router /organizations/:organization/.well-known/openid-configuration [get]
+func (c *RootController) GetOrganizationOidcDiscovery() {
+	host := c.Ctx.Request.Host
+	organization := c.Ctx.Input.Param(":organization")
+
+	oidcDiscovery, err := object.GetOrganizationOidcDiscovery(host, organization)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusNotFound)
+		c.ResponseError(err.Error())
+		return
+	}
+	c.Data["json"] = oidcDiscovery
+	c.ServeJSON()
+}
+
+// @Title GetAuthorizationServerMetadata
+// @Tag OIDC API
+// @Description get the OAuth 2.0 authorization server metadata, per rfc 8414, of the server or of the organization that has its own issuer
+// @Param   organization     path    string  false       "The name of the organization"
+// @router /.well-known/oauth-authorization-server [get]
+func (c *RootController) GetAuthorizationServerMetadata() {
+	host := c.Ctx.Request.Host
+	organization := c.Ctx.Input.Param(":organization")
+
+	metadata, err := object.GetAuthorizationServerMetadata(host, organization)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusNotFound)
+		c.ResponseError(err.Error())
+		return
+	}
+	c.Data["json"] = metadata
+	c.ServeJSON()
+}
+
 // @Title GetJwks
 // @Tag OIDC API
 // @router /.well-known/jwks [get]
@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/astaxie/beego/utils/pagination"
@@ -132,6 +133,11 @@ func (c *ApiController) DeleteToken() {
 // @Param   redirect_uri     query    string  true        "OAuth redirect URI"
 // @Param   scope     query    string  true        "OAuth scope"
 // @Param   state     query    string  true        "OAuth state"
+// @Param   request_uri     query    string  false        "The request_uri returned by the pushed authorization request endpoint"
+// @Param   resource     query    string  false        "The identifier of the API resource that the tokens are for, can be repeated (rfc 8707)"
+// @Param   prompt     query    string  false        "\"consent\" asks the user for consent even if the user has granted the request"
+// @Param   acr_values     query    string  false        "The authentication context classes that the user must have signed in with"
+// @Param   max_age     query    string  false        "The maximum time in seconds since the user has signed in"
 // @Success 200 {object} object.TokenWrapper The Response object
 // @router /login/oauth/code [post]
 func (c *ApiController) GetOAuthCode() {
@@ -151,8 +157,21 @@ func (c *ApiController) GetOAuthCode() {
 		return
 	}
 	host := c.Ctx.Request.Host
+	requestUri := c.Input().Get("request_uri")
 
-	c.Data["json"] = object.GetOAuthCode(userId, clientId, responseType, redirectUri, scope, state, nonce, codeChallenge, host)
+	request := &object.AuthorizationRequest{
+		ResponseType:  responseType,
+		RedirectUri:   redirectUri,
+		Scope:         scope,
+		State:         state,
+		Nonce:         nonce,
+		CodeChallenge: codeChallenge,
+		Resources:     c.Input()["resource"],
+		Prompt:        c.Input().Get("prompt"),
+		AcrValues:     c.Input().Get("acr_values"),
+		MaxAge:        c.Input().Get("max_age"),
+	}
+	c.Data["json"] = object.GetOAuthCode(userId, clientId, request, host, requestUri)
 	c.ServeJSON()
 }
 
@@ -164,6 +183,14 @@ func (c *ApiController) GetOAuthCode() {
 // @Param   client_id     query    string  true        "OAuth client id"
 // @Param   client_secret     query    string  true        "OAuth client secret"
 // @Param   code     query    string  true        "OAuth code"
+// @Param   device_code     query    string  false        "OAuth device code"
+// @Param   auth_req_id     query    string  false        "The id of the backchannel authentication request, for the CIBA grant"
+// @Param   subject_token     query    string  false        "The token to exchange, for the token exchange grant"
+// @Param   actor_token     query    string  false        "The token of the acting party, for the token exchange grant"
+// @Param   audience     query    string  false        "The client id of the target application, for the token exchange grant"
+// @Param   client_assertion_type     query    string  false        "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+// @Param   client_assertion     query    string  false        "The JWT that authenticates the client, for private_key_jwt and client_secret_jwt"
+// @Param   resource     query    string  false        "The identifier of the API resource that the tokens are for, can be repeated (rfc 8707)"
 // @Success 200 {object} object.TokenWrapper The Response object
 // @router /login/oauth/access_token [post]
 func (c *ApiController) GetOAuthToken() {
@@ -177,8 +204,18 @@ func (c *ApiController) GetOAuthToken() {
 	password := c.Input().Get("password")
 	tag := c.Input().Get("tag")
 	avatar := c.Input().Get("avatar")
+	deviceCode := c.Input().Get("device_code")
+	authReqId := c.Input().Get("auth_req_id")
+	subjectToken := c.Input().Get("subject_token")
+	subjectTokenType := c.Input().Get("subject_token_type")
+	actorToken := c.Input().Get("actor_token")
+	actorTokenType := c.Input().Get("actor_token_type")
+	audience := c.Input().Get("audience")
+	clientAssertionType := c.Input().Get("client_assertion_type")
+	clientAssertion := c.Input().Get("client_assertion")
+	resources := c.Input()["resource"]
 
-	if clientId == "" && clientSecret == "" {
+	if clientId == "" && clientSecret == "" && clientAssertion == "" {
 		clientId, clientSecret, _ = c.Ctx.Request.BasicAuth()
 	}
 	if clientId == "" {
@@ -195,14 +232,200 @@ func (c *ApiController) GetOAuthToken() {
 			password = tokenRequest.Password
 			tag = tokenRequest.Tag
 			avatar = tokenRequest.Avatar
+			deviceCode = tokenRequest.DeviceCode
+			authReqId = tokenRequest.AuthReqId
+			subjectToken = tokenRequest.SubjectToken
+			subjectTokenType = tokenRequest.SubjectTokenType
+			actorToken = tokenRequest.ActorToken
+			actorTokenType = tokenRequest.ActorTokenType
+			audience = tokenRequest.Audience
+			clientAssertionType = tokenRequest.ClientAssertionType
+			clientAssertion = tokenRequest.ClientAssertion
+			resources = tokenRequest.getResources()
 		}
 	}
 	host := c.Ctx.Request.Host
 
-	c.Data["json"] = object.GetOAuthToken(grantType, clientId, clientSecret, code, verifier, scope, username, password, host, tag, avatar)
+	dpopProof := c.Ctx.Request.Header.Get("DPoP")
+
+	tokenWrapper := object.GetOAuthToken(grantType, clientId, clientSecret, code, verifier, scope, username, password, host, tag, avatar, deviceCode, authReqId, subjectToken, subjectTokenType, actorToken, actorTokenType, audience, clientAssertionType, clientAssertion, dpopProof, resources)
+	c.setDpopNonce(tokenWrapper.Error)
+	c.Data["json"] = tokenWrapper
+	c.ServeJSON()
+}
+
+// DeviceAuthorization
+// @Title DeviceAuthorization
+// @Tag Token API
+// @Description issue a device_code/user_code pair for the OAuth device authorization grant (rfc 8628)
+// @Param   client_id     formData    string  true        "OAuth client id"
+// @Param   client_secret     formData    string  false        "OAuth client secret, only the public clients leave it out"
+// @Param   client_assertion_type     formData    string  false        "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+// @Param   client_assertion     formData    string  false        "The JWT that authenticates the client, for private_key_jwt and client_secret_jwt"
+// @Param   scope     formData    string  false        "OAuth scope"
+// @Success 200 {object} object.DeviceAuthResponse The Response object
+// @router /login/oauth/device_authorization [post]
+func (c *ApiController) DeviceAuthorization() {
+	clientId := c.Input().Get("client_id")
+	clientSecret := c.Input().Get("client_secret")
+	clientAssertionType := c.Input().Get("client_assertion_type")
+	clientAssertion := c.Input().Get("client_assertion")
+	scope := c.Input().Get("scope")
+
+	if clientSecret == "" && clientAssertion == "" {
+		if basicClientId, basicClientSecret, ok := c.Ctx.Request.BasicAuth(); ok {
+			clientId, clientSecret = basicClientId, basicClientSecret
+		}
+	}
+
+	resp, err := object.GetDeviceAuthorization(clientId, clientSecret, clientAssertionType, clientAssertion, scope, c.Ctx.Request.Host)
+	if err != nil {
+		if err.Error() == "invalid_client" {
+			c.Ctx.Output.SetStatus(http.StatusUnauthorized)
+		} else {
+			c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		}
+		c.Data["json"] = &object.TokenError{Error: err.Error()}
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
+
+// GetDeviceAuth
+// @Title GetDeviceAuth
+// @Tag Token API
+// @Description get the application that a pending device user code belongs to
+// @Param   user_code     query    string  true        "The user code shown on the device"
+// @Success 200 {object} object.Application The Response object
+// @router /login/oauth/device [get]
+func (c *ApiController) GetDeviceAuth() {
+	_, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	userCode := c.Input().Get("user_code")
+	application, err := object.GetDeviceAuthApplication(userCode)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(object.GetMaskedApplication(application, ""))
+}
+
+// VerifyDeviceAuth
+// @Title VerifyDeviceAuth
+// @Tag Token API
+// @Description approve or deny a device user code as the signed-in user
+// @Param   user_code     formData    string  true        "The user code shown on the device"
+// @Param   approved     formData    bool  true        "Whether the user approves the device"
+// @Success 200 {object} controllers.Response The Response object
+// @router /login/oauth/device [post]
+func (c *ApiController) VerifyDeviceAuth() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	userCode := c.Input().Get("user_code")
+	approved := util.ParseBool(c.Input().Get("approved"))
+	err := object.VerifyDeviceAuth(userId, userCode, approved)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
+
+// BackchannelAuthorize
+// @Title BackchannelAuthorize
+// @Tag Token API
+// @Description start a client initiated backchannel authentication (CIBA) of the user that the login hint identifies
+// @Param   client_id     formData    string  true        "OAuth client id"
+// @Param   client_secret     formData    string  false        "OAuth client secret"
+// @Param   scope     formData    string  true        "OAuth scope, which should contain openid"
+// @Param   login_hint     formData    string  true        "The username, email or phone of the user"
+// @Param   binding_message     formData    string  false        "The message shown to the user on both devices"
+// @Param   client_notification_token     formData    string  false        "The bearer token of the ping callback, required in the ping mode"
+// @Param   requested_expiry     formData    int  false        "The lifetime of the request in seconds"
+// @Success 200 {object} object.CibaAuthResponse The Response object
+// @router /login/oauth/bc-authorize [post]
+func (c *ApiController) BackchannelAuthorize() {
+	clientId := c.Input().Get("client_id")
+	clientSecret := c.Input().Get("client_secret")
+	clientAssertionType := c.Input().Get("client_assertion_type")
+	clientAssertion := c.Input().Get("client_assertion")
+	scope := c.Input().Get("scope")
+	loginHint := c.Input().Get("login_hint")
+	bindingMessage := c.Input().Get("binding_message")
+	clientNotificationToken := c.Input().Get("client_notification_token")
+	requestedExpiry := c.Input().Get("requested_expiry")
+
+	if clientSecret == "" && clientAssertion == "" {
+		clientId, clientSecret, _ = c.Ctx.Request.BasicAuth()
+	}
+
+	resp, tokenError := object.AuthorizeBackchannel(clientId, clientSecret, clientAssertionType, clientAssertion, scope, loginHint, bindingMessage, clientNotificationToken, requestedExpiry, c.Ctx.Request.Host)
+	if tokenError != nil {
+		if tokenError.Error == "invalid_client" {
+			c.Ctx.Output.SetStatus(http.StatusUnauthorized)
+		} else {
+			c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		}
+		c.Data["json"] = tokenError
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = resp
 	c.ServeJSON()
 }
 
+// GetCibaRequests
+// @Title GetCibaRequests
+// @Tag Token API
+// @Description get the pending backchannel authentication requests of the signed-in user
+// @Success 200 {array} object.CibaRequest The Response object
+// @router /login/oauth/ciba [get]
+func (c *ApiController) GetCibaRequests() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetUserCibaRequests(userId))
+}
+
+// VerifyCibaRequest
+// @Title VerifyCibaRequest
+// @Tag Token API
+// @Description approve or deny a backchannel authentication request as the signed-in user
+// @Param   id     formData    string  true        "The id of the backchannel authentication request"
+// @Param   approved     formData    bool  true        "Whether the user approves the request"
+// @Success 200 {object} controllers.Response The Response object
+// @router /login/oauth/ciba [post]
+func (c *ApiController) VerifyCibaRequest() {
+	userId, ok := c.RequireSignedIn()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+	approved := util.ParseBool(c.Input().Get("approved"))
+	err := object.VerifyCibaRequest(userId, id, approved)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk()
+}
+
 // RefreshToken
 // @Title RefreshToken
 // @Tag Token API
@@ -212,6 +435,9 @@ func (c *ApiController) GetOAuthToken() {
 // @Param   scope     query    string  true        "OAuth scope"
 // @Param   client_id     query    string  true        "OAuth client id"
 // @Param   client_secret     query    string  false        "OAuth client secret"
+// @Param   client_assertion_type     query    string  false        "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+// @Param   client_assertion     query    string  false        "The JWT that authenticates the client, for private_key_jwt and client_secret_jwt"
+// @Param   resource     query    string  false        "The identifier of a granted API resource that the tokens are for, can be repeated (rfc 8707)"
 // @Success 200 {object} object.TokenWrapper The Response object
 // @router /login/oauth/refresh_token [post]
 func (c *ApiController) RefreshToken() {
@@ -220,9 +446,12 @@ func (c *ApiController) RefreshToken() {
 	scope := c.Input().Get("scope")
 	clientId := c.Input().Get("client_id")
 	clientSecret := c.Input().Get("client_secret")
+	clientAssertionType := c.Input().Get("client_assertion_type")
+	clientAssertion := c.Input().Get("client_assertion")
+	resources := c.Input()["resource"]
 	host := c.Ctx.Request.Host
 
-	if clientId == "" {
+	if clientId == "" && clientAssertion == "" {
 		// If clientID is empty, try to read data from RequestBody
 		var tokenRequest TokenRequest
 		if err := json.Unmarshal(c.Ctx.Input.RequestBody, &tokenRequest); err == nil {
@@ -231,31 +460,63 @@ func (c *ApiController) RefreshToken() {
 			grantType = tokenRequest.GrantType
 			scope = tokenRequest.Scope
 			refreshToken = tokenRequest.RefreshToken
+			clientAssertionType = tokenRequest.ClientAssertionType
+			clientAssertion = tokenRequest.ClientAssertion
+			resources = tokenRequest.getResources()
 		}
 	}
 
-	c.Data["json"] = object.RefreshToken(grantType, refreshToken, scope, clientId, clientSecret, host)
+	dpopProof := c.Ctx.Request.Header.Get("DPoP")
+
+	tokenWrapper := object.RefreshToken(grantType, refreshToken, scope, clientId, clientSecret, host, clientAssertionType, clientAssertion, dpopProof, resources)
+	c.setDpopNonce(tokenWrapper.Error)
+	c.Data["json"] = tokenWrapper
 	c.ServeJSON()
 }
 
 // TokenLogout
 // @Title TokenLogout
 // @Tag Token API
-// @Description delete token by AccessToken
+// @Description delete the token that the id_token_hint was issued along with, and log the user out of the applications that the user has signed in to
 // @Param   id_token_hint     query    string  true        "id_token_hint"
-// @Param   post_logout_redirect_uri    query    string  false      "post_logout_redirect_uri"
+// @Param   post_logout_redirect_uri    query    string  false      "One of the post logout redirect URIs or the redirect URIs of the application"
 // @Param   state     query    string  true        "state"
 // @Success 200 {object} controllers.Response The Response object
 // @router /login/oauth/logout [get]
 func (c *ApiController) TokenLogout() {
-	token := c.Input().Get("id_token_hint")
-	flag, application := object.DeleteTokenByAceessToken(token)
+	idTokenHint := c.Input().Get("id_token_hint")
 	redirectUri := c.Input().Get("post_logout_redirect_uri")
 	state := c.Input().Get("state")
-	if application != nil && object.CheckRedirectUriValid(application, redirectUri) {
+
+	hint, err := object.ParseIdTokenHint(idTokenHint)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	// like the end session endpoint, the hint only logs the user out of all the applications
+	// if it is issued for the signed-in user of the browser
+	flag := object.DeleteTokenByIdToken(idTokenHint)
+	frontchannelLogoutUris := []string{}
+	if sessionUserId := c.GetSessionUsername(); sessionUserId != "" && hint.IsOfUser(sessionUserId) {
+		frontchannelLogoutUris = object.LogoutAppSessions(sessionUserId, c.Ctx.Request.Host)
+	} else {
+		object.EndAppSession(hint)
+	}
+
+	application := hint.Application
+	if redirectUri != "" && (application.IsPostLogoutRedirectUriValid(redirectUri) || application.CheckRedirectUri(redirectUri) == nil) {
+		if len(frontchannelLogoutUris) != 0 {
+			c.renderFrontchannelLogout(frontchannelLogoutUris, redirectUri+"?state="+state)
+			return
+		}
 		c.Ctx.Redirect(http.StatusFound, redirectUri+"?state="+state)
 		return
 	}
+	if len(frontchannelLogoutUris) != 0 {
+		c.renderFrontchannelLogout(frontchannelLogoutUris, "")
+		return
+	}
 	c.Data["json"] = wrapActionResponse(flag)
 	c.ServeJSON()
 }
@@ -266,43 +527,86 @@ func (c *ApiController) TokenLogout() {
 //  parameter representing an OAuth 2.0 token and returns a JSON document
 //  representing the meta information surrounding the
 //  token, including whether this token is currently active.
-//  This endpoint only support Basic Authorization.
+//  The client authenticates with Basic Authorization, or with a client_assertion.
+//  A DPoP-bound token is inactive unless the DPoP header carries a proof signed by its key.
 // @Param token formData string true "access_token's value or refresh_token's value"
 // @Param token_type_hint formData string true "the token type access_token or refresh_token"
+// @Param client_assertion_type formData string false "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+// @Param client_assertion formData string false "The JWT that authenticates the client, for private_key_jwt and client_secret_jwt"
+// @Param resource formData string false "The identifier of the API resource that the token is presented to, the tokens for other audiences are inactive"
 // @Success 200 {object} object.IntrospectionResponse The Response object
 // @router /login/oauth/introspect [post]
 func (c *ApiController) IntrospectToken() {
 	tokenValue := c.Input().Get("token")
-	clientId, clientSecret, ok := c.Ctx.Request.BasicAuth()
-	if !ok {
-		clientId = c.Input().Get("client_id")
-		clientSecret = c.Input().Get("client_secret")
-		if clientId == "" || clientSecret == "" {
-			c.ResponseError("empty clientId or clientSecret")
+	clientAssertion := c.Input().Get("client_assertion")
+
+	var application *object.Application
+	if clientAssertion != "" {
+		var err error
+		application, err = object.ValidateClientAssertion(c.Input().Get("client_id"), c.Input().Get("client_assertion_type"), clientAssertion, c.Ctx.Request.Host)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+	} else {
+		clientId, clientSecret, ok := c.Ctx.Request.BasicAuth()
+		if !ok {
+			clientId = c.Input().Get("client_id")
+			clientSecret = c.Input().Get("client_secret")
+			if clientId == "" || clientSecret == "" {
+				c.ResponseError("empty clientId or clientSecret")
+				return
+			}
+		}
+		application = object.GetApplicationByClientId(clientId)
+		if application == nil || application.ClientSecret != clientSecret {
+			c.ResponseError("invalid application or wrong clientSecret")
+			return
+		}
+		if application.RequiresClientAssertion() {
+			c.ResponseError(fmt.Sprintf("the application: %s should authenticate with a client_assertion", application.Name))
 			return
 		}
 	}
-	application := object.GetApplicationByClientId(clientId)
-	if application == nil || application.ClientSecret != clientSecret {
-		c.ResponseError("invalid application or wrong clientSecret")
-		return
-	}
+	clientId := application.ClientId
 	token := object.GetTokenByTokenAndApplication(tokenValue, application.Name)
 	if token == nil {
 		c.Data["json"] = &object.IntrospectionResponse{Active: false}
 		c.ServeJSON()
 		return
 	}
-	jwtToken, err := object.ParseJwtTokenByApplication(tokenValue, application)
+	// revoked tokens (rfc 7009) have been removed from the database, so they are caught by the check above
+	jwtToken, err := object.ParseJwtTokenByApplication(token.GetJwtToken(tokenValue), application)
 	if err != nil || jwtToken.Valid() != nil {
-		// and token revoked case. but we not implement
-		// TODO: 2022-03-03 add token revoked check, when we implemented the Token Revocation(rfc7009) Specs.
-		// refs: https://tools.ietf.org/html/rfc7009
 		c.Data["json"] = &object.IntrospectionResponse{Active: false}
 		c.ServeJSON()
 		return
 	}
 
+	// the resource server tells which API the token has been presented to, per rfc 8707 section 2
+	resource := c.Input().Get("resource")
+	if resource != "" && !jwtToken.VerifyAudience(resource, true) {
+		c.Data["json"] = &object.IntrospectionResponse{Active: false}
+		c.ServeJSON()
+		return
+	}
+
+	// a DPoP-bound token is only active along with a proof signed by its key,
+	// which the resource server forwards from the request that the token came with
+	var cnf *object.CnfClaim
+	if token.DpopJkt != "" {
+		cnf = &object.CnfClaim{Jkt: token.DpopJkt}
+		jkt, err := object.CheckDpopProof(c.Ctx.Request.Header.Get("DPoP"), "", "", tokenValue, application.RequireDpopNonce)
+		if err != nil || jkt != token.DpopJkt {
+			if err != nil {
+				c.setDpopNonce(err.Error())
+			}
+			c.Data["json"] = &object.IntrospectionResponse{Active: false}
+			c.ServeJSON()
+			return
+		}
+	}
+
 	c.Data["json"] = &object.IntrospectionResponse{
 		Active:    true,
 		Scope:     jwtToken.Scope,
@@ -315,7 +619,106 @@ func (c *ApiController) IntrospectToken() {
 		Sub:       jwtToken.Subject,
 		Aud:       jwtToken.Audience,
 		Iss:       jwtToken.Issuer,
-		Jti:       jwtToken.Id,
+		Jti:       jwtToken.ID,
+		Cnf:       cnf,
 	}
 	c.ServeJSON()
 }
+
+// RevokeToken
+// @Title RevokeToken
+// @Tag Token API
+// @Description revoke an access token or a refresh token, per rfc 7009.
+//  Revoking a refresh token also revokes the access token issued with it.
+// @Param token formData string true "access_token's value or refresh_token's value"
+// @Param token_type_hint formData string false "the token type access_token or refresh_token"
+// @Param client_assertion_type formData string false "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+// @Param client_assertion formData string false "The JWT that authenticates the client, for private_key_jwt and client_secret_jwt"
+// @Success 200 {object} controllers.Response The Response object
+// @router /login/oauth/revoke [post]
+func (c *ApiController) RevokeToken() {
+	tokenValue := c.Input().Get("token")
+	tokenTypeHint := c.Input().Get("token_type_hint")
+	clientId, clientSecret, ok := c.Ctx.Request.BasicAuth()
+	if !ok {
+		clientId = c.Input().Get("client_id")
+		clientSecret = c.Input().Get("client_secret")
+	}
+
+	application, err := object.AuthenticateClient(clientId, clientSecret, c.Input().Get("client_assertion_type"), c.Input().Get("client_assertion"), c.Ctx.Request.Host)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusUnauthorized)
+		c.Data["json"] = &object.TokenError{Error: "invalid_client", ErrorDescription: err.Error()}
+		c.ServeJSON()
+		return
+	}
+
+	if tokenTypeHint != "" && tokenTypeHint != "access_token" && tokenTypeHint != "refresh_token" {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = &object.TokenError{Error: "unsupported_token_type"}
+		c.ServeJSON()
+		return
+	}
+
+	if tokenValue == "" {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = &object.TokenError{Error: "invalid_request", ErrorDescription: "the token parameter is missing"}
+		c.ServeJSON()
+		return
+	}
+
+	// per rfc 7009, invalid or unknown tokens don't cause an error response
+	object.RevokeToken(application, tokenValue, tokenTypeHint)
+	c.Ctx.Output.SetStatus(http.StatusOK)
+	err = c.Ctx.Output.Body([]byte{})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// PushAuthRequest
+// @Title PushAuthRequest
+// @Tag Token API
+// @Description push the parameters of an authorization request and get a short-lived request_uri
+//  to use in their place at the authorization endpoint, per rfc 9126.
+// @Param   client_id     formData    string  true        "OAuth client id"
+// @Param   client_secret     formData    string  false        "OAuth client secret, public clients send a code_challenge instead"
+// @Param   client_assertion_type     formData    string  false        "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+// @Param   client_assertion     formData    string  false        "The JWT that authenticates the client, for private_key_jwt and client_secret_jwt"
+// @Param   response_type     formData    string  true        "OAuth response type"
+// @Param   redirect_uri     formData    string  true        "OAuth redirect URI"
+// @Param   scope     formData    string  false        "OAuth scope"
+// @Param   state     formData    string  false        "OAuth state"
+// @Success 201 {object} object.PushedAuthResponse The Response object
+// @router /login/oauth/par [post]
+func (c *ApiController) PushAuthRequest() {
+	// the pushed parameters are form-encoded in the request body
+	err := c.Ctx.Request.ParseForm()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	params := c.Ctx.Request.PostForm
+
+	clientId, clientSecret, ok := c.Ctx.Request.BasicAuth()
+	if !ok {
+		clientId = params.Get("client_id")
+		clientSecret = params.Get("client_secret")
+	}
+
+	resp, tokenError := object.PushAuthRequest(clientId, clientSecret, params.Get("client_assertion_type"), params.Get("client_assertion"), c.Ctx.Request.Host, params)
+	if tokenError != nil {
+		if tokenError.Error == "invalid_client" {
+			c.Ctx.Output.SetStatus(http.StatusUnauthorized)
+		} else {
+			c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		}
+		c.Data["json"] = tokenError
+		c.ServeJSON()
+		return
+	}
+
+	c.Ctx.Output.SetStatus(http.StatusCreated)
+	c.Data["json"] = resp
+	c.ServeJSON()
+}
@@ -26,6 +26,36 @@ type TokenRequest struct {
 	Tag          string `json:"tag"`
 	Avatar       string `json:"avatar"`
 	RefreshToken string `json:"refresh_token"`
+	DeviceCode   string `json:"device_code"`
+	AuthReqId    string `json:"auth_req_id"`
+
+	SubjectToken     string `json:"subject_token"`
+	SubjectTokenType string `json:"subject_token_type"`
+	ActorToken       string `json:"actor_token"`
+	ActorTokenType   string `json:"actor_token_type"`
+	Audience         string `json:"audience"`
+
+	ClientAssertionType string `json:"client_assertion_type"`
+	ClientAssertion     string `json:"client_assertion"`
+
+	// a single resource, or an array of resources (rfc 8707)
+	Resource interface{} `json:"resource"`
+}
+
+func (tokenRequest *TokenRequest) getResources() []string {
+	switch resource := tokenRequest.Resource.(type) {
+	case string:
+		return []string{resource}
+	case []interface{}:
+		res := []string{}
+		for _, value := range resource {
+			if s, ok := value.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
 }
 
 const OTT_ORGANIZATION_ID = "OTT"
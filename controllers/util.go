@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/casdoor/casdoor/conf"
@@ -74,6 +75,16 @@ func (c *ApiController) RequireSignedIn() (string, bool) {
 	return userId, true
 }
 
+// setDpopNonce asks the client to retry with a nonce provided by Casdoor when its DPoP proof lacks one, per rfc 9449 section 8
+func (c *ApiController) setDpopNonce(errString string) {
+	if errString != object.ErrUseDpopNonce.Error() {
+		return
+	}
+
+	c.Ctx.Output.Header("DPoP-Nonce", object.GetDpopNonce())
+	c.Ctx.Output.SetStatus(http.StatusBadRequest)
+}
+
 func getInitScore() int {
 	score, err := strconv.Atoi(conf.GetConfigString("initScore"))
 	if err != nil {
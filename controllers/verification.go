@@ -238,7 +238,8 @@ func (c *ApiController) ResetEmailOrPhone() {
 		object.SetUserField(user, "email", user.Email)
 	case "phone":
 		user.Phone = dest
-		object.SetUserField(user, "phone", user.Phone)
+		user.PhoneVerified = true
+		object.UpdateUser(user.GetId(), user, []string{"phone", "phone_verified", "hash"}, false)
 	default:
 		c.ResponseError("Unknown type.")
 		return
@@ -42,6 +42,8 @@ func main() {
 	authz.InitAuthz()
 
 	util.SafeGoroutine(func() {object.RunSyncUsersJob()})
+	util.SafeGoroutine(func() {object.RunCertRotationJob()})
+	util.SafeGoroutine(func() {object.RunJanitorJob()})
 
 	//beego.DelStaticPath("/static")
 	beego.SetStaticPath("/static", "web/build/static")
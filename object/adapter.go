@@ -15,6 +15,7 @@
 package object
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 
@@ -148,11 +149,25 @@ func (a *Adapter) createTable() {
 		panic(err)
 	}
 
+	// the applications from before the consent signed their users in without asking them, so they keep skipping it
+	// after the upgrade, and only the applications added afterwards ask the users for consent
+	isSkipConsentAdded, err := a.isColumnMissing(new(Application), "skip_consent")
+	if err != nil {
+		panic(err)
+	}
+
 	err = a.Engine.Sync2(new(Application))
 	if err != nil {
 		panic(err)
 	}
 
+	if isSkipConsentAdded {
+		_, err = a.Engine.Table(new(Application)).Where("skip_consent = ?", false).Update(map[string]interface{}{"skip_consent": true})
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	err = a.Engine.Sync2(new(Resource))
 	if err != nil {
 		panic(err)
@@ -202,6 +217,57 @@ func (a *Adapter) createTable() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = a.Engine.Sync2(new(DeviceAuth))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(PushedAuthRequest))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(JtiRecord))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(AppSession))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(ArchivedRecord))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(ApiResource))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(Consent))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.Engine.Sync2(new(CibaRequest))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// isColumnMissing tells whether the table of the bean exists without the column, which Sync2 is about to add
+func (a *Adapter) isColumnMissing(bean interface{}, column string) (bool, error) {
+	tableExisted, err := a.Engine.IsTableExist(bean)
+	if err != nil || !tableExisted {
+		return false, err
+	}
+
+	columnExisted, err := a.Engine.Dialect().IsColumnExist(a.Engine.DB(), context.Background(), a.Engine.TableName(bean), column)
+	return !columnExisted, err
 }
 
 func GetSession(owner string, offset, limit int, field, value, sortField, sortOrder string) *xorm.Session {
@@ -0,0 +1,262 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+	"xorm.io/core"
+)
+
+// ApiResource is an API that the access tokens can be issued for. The client asks for a token of the API with the
+// resource parameter set to its identifier, per rfc 8707, and the identifier becomes the audience of the token.
+type ApiResource struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	DisplayName string `xorm:"varchar(100)" json:"displayName"`
+
+	Identifier string   `xorm:"varchar(200) index" json:"identifier"`
+	Scopes     []string `xorm:"mediumtext" json:"scopes"`
+	IsEnabled  bool     `json:"isEnabled"`
+}
+
+// the scopes of the OpenID Connect requests, which are about the user rather than an API
+var userScopes = []string{"openid", "profile", "email", "address", "phone", "offline_access"}
+
+func GetApiResourceCount(owner, field, value string) int {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	count, err := session.Count(&ApiResource{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(count)
+}
+
+func GetApiResources(owner string) []*ApiResource {
+	apiResources := []*ApiResource{}
+	err := adapter.Engine.Desc("created_time").Find(&apiResources, &ApiResource{Owner: owner})
+	if err != nil {
+		panic(err)
+	}
+
+	return apiResources
+}
+
+func GetPaginationApiResources(owner string, offset, limit int, field, value, sortField, sortOrder string) []*ApiResource {
+	apiResources := []*ApiResource{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&apiResources)
+	if err != nil {
+		panic(err)
+	}
+
+	return apiResources
+}
+
+func getApiResource(owner string, name string) *ApiResource {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	apiResource := ApiResource{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&apiResource)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &apiResource
+	} else {
+		return nil
+	}
+}
+
+func GetApiResource(id string) *ApiResource {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getApiResource(owner, name)
+}
+
+func getApiResourceByIdentifier(owner string, identifier string) *ApiResource {
+	if identifier == "" {
+		return nil
+	}
+
+	apiResource := ApiResource{Owner: owner, Identifier: identifier}
+	existed, err := adapter.Engine.Get(&apiResource)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &apiResource
+	} else {
+		return nil
+	}
+}
+
+func UpdateApiResource(id string, apiResource *ApiResource) bool {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	if getApiResource(owner, name) == nil {
+		return false
+	}
+
+	affected, err := adapter.Engine.ID(core.PK{owner, name}).AllCols().Update(apiResource)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func AddApiResource(apiResource *ApiResource) bool {
+	affected, err := adapter.Engine.Insert(apiResource)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func DeleteApiResource(apiResource *ApiResource) bool {
+	affected, err := adapter.Engine.ID(core.PK{apiResource.Owner, apiResource.Name}).Delete(&ApiResource{})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func (apiResource *ApiResource) GetId() string {
+	return fmt.Sprintf("%s/%s", apiResource.Owner, apiResource.Name)
+}
+
+// CheckIdentifier returns an error if the identifier of the API resource isn't an absolute URI without a fragment,
+// or if another API resource of the organization has the same identifier
+func (apiResource *ApiResource) CheckIdentifier() error {
+	err := checkResourceIndicator(apiResource.Identifier)
+	if err != nil {
+		return err
+	}
+
+	existing := getApiResourceByIdentifier(apiResource.Owner, apiResource.Identifier)
+	if existing != nil && existing.Name != apiResource.Name {
+		return fmt.Errorf("the identifier: %s is already used by the API resource: %s", apiResource.Identifier, existing.Name)
+	}
+	return nil
+}
+
+// checkResourceIndicator returns an error if the resource isn't an absolute URI without a fragment, per rfc 8707 section 2
+func checkResourceIndicator(resource string) error {
+	u, err := url.Parse(resource)
+	if err != nil || !u.IsAbs() || strings.Contains(resource, "#") {
+		return fmt.Errorf("the resource: \"%s\" should be an absolute URI without a fragment", resource)
+	}
+	return nil
+}
+
+// checkResources checks the resources that the client asks the tokens for, and returns them space-delimited.
+// The resources are the enabled API resources of the organization of the application, and the scopes of the request
+// other than the OpenID Connect ones must be allowed by one of them.
+func checkResources(application *Application, resources []string, scope string) (string, error) {
+	if len(resources) == 0 {
+		return "", nil
+	}
+
+	res := []string{}
+	allowedScopes := map[string]bool{}
+	for _, resource := range resources {
+		err := checkResourceIndicator(resource)
+		if err != nil {
+			return "", fmt.Errorf("error: invalid_target: %s", err.Error())
+		}
+
+		apiResource := getApiResourceByIdentifier(application.Organization, resource)
+		if apiResource == nil || !apiResource.IsEnabled {
+			return "", fmt.Errorf("error: invalid_target: the resource: %s doesn't exist in the organization: %s", resource, application.Organization)
+		}
+		for _, s := range apiResource.Scopes {
+			allowedScopes[s] = true
+		}
+
+		if !hasScope(strings.Join(res, " "), resource) {
+			res = append(res, resource)
+		}
+	}
+
+	for _, s := range strings.Fields(scope) {
+		if !hasScope(strings.Join(userScopes, " "), s) && !allowedScopes[s] {
+			return "", fmt.Errorf("error: invalid_scope: the scope: %s is not allowed for the resource: %s", s, strings.Join(res, " "))
+		}
+	}
+	return strings.Join(res, " "), nil
+}
+
+// checkGrantedResources checks the resources that the client asks the tokens for when it exchanges a grant for them,
+// which must be among the resources of the grant, per rfc 8707 section 2.2. It returns the resources of the grant
+// if the client doesn't ask for any.
+func checkGrantedResources(application *Application, resources []string, scope string, grantedResource string) (string, error) {
+	if len(resources) == 0 {
+		return grantedResource, nil
+	}
+
+	for _, resource := range resources {
+		if !hasScope(grantedResource, resource) {
+			return "", fmt.Errorf("error: invalid_target: the resource: %s has not been granted", resource)
+		}
+	}
+	return checkResources(application, resources, scope)
+}
+
+// reissueTokenForResource reissues the tokens of an authorization code for some of the resources the code is granted for,
+// the resources of the grant are kept for the refresh requests
+func reissueTokenForResource(application *Application, token *Token, resource string, host string) error {
+	user := getUser(token.Organization, token.User)
+	if user == nil {
+		return fmt.Errorf("error: the user: %s/%s doesn't exist", token.Organization, token.User)
+	}
+
+	claims, err := ParseJwtTokenByApplication(token.AccessToken, application)
+	if err != nil {
+		return err
+	}
+
+	accessToken, refreshToken, err := generateJwtToken(application, user, token.Scope, resource, host, nil, getClaimsAuthentication(claims))
+	if err != nil {
+		return err
+	}
+	idToken, err := getIdTokenWithAtHash(application, token.IdToken, accessToken)
+	if err != nil {
+		return err
+	}
+
+	token.AccessToken = accessToken
+	token.RefreshToken = refreshToken
+	token.IdToken = idToken
+	_, err = adapter.Engine.ID(core.PK{token.Owner, token.Name}).Cols("access_token", "refresh_token", "id_token").Update(token)
+	return err
+}
+
+// getTokenAudience returns the audience of the access token: the resources it is issued for, or else the application
+func getTokenAudience(application *Application, resource string) []string {
+	if resource == "" {
+		return []string{application.ClientId}
+	}
+	return strings.Fields(resource)
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckResourceIndicator(t *testing.T) {
+	scenarios := map[string]bool{
+		"https://api.example.com":          true,
+		"https://api.example.com/orders":   true,
+		"urn:example:orders":               true,
+		"api.example.com":                  false,
+		"/orders":                          false,
+		"https://api.example.com/#orders":  false,
+		"https://api.example.com/orders#x": false,
+		"":                                 false,
+	}
+
+	for resource, valid := range scenarios {
+		err := checkResourceIndicator(resource)
+		if (err == nil) != valid {
+			t.Errorf("checkResourceIndicator(%s) = %v, want valid: %v", resource, err, valid)
+		}
+	}
+}
+
+func TestGetTokenAudience(t *testing.T) {
+	application := &Application{ClientId: "client-test"}
+
+	audience := getTokenAudience(application, "")
+	if !reflect.DeepEqual(audience, []string{"client-test"}) {
+		t.Errorf("getTokenAudience() = %v, want the client id", audience)
+	}
+
+	audience = getTokenAudience(application, "https://api.example.com https://api.example.org")
+	if !reflect.DeepEqual(audience, []string{"https://api.example.com", "https://api.example.org"}) {
+		t.Errorf("getTokenAudience() = %v, want the resources", audience)
+	}
+}
+
+func TestCheckGrantedResources(t *testing.T) {
+	application := &Application{Organization: "org-test"}
+	granted := "https://api.example.com https://api.example.org"
+
+	resource, err := checkGrantedResources(application, nil, "openid", granted)
+	if err != nil || resource != granted {
+		t.Errorf("checkGrantedResources() = %s, %v, want the granted resources", resource, err)
+	}
+
+	_, err = checkGrantedResources(application, []string{"https://api.example.net"}, "openid", granted)
+	if err == nil {
+		t.Errorf("checkGrantedResources() accepts a resource that has not been granted")
+	}
+
+	_, err = checkGrantedResources(application, []string{"https://api.example.com"}, "openid", "")
+	if err == nil {
+		t.Errorf("checkGrantedResources() accepts a resource for a grant without resources")
+	}
+}
@@ -40,21 +40,44 @@ type Application struct {
 	Providers           []*ProviderItem `xorm:"mediumtext" json:"providers"`
 	SignupItems         []*SignupItem   `xorm:"varchar(1000)" json:"signupItems"`
 	GrantTypes          []string        `xorm:"varchar(1000)" json:"grantTypes"`
+	ResponseTypes       []string        `xorm:"varchar(1000)" json:"responseTypes"`
 	OrganizationObj     *Organization   `xorm:"-" json:"organizationObj"`
 
-	ClientId             string   `xorm:"varchar(100)" json:"clientId"`
-	ClientSecret         string   `xorm:"varchar(100)" json:"clientSecret"`
-	RedirectUris         []string `xorm:"varchar(1000)" json:"redirectUris"`
-	TokenFormat          string   `xorm:"varchar(100)" json:"tokenFormat"`
-	ExpireInHours        int      `json:"expireInHours"`
-	RefreshExpireInHours int      `json:"refreshExpireInHours"`
-	SignupUrl            string   `xorm:"varchar(200)" json:"signupUrl"`
-	SigninUrl            string   `xorm:"varchar(200)" json:"signinUrl"`
-	ForgetUrl            string   `xorm:"varchar(200)" json:"forgetUrl"`
-	AffiliationUrl       string   `xorm:"varchar(100)" json:"affiliationUrl"`
-	TermsOfUse           string   `xorm:"varchar(100)" json:"termsOfUse"`
-	SignupHtml           string   `xorm:"mediumtext" json:"signupHtml"`
-	SigninHtml           string   `xorm:"mediumtext" json:"signinHtml"`
+	ClientId                              string           `xorm:"varchar(100)" json:"clientId"`
+	ClientSecret                          string           `xorm:"varchar(100)" json:"clientSecret"`
+	RedirectUris                          []string         `xorm:"varchar(1000)" json:"redirectUris"`
+	TokenFormat                           string           `xorm:"varchar(100)" json:"tokenFormat"`
+	SigningAlgorithm                      string           `xorm:"varchar(100)" json:"signingAlgorithm"`
+	ClaimRules                            []*ClaimRule     `xorm:"mediumtext" json:"claimRules"`
+	ClaimTemplates                        []*ClaimTemplate `xorm:"mediumtext" json:"claimTemplates"`
+	ClaimNamespace                        string           `xorm:"varchar(200)" json:"claimNamespace"`
+	ExpireInHours                         int              `json:"expireInHours"`
+	RefreshExpireInHours                  int              `json:"refreshExpireInHours"`
+	EnableRefreshTokenRotation            bool             `json:"enableRefreshTokenRotation"`
+	TokenExchangeAudiences                []string         `xorm:"varchar(1000)" json:"tokenExchangeAudiences"`
+	RequirePushedAuthorizationRequests    bool             `json:"requirePushedAuthorizationRequests"`
+	TokenEndpointAuthMethod               string           `xorm:"varchar(100)" json:"tokenEndpointAuthMethod"`
+	ClientJwks                            string           `xorm:"mediumtext" json:"clientJwks"`
+	ClientPublicKey                       string           `xorm:"mediumtext" json:"clientPublicKey"`
+	RequireDpopNonce                      bool             `json:"requireDpopNonce"`
+	SkipConsent                           bool             `json:"skipConsent"`
+	UserinfoSignedResponseAlg             string           `xorm:"varchar(100)" json:"userinfoSignedResponseAlg"`
+	UserinfoEncryptedResponseAlg          string           `xorm:"varchar(100)" json:"userinfoEncryptedResponseAlg"`
+	UserinfoEncryptedResponseEnc          string           `xorm:"varchar(100)" json:"userinfoEncryptedResponseEnc"`
+	BackchannelLogoutUri                  string           `xorm:"varchar(200)" json:"backchannelLogoutUri"`
+	FrontchannelLogoutUri                 string           `xorm:"varchar(200)" json:"frontchannelLogoutUri"`
+	FrontchannelLogoutSessionRequired     bool             `json:"frontchannelLogoutSessionRequired"`
+	BackchannelTokenDeliveryMode          string           `xorm:"varchar(100)" json:"backchannelTokenDeliveryMode"`
+	BackchannelClientNotificationEndpoint string           `xorm:"varchar(200)" json:"backchannelClientNotificationEndpoint"`
+	PostLogoutRedirectUris                []string         `xorm:"varchar(1000)" json:"postLogoutRedirectUris"`
+	RegistrationAccessToken               string           `xorm:"varchar(100) index" json:"registrationAccessToken"`
+	SignupUrl                             string           `xorm:"varchar(200)" json:"signupUrl"`
+	SigninUrl                             string           `xorm:"varchar(200)" json:"signinUrl"`
+	ForgetUrl                             string           `xorm:"varchar(200)" json:"forgetUrl"`
+	AffiliationUrl                        string           `xorm:"varchar(100)" json:"affiliationUrl"`
+	TermsOfUse                            string           `xorm:"varchar(100)" json:"termsOfUse"`
+	SignupHtml                            string           `xorm:"mediumtext" json:"signupHtml"`
+	SigninHtml                            string           `xorm:"mediumtext" json:"signinHtml"`
 }
 
 func GetApplicationCount(owner, field, value string) int {
@@ -217,6 +240,9 @@ func GetMaskedApplication(application *Application, userId string) *Application
 	if application.ClientSecret != "" {
 		application.ClientSecret = "***"
 	}
+	if application.RegistrationAccessToken != "" {
+		application.RegistrationAccessToken = "***"
+	}
 
 	if application.OrganizationObj != nil {
 		if application.OrganizationObj.MasterPassword != "" {
@@ -261,6 +287,9 @@ func UpdateApplication(id string, application *Application) bool {
 	if application.ClientSecret == "***" {
 		session.Omit("client_secret")
 	}
+	if application.RegistrationAccessToken == "***" {
+		session.Omit("registration_access_token")
+	}
 	affected, err := session.Update(application)
 	if err != nil {
 		panic(err)
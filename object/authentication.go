@@ -0,0 +1,163 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The authorization requests can ask for a recent or a strong enough authentication of the user
+// with prompt=login, max_age and acr_values, per OpenID Connect Core section 3.1.2.1
+// https://openid.net/specs/openid-connect-core-1_0.html#AuthRequest
+const (
+	// the authentication method references, per rfc 8176
+	AmrPassword  = "pwd"
+	AmrOtp       = "otp" // a verification code sent by email
+	AmrSms       = "sms" // a verification code sent by SMS
+	AmrFederated = "fed" // a third-party identity provider
+
+	LoginRequired                   = "login_required"
+	UnmetAuthenticationRequirements = "unmet_authentication_requirements"
+)
+
+// the authentication context classes, from the weakest to the strongest
+var supportedAcrValues = []string{AcrSingleFactor, AcrMultiFactor}
+
+// the factors of the authentication methods. The sign in with an identity provider isn't counted as a factor,
+// as Casdoor doesn't know how the identity provider has authenticated the user.
+var amrFactors = map[string]string{
+	AmrPassword: "knowledge",
+	AmrOtp:      "possession",
+	AmrSms:      "possession",
+}
+
+var (
+	errLoginRequired                   = fmt.Errorf("error: %s: the request asks the user to sign in again", LoginRequired)
+	errUnmetAuthenticationRequirements = fmt.Errorf("error: %s: the user hasn't signed in with the authentication level that the request asks for", UnmetAuthenticationRequirements)
+)
+
+// GetLoginRequiredError returns login_required or unmet_authentication_requirements if the error message
+// of an authorization request asks the user to sign in again, or "" otherwise
+func GetLoginRequiredError(msg string) string {
+	switch msg {
+	case errLoginRequired.Error():
+		return LoginRequired
+	case errUnmetAuthenticationRequirements.Error():
+		return UnmetAuthenticationRequirements
+	}
+	return ""
+}
+
+// Authentication is when and how the user of the session has authenticated
+type Authentication struct {
+	User     string   `json:"user"`
+	AuthTime int64    `json:"authTime"`
+	Amr      []string `json:"amr"`
+
+	// the user has just authenticated for the request, rather than continuing as the signed-in user
+	IsNew bool `json:"-"`
+}
+
+// NewAuthentication returns the authentication of the user who has just signed in with the method. The methods
+// of the previous authentication of the same user in the session still count, so signing in again with
+// another factor steps the session up to multiple factors.
+func NewAuthentication(userId string, method string, previous *Authentication) *Authentication {
+	authentication := &Authentication{
+		User:     userId,
+		AuthTime: time.Now().Unix(),
+		Amr:      []string{},
+		IsNew:    true,
+	}
+	if previous != nil && previous.User == userId {
+		authentication.Amr = addToSet(authentication.Amr, previous.Amr)
+	}
+	if method != "" {
+		authentication.Amr = addToSet(authentication.Amr, []string{method})
+	}
+	return authentication
+}
+
+// getClaimsAuthentication returns the authentication that the tokens of the claims were issued for,
+// which is carried over to the tokens that replace them. The tokens of a client have no authentication.
+func getClaimsAuthentication(claims *Claims) *Authentication {
+	if claims.Acr == "" {
+		return nil
+	}
+	return &Authentication{AuthTime: claims.AuthTime, Amr: claims.Amr}
+}
+
+// GetAcr returns the authentication context class reference that the methods of the authentication reach
+func (authentication *Authentication) GetAcr() string {
+	factors := map[string]bool{}
+	for _, method := range authentication.Amr {
+		if factor, ok := amrFactors[method]; ok {
+			factors[factor] = true
+		}
+	}
+
+	if len(factors) >= 2 {
+		return AcrMultiFactor
+	}
+	return AcrSingleFactor
+}
+
+func getAcrLevel(acr string) int {
+	for i, value := range supportedAcrValues {
+		if value == acr {
+			return i
+		}
+	}
+	return -1
+}
+
+// isAcrSatisfied tells whether the acr is at least one of the acr values of the request,
+// the acr values that Casdoor doesn't support can't be satisfied
+func isAcrSatisfied(acr string, acrValues string) bool {
+	level := getAcrLevel(acr)
+	for _, value := range strings.Fields(acrValues) {
+		requiredLevel := getAcrLevel(value)
+		if requiredLevel != -1 && requiredLevel <= level {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAuthentication returns the login_required error if the request asks the user to sign in again with
+// prompt=login or max_age, and the unmet_authentication_requirements error if the authentication of the user
+// doesn't reach any of the acr_values of the request.
+func checkAuthentication(authentication *Authentication, request *AuthorizationRequest) error {
+	if hasScope(request.Prompt, "login") && !authentication.IsNew {
+		return errLoginRequired
+	}
+
+	if request.MaxAge != "" {
+		maxAge, err := strconv.Atoi(request.MaxAge)
+		if err != nil || maxAge < 0 {
+			return fmt.Errorf("error: invalid max_age: %s", request.MaxAge)
+		}
+		if !authentication.IsNew && time.Now().Unix()-authentication.AuthTime > int64(maxAge) {
+			return errLoginRequired
+		}
+	}
+
+	if request.AcrValues != "" && !isAcrSatisfied(authentication.GetAcr(), request.AcrValues) {
+		return errUnmetAuthenticationRequirements
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewAuthentication(t *testing.T) {
+	previous := NewAuthentication("built-in/alice", AmrPassword, nil)
+
+	authentication := NewAuthentication("built-in/alice", AmrSms, previous)
+	if len(authentication.Amr) != 2 || authentication.GetAcr() != AcrMultiFactor {
+		t.Errorf("the methods of the same user are %v, acr: %s", authentication.Amr, authentication.GetAcr())
+	}
+
+	authentication = NewAuthentication("built-in/bob", AmrSms, previous)
+	if len(authentication.Amr) != 1 || authentication.GetAcr() != AcrSingleFactor {
+		t.Errorf("the methods of another user are %v, acr: %s", authentication.Amr, authentication.GetAcr())
+	}
+
+	// two methods of the same factor are still a single factor
+	authentication = NewAuthentication("built-in/alice", AmrOtp, NewAuthentication("built-in/alice", AmrSms, nil))
+	if authentication.GetAcr() != AcrSingleFactor {
+		t.Errorf("the acr of %v is %s", authentication.Amr, authentication.GetAcr())
+	}
+}
+
+func TestIsAcrSatisfied(t *testing.T) {
+	tests := []struct {
+		acr       string
+		acrValues string
+		satisfied bool
+	}{
+		{AcrSingleFactor, "1", true},
+		{AcrSingleFactor, "2", false},
+		{AcrSingleFactor, "2 1", true},
+		{AcrMultiFactor, "1", true},
+		{AcrMultiFactor, "2", true},
+		{AcrMultiFactor, "urn:example:unknown", false},
+	}
+	for _, test := range tests {
+		if isAcrSatisfied(test.acr, test.acrValues) != test.satisfied {
+			t.Errorf("isAcrSatisfied(%s, %s) != %v", test.acr, test.acrValues, test.satisfied)
+		}
+	}
+}
+
+func TestCheckAuthentication(t *testing.T) {
+	session := &Authentication{User: "built-in/alice", AuthTime: time.Now().Unix() - 600, Amr: []string{AmrPassword}}
+	newAuthentication := NewAuthentication("built-in/alice", AmrPassword, nil)
+
+	tests := []struct {
+		authentication *Authentication
+		request        *AuthorizationRequest
+		err            error
+	}{
+		{session, &AuthorizationRequest{}, nil},
+		{session, &AuthorizationRequest{Prompt: "login"}, errLoginRequired},
+		{newAuthentication, &AuthorizationRequest{Prompt: "login consent"}, nil},
+		{session, &AuthorizationRequest{MaxAge: "3600"}, nil},
+		{session, &AuthorizationRequest{MaxAge: "60"}, errLoginRequired},
+		{newAuthentication, &AuthorizationRequest{MaxAge: "0"}, nil},
+		{session, &AuthorizationRequest{AcrValues: "1"}, nil},
+		{newAuthentication, &AuthorizationRequest{AcrValues: "2"}, errUnmetAuthenticationRequirements},
+	}
+	for i, test := range tests {
+		if err := checkAuthentication(test.authentication, test.request); err != test.err {
+			t.Errorf("test %d: checkAuthentication() = %v, want %v", i, err, test.err)
+		}
+	}
+
+	if err := checkAuthentication(session, &AuthorizationRequest{MaxAge: "-1"}); err == nil || GetLoginRequiredError(err.Error()) != "" {
+		t.Errorf("checkAuthentication() with an invalid max_age = %v", err)
+	}
+}
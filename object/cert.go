@@ -37,6 +37,18 @@ type Cert struct {
 	PrivateKey             string `xorm:"mediumtext" json:"privateKey"`
 	AuthorityPublicKey     string `xorm:"mediumtext" json:"authorityPublicKey"`
 	AuthorityRootPublicKey string `xorm:"mediumtext" json:"authorityRootPublicKey"`
+
+	// key rotation: the next key is published in the JWKS before it signs any token, and the previous key
+	// keeps verifying the tokens that it has signed until they expire
+	KeyId                 string `xorm:"varchar(100)" json:"keyId"`
+	NextKeyId             string `xorm:"varchar(100)" json:"nextKeyId"`
+	NextPublicKey         string `xorm:"mediumtext" json:"nextPublicKey"`
+	NextPrivateKey        string `xorm:"mediumtext" json:"nextPrivateKey"`
+	PreviousKeyId         string `xorm:"varchar(100)" json:"previousKeyId"`
+	PreviousPublicKey     string `xorm:"mediumtext" json:"previousPublicKey"`
+	PreviousKeyExpireTime string `xorm:"varchar(100)" json:"previousKeyExpireTime"`
+	RotationIntervalDays  int    `json:"rotationIntervalDays"`
+	RotatedTime           string `xorm:"varchar(100)" json:"rotatedTime"`
 }
 
 func GetMaskedCert(cert *Cert) *Cert {
@@ -44,12 +56,18 @@ func GetMaskedCert(cert *Cert) *Cert {
 		return nil
 	}
 
+	if cert.PrivateKey != "" {
+		cert.PrivateKey = "***"
+	}
+	if cert.NextPrivateKey != "" {
+		cert.NextPrivateKey = "***"
+	}
 	return cert
 }
 
 func GetMaskedCerts(certs []*Cert) []*Cert {
-	for _, cert := range certs {
-		cert = GetMaskedCert(cert)
+	for i, cert := range certs {
+		certs[i] = GetMaskedCert(cert)
 	}
 	return certs
 }
@@ -108,10 +126,38 @@ func GetCert(id string) *Cert {
 	return getCert(owner, name)
 }
 
-func UpdateCert(id string, cert *Cert) bool {
+func UpdateCert(id string, cert *Cert) (bool, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
-	if getCert(owner, name) == nil {
-		return false
+	oldCert := getCert(owner, name)
+	if oldCert == nil {
+		return false, nil
+	}
+
+	// the masked private keys stay as they are
+	if cert.PrivateKey == "***" {
+		cert.PrivateKey = oldCert.PrivateKey
+	}
+	if cert.NextPrivateKey == "***" {
+		cert.NextPrivateKey = oldCert.NextPrivateKey
+	}
+
+	// the keys are generated again when they are cleared, or when they can't sign with the changed crypto algorithm
+	if !isCertKeyValid(cert) {
+		err := generateCertKeys(cert)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// a replaced key becomes the previous key, so that the tokens it has signed stay valid until they expire
+	if oldCert.PublicKey != "" && cert.PublicKey != oldCert.PublicKey {
+		err := checkCertKeyReplaceable(oldCert)
+		if err != nil {
+			return false, err
+		}
+
+		setCertPreviousKey(cert, getCertKeyId(oldCert), oldCert.PublicKey)
+		cert.KeyId = getNewCertKeyId(cert)
 	}
 
 	affected, err := adapter.Engine.ID(core.PK{owner, name}).AllCols().Update(cert)
@@ -119,14 +165,26 @@ func UpdateCert(id string, cert *Cert) bool {
 		panic(err)
 	}
 
-	return affected != 0
+	return affected != 0, nil
+}
+
+func generateCertKeys(cert *Cert) error {
+	publicKey, privateKey, err := generateKeys(cert.CryptoAlgorithm, cert.BitSize, cert.ExpireInYears, cert.Name, cert.Owner)
+	if err != nil {
+		return err
+	}
+
+	cert.PublicKey = publicKey
+	cert.PrivateKey = privateKey
+	return nil
 }
 
 func AddCert(cert *Cert) bool {
 	if cert.PublicKey == "" || cert.PrivateKey == "" {
-		publicKey, privateKey := generateRsaKeys(cert.BitSize, cert.ExpireInYears, cert.Name, cert.Owner)
-		cert.PublicKey = publicKey
-		cert.PrivateKey = privateKey
+		err := generateCertKeys(cert)
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	affected, err := adapter.Engine.Insert(cert)
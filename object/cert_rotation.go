@@ -0,0 +1,253 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/astaxie/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"xorm.io/core"
+)
+
+const (
+	certRotationCheckInterval = time.Hour
+
+	defaultTokenLifetime = 168 * time.Hour
+)
+
+// getCertKeyId returns the kid of the current key of the cert. The key that the cert was created with is named after the cert.
+func getCertKeyId(cert *Cert) string {
+	if cert.KeyId != "" {
+		return cert.KeyId
+	}
+	return cert.Name
+}
+
+func getNewCertKeyId(cert *Cert) string {
+	return fmt.Sprintf("%s-%s", cert.Name, util.GenerateId()[:8])
+}
+
+func isCertPreviousKeyValid(cert *Cert) bool {
+	if cert.PreviousPublicKey == "" {
+		return false
+	}
+
+	expireTime, err := time.Parse(time.RFC3339, cert.PreviousKeyExpireTime)
+	return err == nil && time.Now().Before(expireTime)
+}
+
+// getCertPublicKeyByKid returns the public key of the cert that verifies the tokens with the kid:
+// the current key, or the previous key until the tokens that it has signed expire.
+func getCertPublicKeyByKid(cert *Cert, kid string) (string, error) {
+	if kid == "" || kid == getCertKeyId(cert) {
+		return cert.PublicKey, nil
+	}
+
+	if kid == cert.PreviousKeyId && isCertPreviousKeyValid(cert) {
+		return cert.PreviousPublicKey, nil
+	}
+
+	return "", fmt.Errorf("the cert: %s has no key: %s", cert.Name, kid)
+}
+
+// getCertTokenLifetime returns the longest lifetime of the tokens signed by the cert, among the applications using it
+func getCertTokenLifetime(cert *Cert) time.Duration {
+	lifetime := time.Duration(0)
+	for _, application := range GetApplications("admin") {
+		if application.Cert != cert.Name && !(application.Cert == "" && cert.Name == "cert-built-in") {
+			continue
+		}
+
+		for _, hours := range []int{application.ExpireInHours, application.RefreshExpireInHours} {
+			if time.Duration(hours)*time.Hour > lifetime {
+				lifetime = time.Duration(hours) * time.Hour
+			}
+		}
+	}
+
+	if lifetime == 0 {
+		return defaultTokenLifetime
+	}
+	return lifetime
+}
+
+func setCertPreviousKey(cert *Cert, keyId string, publicKey string) {
+	cert.PreviousKeyId = keyId
+	cert.PreviousPublicKey = publicKey
+	cert.PreviousKeyExpireTime = time.Now().Add(getCertTokenLifetime(cert)).Format(time.RFC3339)
+}
+
+// checkCertKeyReplaceable refuses to replace the key of the cert while its previous key is still valid,
+// as the tokens signed by the previous key would stop verifying before they expire
+func checkCertKeyReplaceable(cert *Cert) error {
+	if isCertPreviousKeyValid(cert) {
+		return fmt.Errorf("the key of the cert: %s can't be replaced until its previous key expires at %s", cert.Name, cert.PreviousKeyExpireTime)
+	}
+	return nil
+}
+
+func generateCertNextKey(cert *Cert) error {
+	publicKey, privateKey, err := generateKeys(cert.CryptoAlgorithm, cert.BitSize, cert.ExpireInYears, cert.Name, cert.Owner)
+	if err != nil {
+		return err
+	}
+
+	cert.NextKeyId = getNewCertKeyId(cert)
+	cert.NextPublicKey = publicKey
+	cert.NextPrivateKey = privateKey
+	return nil
+}
+
+// rotateCertKey makes the next key the current one and keeps the current key as the previous one.
+// With a rotation policy, a new next key is published right away for the following rotation.
+func rotateCertKey(cert *Cert) error {
+	err := checkCertKeyReplaceable(cert)
+	if err != nil {
+		return err
+	}
+
+	if cert.NextPublicKey == "" || cert.NextPrivateKey == "" {
+		err := generateCertNextKey(cert)
+		if err != nil {
+			return err
+		}
+	}
+
+	setCertPreviousKey(cert, getCertKeyId(cert), cert.PublicKey)
+	cert.KeyId = cert.NextKeyId
+	cert.PublicKey = cert.NextPublicKey
+	cert.PrivateKey = cert.NextPrivateKey
+	cert.NextKeyId = ""
+	cert.NextPublicKey = ""
+	cert.NextPrivateKey = ""
+	cert.RotatedTime = util.GetCurrentTime()
+
+	if cert.RotationIntervalDays > 0 {
+		return generateCertNextKey(cert)
+	}
+	return nil
+}
+
+// updateRotatedCert saves the rotated cert, unless it has been rotated by another instance in the meantime
+func updateRotatedCert(cert *Cert, oldRotatedTime string) bool {
+	session := adapter.Engine.ID(core.PK{cert.Owner, cert.Name})
+	if oldRotatedTime == "" {
+		// the certs created before key rotation have no rotated_time
+		session = session.Where("rotated_time = ? or rotated_time is null", oldRotatedTime)
+	} else {
+		session = session.Where("rotated_time = ?", oldRotatedTime)
+	}
+
+	affected, err := session.AllCols().Update(cert)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// PrepareCertNextKey generates the next key of the cert, so that it is published in the JWKS before it is used
+func PrepareCertNextKey(id string) (bool, error) {
+	cert := GetCert(id)
+	if cert == nil {
+		return false, nil
+	}
+
+	err := generateCertNextKey(cert)
+	if err != nil {
+		return false, err
+	}
+
+	return updateRotatedCert(cert, cert.RotatedTime), nil
+}
+
+func RotateCert(id string) (bool, error) {
+	cert := GetCert(id)
+	if cert == nil {
+		return false, nil
+	}
+
+	oldRotatedTime := cert.RotatedTime
+	err := rotateCertKey(cert)
+	if err != nil {
+		return false, err
+	}
+
+	return updateRotatedCert(cert, oldRotatedTime), nil
+}
+
+func isCertRotationDue(cert *Cert) bool {
+	if cert.RotationIntervalDays <= 0 {
+		return false
+	}
+
+	rotatedTime := cert.RotatedTime
+	if rotatedTime == "" {
+		rotatedTime = cert.CreatedTime
+	}
+	t, err := time.Parse(time.RFC3339, rotatedTime)
+	if err != nil {
+		return true
+	}
+	return time.Now().After(t.AddDate(0, 0, cert.RotationIntervalDays))
+}
+
+// runCertRotation rotates the certs whose rotation is due once their previous keys have expired, publishes the next keys of the certs with a
+// rotation policy, and drops the expired previous keys.
+func runCertRotation() {
+	for _, cert := range GetCerts("admin") {
+		oldRotatedTime := cert.RotatedTime
+		changed := false
+
+		if isCertRotationDue(cert) && !isCertPreviousKeyValid(cert) {
+			err := rotateCertKey(cert)
+			if err != nil {
+				logs.Error("failed to rotate the cert: %s, error: %s", cert.Name, err.Error())
+				continue
+			}
+			changed = true
+		} else if cert.RotationIntervalDays > 0 && cert.NextPublicKey == "" {
+			err := generateCertNextKey(cert)
+			if err != nil {
+				logs.Error("failed to generate the next key of the cert: %s, error: %s", cert.Name, err.Error())
+				continue
+			}
+			changed = true
+		}
+
+		if cert.PreviousPublicKey != "" && !isCertPreviousKeyValid(cert) {
+			cert.PreviousKeyId = ""
+			cert.PreviousPublicKey = ""
+			cert.PreviousKeyExpireTime = ""
+			changed = true
+		}
+
+		if changed && updateRotatedCert(cert, oldRotatedTime) {
+			logs.Info("the keys of the cert: %s have been updated by the rotation", cert.Name)
+		}
+	}
+}
+
+func RunCertRotationJob() {
+	ticker := time.NewTicker(certRotationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		runCertRotation()
+		<-ticker.C
+	}
+}
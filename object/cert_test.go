@@ -0,0 +1,63 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetMaskedCerts(t *testing.T) {
+	certs := GetMaskedCerts([]*Cert{
+		{Name: "cert-built-in", PublicKey: "public", PrivateKey: "private", NextPublicKey: "next public", NextPrivateKey: "next private"},
+		{Name: "cert-without-next-key", PublicKey: "public", PrivateKey: "private"},
+	})
+
+	if certs[0].PrivateKey != "***" || certs[0].NextPrivateKey != "***" || certs[1].PrivateKey != "***" {
+		t.Errorf("the private keys of the certs aren't masked: %s, %s, %s", certs[0].PrivateKey, certs[0].NextPrivateKey, certs[1].PrivateKey)
+	}
+	if certs[0].PublicKey != "public" || certs[0].NextPublicKey != "next public" || certs[1].NextPrivateKey != "" {
+		t.Errorf("the public keys of the certs are masked: %#v", certs[0])
+	}
+}
+
+func TestRotateCertKeyWithValidPreviousKey(t *testing.T) {
+	cert := &Cert{
+		Name:                  "cert-rotated",
+		KeyId:                 "current",
+		PublicKey:             "public",
+		PrivateKey:            "private",
+		NextKeyId:             "next",
+		NextPublicKey:         "next public",
+		NextPrivateKey:        "next private",
+		PreviousKeyId:         "previous",
+		PreviousPublicKey:     "previous public",
+		PreviousKeyExpireTime: time.Now().Add(time.Hour).Format(time.RFC3339),
+	}
+
+	err := rotateCertKey(cert)
+	if err == nil {
+		t.Errorf("the cert is rotated while its previous key is still valid")
+	}
+	if cert.KeyId != "current" || cert.PublicKey != "public" || cert.NextPublicKey != "next public" || cert.PreviousPublicKey != "previous public" {
+		t.Errorf("the keys of the cert are changed by the refused rotation: %#v", cert)
+	}
+
+	cert.PreviousKeyExpireTime = time.Now().Add(-time.Hour).Format(time.RFC3339)
+	err = checkCertKeyReplaceable(cert)
+	if err != nil {
+		t.Errorf("the key of the cert can't be replaced after its previous key has expired: %s", err.Error())
+	}
+}
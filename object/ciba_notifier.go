@@ -0,0 +1,138 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/astaxie/beego/logs"
+)
+
+// CibaNotifier alerts the user of a backchannel authentication request, the link is the page
+// where the signed-in user approves or denies it
+type CibaNotifier interface {
+	Notify(application *Application, user *User, cibaRequest *CibaRequest, link string) error
+}
+
+// the notifiers of the provider categories, the first ones are the Email and the SMS providers
+var cibaNotifierFactories = map[string]func(provider *Provider) CibaNotifier{
+	"Email": func(provider *Provider) CibaNotifier { return &EmailCibaNotifier{Provider: provider} },
+	"SMS":   func(provider *Provider) CibaNotifier { return &SmsCibaNotifier{Provider: provider} },
+}
+
+// RegisterCibaNotifier makes the providers of the category alert the users of the backchannel authentication requests
+func RegisterCibaNotifier(category string, factory func(provider *Provider) CibaNotifier) {
+	cibaNotifierFactories[category] = factory
+}
+
+type EmailCibaNotifier struct {
+	Provider *Provider
+}
+
+func (notifier *EmailCibaNotifier) Notify(application *Application, user *User, cibaRequest *CibaRequest, link string) error {
+	if user.Email == "" {
+		return errors.New("the user has no email")
+	}
+
+	title := fmt.Sprintf("Sign in to %s", getCibaApplicationName(application))
+	content := fmt.Sprintf("<p>%s is asking you to sign in.</p>", html.EscapeString(getCibaApplicationName(application)))
+	if cibaRequest.BindingMessage != "" {
+		content += fmt.Sprintf("<p>Make sure the code matches the one you were given: <b>%s</b></p>", html.EscapeString(cibaRequest.BindingMessage))
+	}
+	content += fmt.Sprintf("<p><a href=\"%s\">Approve or deny the sign in</a></p>", html.EscapeString(link))
+
+	return SendEmail(notifier.Provider, title, content, user.Email, application.Organization)
+}
+
+// SmsCibaNotifier sends the binding message in the template of the SMS provider, or the name of the application
+// if the client has no binding message, the user approves the request on the page of the pending requests
+type SmsCibaNotifier struct {
+	Provider *Provider
+}
+
+func (notifier *SmsCibaNotifier) Notify(application *Application, user *User, cibaRequest *CibaRequest, link string) error {
+	if user.Phone == "" {
+		return errors.New("the user has no phone")
+	}
+
+	content := cibaRequest.BindingMessage
+	if content == "" {
+		content = getCibaApplicationName(application)
+	}
+
+	phone := user.Phone
+	if !strings.HasPrefix(phone, "+") {
+		organization := getOrganization("admin", user.Owner)
+		if organization != nil && organization.PhonePrefix != "" {
+			phone = fmt.Sprintf("+%s%s", organization.PhonePrefix, phone)
+		}
+	}
+
+	return SendSms(notifier.Provider, content, phone)
+}
+
+func getCibaApplicationName(application *Application) string {
+	if application.DisplayName != "" {
+		return application.DisplayName
+	}
+	return application.Name
+}
+
+// getCibaNotifiers returns the notifiers of the providers of the application, in the order of the providers
+func getCibaNotifiers(application *Application, providers []*Provider) []CibaNotifier {
+	providerMap := map[string]*Provider{}
+	for _, provider := range providers {
+		providerMap[provider.Name] = provider
+	}
+
+	notifiers := []CibaNotifier{}
+	for _, providerItem := range application.Providers {
+		provider, ok := providerMap[providerItem.Name]
+		if !ok {
+			continue
+		}
+
+		if factory, ok := cibaNotifierFactories[provider.Category]; ok {
+			notifiers = append(notifiers, factory(provider))
+		}
+	}
+	return notifiers
+}
+
+// notifyCibaUser alerts the user through all the notifiers of the application, it fails only if none of them could
+func notifyCibaUser(application *Application, user *User, cibaRequest *CibaRequest, link string) error {
+	notifiers := getCibaNotifiers(application, GetProviders(application.Owner))
+	if len(notifiers) == 0 {
+		return fmt.Errorf("the application: %s has no provider to notify the user", application.Name)
+	}
+
+	notified := false
+	for _, notifier := range notifiers {
+		err := notifier.Notify(application, user, cibaRequest, link)
+		if err != nil {
+			logs.Warning("failed to notify the user: %s of the backchannel authentication request, error: %s", user.GetId(), err.Error())
+			continue
+		}
+		notified = true
+	}
+
+	if !notified {
+		return errors.New("the user can't be notified")
+	}
+	return nil
+}
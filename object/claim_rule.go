@@ -0,0 +1,113 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const propertiesClaimPrefix = "properties."
+
+// ClaimRule releases the claims to the application when the scope is requested. A claim is a user field
+// by its JSON name, like "phone", or a key of the user's properties, like "properties.department".
+type ClaimRule struct {
+	Scope  string   `json:"scope"`
+	Claims []string `json:"claims"`
+}
+
+// the secrets of the user are never released, whatever the rules say: the password and what is derived from it,
+// and the ID card number, which the user proves who they are with
+var unreleasableClaims = map[string]bool{
+	"password":     true,
+	"passwordSalt": true,
+	"hash":         true,
+	"preHash":      true,
+	"idCard":       true,
+}
+
+// getReleasedClaims returns the claims about the user that the claim rules of the application release for the scope.
+// The released properties are gathered in the "properties" claim.
+func getReleasedClaims(application *Application, user *User, scope string) map[string]interface{} {
+	res := map[string]interface{}{}
+	if len(application.ClaimRules) == 0 {
+		return res
+	}
+
+	userMap, err := getUserClaimMap(user)
+	if err != nil {
+		panic(err)
+	}
+
+	properties := map[string]string{}
+	for _, claimRule := range application.ClaimRules {
+		if !hasScope(scope, claimRule.Scope) {
+			continue
+		}
+
+		for _, claim := range claimRule.Claims {
+			if strings.HasPrefix(claim, propertiesClaimPrefix) {
+				key := strings.TrimPrefix(claim, propertiesClaimPrefix)
+				if value, ok := user.Properties[key]; ok {
+					properties[key] = value
+				}
+			} else if value, ok := userMap[claim]; ok && !unreleasableClaims[claim] {
+				res[claim] = value
+			}
+		}
+	}
+
+	if len(properties) != 0 {
+		res["properties"] = properties
+	}
+	return res
+}
+
+// getUserClaimMap returns the user fields by their JSON names, which are the names of the claims about the user
+func getUserClaimMap(user *User) (map[string]interface{}, error) {
+	userJson, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]interface{}{}
+	err = json.Unmarshal(userJson, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// addReleasedClaims returns the claims along with the released claims, the released claims don't override
+// the claims of the same name.
+func addReleasedClaims(claims interface{}, releasedClaims map[string]interface{}) (map[string]interface{}, error) {
+	claimsJson, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]interface{}{}
+	err = json.Unmarshal(claimsJson, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	for claim, value := range releasedClaims {
+		if _, ok := res[claim]; !ok {
+			res[claim] = value
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestGetReleasedClaims(t *testing.T) {
+	user := &User{
+		Owner:        "org-test",
+		Name:         "user-test",
+		Phone:        "13800000000",
+		Password:     "secret",
+		PasswordSalt: "salt",
+		Hash:         "hash",
+		PreHash:      "pre-hash",
+		IdCard:       "110101199001011234",
+		Properties:   map[string]string{"department": "sales"},
+	}
+	application := &Application{
+		ClaimRules: []*ClaimRule{
+			{Scope: "phone", Claims: []string{"phone", "properties.department"}},
+			{Scope: "secrets", Claims: []string{"password", "passwordSalt", "hash", "preHash", "idCard"}},
+		},
+	}
+
+	claims := getReleasedClaims(application, user, "openid phone secrets")
+	if claims["phone"] != "13800000000" {
+		t.Errorf("the phone isn't released: %v", claims)
+	}
+	if properties, ok := claims["properties"].(map[string]string); !ok || properties["department"] != "sales" {
+		t.Errorf("the properties released are %v", claims["properties"])
+	}
+	for _, claim := range []string{"password", "passwordSalt", "hash", "preHash", "idCard"} {
+		if _, ok := claims[claim]; ok {
+			t.Errorf("the secret: %s is released", claim)
+		}
+	}
+
+	if claims = getReleasedClaims(application, user, "openid"); len(claims) != 0 {
+		t.Errorf("the claims released without their scope are %v", claims)
+	}
+}
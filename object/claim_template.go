@@ -0,0 +1,227 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+)
+
+// The sources of the values of the custom claims
+const (
+	ClaimSourceUser        = "User"
+	ClaimSourceProperty    = "Property"
+	ClaimSourceRoles       = "Roles"
+	ClaimSourcePermissions = "Permissions"
+	ClaimSourceStatic      = "Static"
+)
+
+// The tokens that the custom claims are added to
+const (
+	ClaimTargetAll      = ""
+	ClaimTargetJwt      = "JWT"
+	ClaimTargetUserinfo = "Userinfo"
+)
+
+// ClaimTemplate maps a value to a custom claim of the access token and the userinfo of the application. The value is:
+//   - User: the user field of the JSON name Value, like "tag"
+//   - Property: the user property of the key Value, like "department"
+//   - Roles: the names of the enabled roles of the user
+//   - Permissions: the names of the enabled permissions of the user
+//   - Static: Value itself
+//
+// Name renames the claim, which defaults to Value. The claim is only added when Scope is requested, if any.
+type ClaimTemplate struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Value  string `json:"value"`
+	Scope  string `json:"scope"`
+	Target string `json:"target"`
+}
+
+// the applications without claim templates keep the "tag" claim that the WeChat mini program flow relies on,
+// except for the "JWT-Empty" token format, whose tokens carry no claims about the user
+var defaultClaimTemplates = []*ClaimTemplate{
+	{Name: "tag", Source: ClaimSourceUser, Value: "tag", Target: ClaimTargetJwt},
+}
+
+func (claimTemplate *ClaimTemplate) getClaimName(namespace string) string {
+	name := claimTemplate.Name
+	if name == "" {
+		name = claimTemplate.Value
+	}
+	return namespace + name
+}
+
+func (claimTemplate *ClaimTemplate) isForTarget(target string) bool {
+	return claimTemplate.Target == ClaimTargetAll || claimTemplate.Target == target
+}
+
+// getUserRoleIds returns the IDs of the enabled roles of the user among the roles of the organization of the user,
+// including the roles granted through another role
+func getUserRoleIds(user *User, roles []*Role) map[string]bool {
+	res := map[string]bool{}
+	for {
+		added := false
+		for _, role := range roles {
+			if !role.IsEnabled || res[role.GetId()] {
+				continue
+			}
+
+			granted := false
+			for _, userId := range role.Users {
+				if userId == user.GetId() {
+					granted = true
+				}
+			}
+			for _, roleId := range role.Roles {
+				if res[roleId] {
+					granted = true
+				}
+			}
+			if granted {
+				res[role.GetId()] = true
+				added = true
+			}
+		}
+		if !added {
+			return res
+		}
+	}
+}
+
+func getUserRoleNames(roles []*Role, roleIds map[string]bool) []string {
+	res := []string{}
+	for _, role := range roles {
+		if roleIds[role.GetId()] {
+			res = append(res, role.Name)
+		}
+	}
+	return res
+}
+
+func getUserPermissionNames(user *User, roleIds map[string]bool) []string {
+	res := []string{}
+	for _, permission := range GetPermissions(user.Owner) {
+		if !permission.IsEnabled {
+			continue
+		}
+
+		granted := false
+		for _, userId := range permission.Users {
+			if userId == user.GetId() {
+				granted = true
+			}
+		}
+		for _, roleId := range permission.Roles {
+			if roleIds[roleId] {
+				granted = true
+			}
+		}
+		if granted {
+			res = append(res, permission.Name)
+		}
+	}
+	return res
+}
+
+// addCustomClaims adds the custom claims that the claim templates of the application map for the target and the scope
+// to the claims, without overriding the claims of the same name. The custom claims are prefixed with the claim namespace
+// of the application, like "https://example.com/claims/".
+func addCustomClaims(claims map[string]interface{}, application *Application, user *User, scope string, target string) error {
+	res := map[string]interface{}{}
+	claimTemplates := application.ClaimTemplates
+	namespace := application.ClaimNamespace
+	if len(claimTemplates) == 0 {
+		if application.TokenFormat == "JWT-Empty" {
+			return nil
+		}
+		claimTemplates = defaultClaimTemplates
+		namespace = ""
+	}
+
+	var userMap map[string]interface{}
+	var roles []*Role
+	var roleIds map[string]bool
+	for _, claimTemplate := range claimTemplates {
+		if !claimTemplate.isForTarget(target) || (claimTemplate.Scope != "" && !hasScope(scope, claimTemplate.Scope)) {
+			continue
+		}
+
+		name := claimTemplate.getClaimName(namespace)
+		switch claimTemplate.Source {
+		case ClaimSourceUser:
+			if userMap == nil {
+				var err error
+				userMap, err = getUserClaimMap(user)
+				if err != nil {
+					return err
+				}
+			}
+			// the empty user fields are left out, like the omitempty fields of the tokens
+			if value, ok := userMap[claimTemplate.Value]; ok && value != "" && !unreleasableClaims[claimTemplate.Value] {
+				res[name] = value
+			}
+		case ClaimSourceProperty:
+			if value, ok := user.Properties[claimTemplate.Value]; ok {
+				res[name] = value
+			}
+		case ClaimSourceRoles, ClaimSourcePermissions:
+			if roleIds == nil {
+				roles = GetRoles(user.Owner)
+				roleIds = getUserRoleIds(user, roles)
+			}
+			if claimTemplate.Source == ClaimSourceRoles {
+				res[name] = getUserRoleNames(roles, roleIds)
+			} else {
+				res[name] = getUserPermissionNames(user, roleIds)
+			}
+		case ClaimSourceStatic:
+			res[name] = claimTemplate.Value
+		}
+	}
+
+	for claim, value := range res {
+		if _, ok := claims[claim]; !ok {
+			claims[claim] = value
+		}
+	}
+	return nil
+}
+
+// CheckClaimTemplates returns an error if one of the claim templates of the application is invalid
+func (application *Application) CheckClaimTemplates() error {
+	for _, claimTemplate := range application.ClaimTemplates {
+		switch claimTemplate.Source {
+		case ClaimSourceUser, ClaimSourceProperty:
+			if claimTemplate.Value == "" {
+				return fmt.Errorf("the custom claim of the source: %s needs the name of its user field or property", claimTemplate.Source)
+			}
+			if claimTemplate.Source == ClaimSourceUser && unreleasableClaims[claimTemplate.Value] {
+				return fmt.Errorf("the user field: %s can't be a custom claim", claimTemplate.Value)
+			}
+		case ClaimSourceRoles, ClaimSourcePermissions, ClaimSourceStatic:
+			if claimTemplate.Name == "" {
+				return fmt.Errorf("the custom claim of the source: %s needs a name", claimTemplate.Source)
+			}
+		default:
+			return fmt.Errorf("the source: \"%s\" of the custom claim is not supported", claimTemplate.Source)
+		}
+
+		if claimTemplate.Target != ClaimTargetAll && claimTemplate.Target != ClaimTargetJwt && claimTemplate.Target != ClaimTargetUserinfo {
+			return fmt.Errorf("the target: \"%s\" of the custom claim is not supported", claimTemplate.Target)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestAddCustomClaims(t *testing.T) {
+	user := &User{
+		Owner:      "org-test",
+		Name:       "user-test",
+		Tag:        "staff",
+		Password:   "secret",
+		Properties: map[string]string{"department": "sales"},
+	}
+	application := &Application{
+		ClaimNamespace: "https://example.com/",
+		ClaimTemplates: []*ClaimTemplate{
+			{Name: "group", Source: ClaimSourceUser, Value: "tag"},
+			{Source: ClaimSourceProperty, Value: "department", Target: ClaimTargetUserinfo},
+			{Name: "tenant", Source: ClaimSourceStatic, Value: "acme", Scope: "tenant"},
+			{Name: "password", Source: ClaimSourceUser, Value: "password"},
+		},
+	}
+
+	claims := map[string]interface{}{"https://example.com/group": "kept"}
+	err := addCustomClaims(claims, application, user, "openid tenant", ClaimTargetJwt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["https://example.com/group"] != "kept" {
+		t.Errorf("the custom claim overrides the claim of the same name: %v", claims)
+	}
+	if claims["https://example.com/tenant"] != "acme" {
+		t.Errorf("the static claim is missing: %v", claims)
+	}
+	if _, ok := claims["https://example.com/department"]; ok {
+		t.Errorf("the userinfo claim is added to the JWT: %v", claims)
+	}
+	if _, ok := claims["https://example.com/password"]; ok {
+		t.Errorf("the password is released: %v", claims)
+	}
+
+	claims = map[string]interface{}{}
+	err = addCustomClaims(claims, application, user, "openid", ClaimTargetUserinfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claims) != 2 || claims["https://example.com/group"] != "staff" || claims["https://example.com/department"] != "sales" {
+		t.Errorf("the userinfo claims are wrong: %v", claims)
+	}
+
+	// the applications without claim templates keep the tag claim
+	claims = map[string]interface{}{}
+	err = addCustomClaims(claims, &Application{}, user, "", ClaimTargetJwt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claims) != 1 || claims["tag"] != "staff" {
+		t.Errorf("the tag claim is wrong: %v", claims)
+	}
+
+	// while the "JWT-Empty" tokens carry no claims about the user
+	claims = map[string]interface{}{}
+	err = addCustomClaims(claims, &Application{TokenFormat: "JWT-Empty"}, user, "", ClaimTargetJwt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claims) != 0 {
+		t.Errorf("the JWT-Empty claims are %v", claims)
+	}
+}
+
+func TestGetUserRoleIds(t *testing.T) {
+	user := &User{Owner: "org-test", Name: "user-test"}
+	roles := []*Role{
+		{Owner: "org-test", Name: "admin", Roles: []string{"org-test/staff"}, IsEnabled: true},
+		{Owner: "org-test", Name: "staff", Users: []string{"org-test/user-test"}, IsEnabled: true},
+		{Owner: "org-test", Name: "auditor", Users: []string{"org-test/user-test"}},
+		{Owner: "org-test", Name: "guest", Users: []string{"org-test/other"}, IsEnabled: true},
+	}
+
+	// the roles granted through another role are the user's too, whatever their order
+	roleIds := getUserRoleIds(user, roles)
+	if len(roleIds) != 2 || !roleIds["org-test/staff"] || !roleIds["org-test/admin"] {
+		t.Errorf("getUserRoleIds() = %v", roleIds)
+	}
+	if names := getUserRoleNames(roles, roleIds); len(names) != 2 || names[0] != "admin" || names[1] != "staff" {
+		t.Errorf("getUserRoleNames() = %v", names)
+	}
+}
+
+func TestCheckClaimTemplates(t *testing.T) {
+	scenarios := []struct {
+		claimTemplate *ClaimTemplate
+		valid         bool
+	}{
+		{&ClaimTemplate{Source: ClaimSourceUser, Value: "email"}, true},
+		{&ClaimTemplate{Name: "roles", Source: ClaimSourceRoles}, true},
+		{&ClaimTemplate{Source: ClaimSourcePermissions}, false},
+		{&ClaimTemplate{Source: ClaimSourceProperty}, false},
+		{&ClaimTemplate{Source: ClaimSourceUser, Value: "passwordSalt"}, false},
+		{&ClaimTemplate{Source: ClaimSourceUser, Value: "preHash"}, false},
+		{&ClaimTemplate{Name: "env", Source: "Env", Value: "HOME"}, false},
+		{&ClaimTemplate{Name: "tenant", Source: ClaimSourceStatic, Value: "acme", Target: "ID token"}, false},
+	}
+
+	for _, scenario := range scenarios {
+		application := &Application{ClaimTemplates: []*ClaimTemplate{scenario.claimTemplate}}
+		err := application.CheckClaimTemplates()
+		if (err == nil) != scenario.valid {
+			t.Errorf("CheckClaimTemplates(%+v) = %v, want valid: %v", scenario.claimTemplate, err, scenario.valid)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gopkg.in/square/go-jose.v2"
+)
+
+// JWT client authentication, per rfc 7523 and OpenID Connect Core section 9
+// https://datatracker.ietf.org/doc/html/rfc7523
+// https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication
+const (
+	ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	ClientSecretBasic = "client_secret_basic"
+	ClientSecretPost  = "client_secret_post"
+	ClientSecretJwt   = "client_secret_jwt"
+	PrivateKeyJwt     = "private_key_jwt"
+)
+
+var (
+	clientSecretJwtMethods = []string{"HS256", "HS384", "HS512"}
+	privateKeyJwtMethods   = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+)
+
+// RequiresClientAssertion tells whether the application has to authenticate with a client_assertion
+// instead of sending its client secret.
+func (application *Application) RequiresClientAssertion() bool {
+	return application.TokenEndpointAuthMethod == ClientSecretJwt || application.TokenEndpointAuthMethod == PrivateKeyJwt
+}
+
+// IsPublicClient tells whether the application is a public client, which has no client secret to authenticate with
+// and proves that it started the authorization request with PKCE instead
+func (application *Application) IsPublicClient() bool {
+	return application.TokenEndpointAuthMethod == ClientAuthMethodNone
+}
+
+// getClientAssertionKey returns the key that verifies a private_key_jwt assertion: the key with the
+// matching kid in the JWKS of the application, or else the public key of the application.
+func getClientAssertionKey(application *Application, kid string) (interface{}, error) {
+	if application.ClientJwks != "" {
+		jwks := jose.JSONWebKeySet{}
+		err := json.Unmarshal([]byte(application.ClientJwks), &jwks)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range jwks.Keys {
+			if (kid == "" || key.KeyID == kid) && key.Use != "enc" {
+				return key.Public().Key, nil
+			}
+		}
+		return nil, fmt.Errorf("the JWKS of the application has no key: %s", kid)
+	}
+
+	if application.ClientPublicKey != "" {
+		if publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(application.ClientPublicKey)); err == nil {
+			return publicKey, nil
+		}
+		return jwt.ParseECPublicKeyFromPEM([]byte(application.ClientPublicKey))
+	}
+
+	return nil, errors.New("the application has neither a JWKS nor a public key")
+}
+
+// getClientAssertionAudiences returns the audiences that the client_assertion can be addressed to,
+// which include the issuer of the organization of the application if it has its own one
+func getClientAssertionAudiences(application *Application, host string) []string {
+	oidcDiscovery := GetOidcDiscovery(host)
+	return []string{
+		oidcDiscovery.Issuer,
+		getJwtIssuer(application, host),
+		oidcDiscovery.TokenEndpoint,
+		fmt.Sprintf("%s/api/login/oauth/refresh_token", oidcDiscovery.Issuer),
+		oidcDiscovery.IntrospectionEndpoint,
+		oidcDiscovery.RevocationEndpoint,
+		oidcDiscovery.PushedAuthorizationRequestEndpoint,
+		oidcDiscovery.DeviceAuthorizationEndpoint,
+	}
+}
+
+// ValidateClientAssertion authenticates the client by its client_assertion and returns the client application.
+// The assertion must be issued by the client for itself, be addressed to Casdoor, not be expired and
+// not be replayed.
+func ValidateClientAssertion(clientId string, clientAssertionType string, clientAssertion string, host string) (*Application, error) {
+	if clientAssertionType != ClientAssertionType {
+		return nil, fmt.Errorf("error: client_assertion_type: %s is not supported", clientAssertionType)
+	}
+
+	claims := jwt.RegisteredClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(clientAssertion, &claims)
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid client_assertion: %s", err.Error())
+	}
+
+	// the client_id parameter is optional, the client is identified by the assertion
+	if clientId == "" {
+		clientId = claims.Subject
+	}
+	if claims.Issuer != clientId || claims.Subject != clientId {
+		return nil, errors.New("error: the iss and sub of the client_assertion should be the client_id")
+	}
+
+	application := GetApplicationByClientId(clientId)
+	if application == nil {
+		return nil, errors.New("error: invalid client_id")
+	}
+
+	claims, err = verifyClientAssertion(application, clientAssertion, getClientAssertionAudiences(application, host))
+	if err != nil {
+		return nil, err
+	}
+
+	if !useJti(application.Owner, fmt.Sprintf("client_assertion/%s", application.Name), claims.ID, claims.ExpiresAt.Unix()) {
+		return nil, errors.New("error: the client_assertion has already been used")
+	}
+
+	return application, nil
+}
+
+// verifyClientAssertion verifies the signature of the client_assertion with the client secret or the keys
+// of the application, and checks that it is addressed to one of the audiences and can't be replayed forever
+func verifyClientAssertion(application *Application, clientAssertion string, audiences []string) (jwt.RegisteredClaims, error) {
+	claims := jwt.RegisteredClaims{}
+	var validMethods []string
+	var keyFunc jwt.Keyfunc
+	switch application.TokenEndpointAuthMethod {
+	case ClientSecretJwt:
+		validMethods = clientSecretJwtMethods
+		keyFunc = func(token *jwt.Token) (interface{}, error) {
+			return []byte(application.ClientSecret), nil
+		}
+	case PrivateKeyJwt:
+		validMethods = privateKeyJwtMethods
+		keyFunc = func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return getClientAssertionKey(application, kid)
+		}
+	default:
+		return claims, fmt.Errorf("error: the application: %s does not support the client_assertion authentication", application.Name)
+	}
+
+	_, err := jwt.NewParser(jwt.WithValidMethods(validMethods)).ParseWithClaims(clientAssertion, &claims, keyFunc)
+	if err != nil {
+		return claims, fmt.Errorf("error: invalid client_assertion: %s", err.Error())
+	}
+
+	if claims.ExpiresAt == nil {
+		return claims, errors.New("error: the client_assertion should have an exp")
+	}
+	if claims.ID == "" {
+		return claims, errors.New("error: the client_assertion should have a jti")
+	}
+
+	for _, audience := range audiences {
+		if claims.VerifyAudience(audience, true) {
+			return claims, nil
+		}
+	}
+	return claims, errors.New("error: the aud of the client_assertion should be the issuer or the endpoint of Casdoor")
+}
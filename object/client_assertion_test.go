@@ -0,0 +1,133 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gopkg.in/square/go-jose.v2"
+)
+
+var testAssertionAudiences = []string{"https://door.casdoor.com", "https://door.casdoor.com/api/login/oauth/access_token"}
+
+func getTestAssertionClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    "client-id",
+		Subject:   "client-id",
+		Audience:  []string{"https://door.casdoor.com/api/login/oauth/access_token"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		ID:        "jti-1",
+	}
+}
+
+func signTestAssertion(t *testing.T, method jwt.SigningMethod, claims jwt.RegisteredClaims, kid string, key interface{}) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	assertion, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return assertion
+}
+
+func TestVerifyClientSecretJwt(t *testing.T) {
+	application := &Application{Name: "app-client", ClientId: "client-id", ClientSecret: "client-secret", TokenEndpointAuthMethod: ClientSecretJwt}
+
+	assertion := signTestAssertion(t, jwt.SigningMethodHS256, getTestAssertionClaims(), "", []byte("client-secret"))
+	if _, err := verifyClientAssertion(application, assertion, testAssertionAudiences); err != nil {
+		t.Errorf("the valid assertion is refused: %v", err)
+	}
+
+	assertion = signTestAssertion(t, jwt.SigningMethodHS256, getTestAssertionClaims(), "", []byte("wrong-secret"))
+	if _, err := verifyClientAssertion(application, assertion, testAssertionAudiences); err == nil {
+		t.Errorf("the assertion signed with a wrong secret is accepted")
+	}
+
+	// the client secret can't be used for the assertions of a private_key_jwt client
+	application.TokenEndpointAuthMethod = PrivateKeyJwt
+	application.ClientJwks = `{"keys":[]}`
+	assertion = signTestAssertion(t, jwt.SigningMethodHS256, getTestAssertionClaims(), "", []byte("client-secret"))
+	if _, err := verifyClientAssertion(application, assertion, testAssertionAudiences); err == nil {
+		t.Errorf("the HMAC assertion of a private_key_jwt client is accepted")
+	}
+
+	application.TokenEndpointAuthMethod = ""
+	if _, err := verifyClientAssertion(application, assertion, testAssertionAudiences); err == nil {
+		t.Errorf("the assertion of a client_secret_basic client is accepted")
+	}
+}
+
+func TestVerifyPrivateKeyJwt(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "key-1", Use: "sig", Algorithm: "RS256"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	application := &Application{Name: "app-client", ClientId: "client-id", ClientSecret: "client-secret", TokenEndpointAuthMethod: PrivateKeyJwt, ClientJwks: string(jwks)}
+
+	assertion := signTestAssertion(t, jwt.SigningMethodRS256, getTestAssertionClaims(), "key-1", key)
+	if _, err := verifyClientAssertion(application, assertion, testAssertionAudiences); err != nil {
+		t.Errorf("the valid assertion is refused: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		assertion string
+	}{
+		{"another key", signTestAssertion(t, jwt.SigningMethodRS256, getTestAssertionClaims(), "key-1", otherKey)},
+		{"unknown kid", signTestAssertion(t, jwt.SigningMethodRS256, getTestAssertionClaims(), "key-2", key)},
+		{"no exp", signTestAssertion(t, jwt.SigningMethodRS256, func() jwt.RegisteredClaims {
+			claims := getTestAssertionClaims()
+			claims.ExpiresAt = nil
+			return claims
+		}(), "key-1", key)},
+		{"expired", signTestAssertion(t, jwt.SigningMethodRS256, func() jwt.RegisteredClaims {
+			claims := getTestAssertionClaims()
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			return claims
+		}(), "key-1", key)},
+		{"no jti", signTestAssertion(t, jwt.SigningMethodRS256, func() jwt.RegisteredClaims {
+			claims := getTestAssertionClaims()
+			claims.ID = ""
+			return claims
+		}(), "key-1", key)},
+		{"wrong aud", signTestAssertion(t, jwt.SigningMethodRS256, func() jwt.RegisteredClaims {
+			claims := getTestAssertionClaims()
+			claims.Audience = []string{"https://attacker.example.com"}
+			return claims
+		}(), "key-1", key)},
+		{"alg none", signTestAssertion(t, jwt.SigningMethodNone, getTestAssertionClaims(), "key-1", jwt.UnsafeAllowNoneSignatureType)},
+	}
+	for _, test := range tests {
+		if _, err := verifyClientAssertion(application, test.assertion, testAssertionAudiences); err == nil {
+			t.Errorf("%s: the assertion is accepted", test.name)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"xorm.io/core"
+)
+
+// OAuth 2.0 Dynamic Client Registration, per rfc 7591 and rfc 7592
+// https://datatracker.ietf.org/doc/html/rfc7591
+// https://datatracker.ietf.org/doc/html/rfc7592
+const (
+	ClientAuthMethodNone = "none"
+)
+
+var registrationGrantTypes = map[string][]string{
+	"authorization_code":   {"authorization_code"},
+	"implicit":             {"token", "id_token"},
+	"password":             {"password"},
+	"client_credentials":   {"client_credentials"},
+	"refresh_token":        {"refresh_token"},
+	DeviceCodeGrantType:    {DeviceCodeGrantType},
+	CibaGrantType:          {CibaGrantType},
+	TokenExchangeGrantType: {TokenExchangeGrantType},
+}
+
+type ClientMetadata struct {
+	RedirectUris                          []string        `json:"redirect_uris,omitempty"`
+	TokenEndpointAuthMethod               string          `json:"token_endpoint_auth_method,omitempty"`
+	GrantTypes                            []string        `json:"grant_types,omitempty"`
+	ResponseTypes                         []string        `json:"response_types,omitempty"`
+	ClientName                            string          `json:"client_name,omitempty"`
+	ClientUri                             string          `json:"client_uri,omitempty"`
+	LogoUri                               string          `json:"logo_uri,omitempty"`
+	TosUri                                string          `json:"tos_uri,omitempty"`
+	Jwks                                  json.RawMessage `json:"jwks,omitempty"`
+	JwksUri                               string          `json:"jwks_uri,omitempty"`
+	PostLogoutRedirectUris                []string        `json:"post_logout_redirect_uris,omitempty"`
+	BackchannelLogoutUri                  string          `json:"backchannel_logout_uri,omitempty"`
+	FrontchannelLogoutUri                 string          `json:"frontchannel_logout_uri,omitempty"`
+	FrontchannelLogoutSessionRequired     bool            `json:"frontchannel_logout_session_required,omitempty"`
+	UserinfoSignedResponseAlg             string          `json:"userinfo_signed_response_alg,omitempty"`
+	UserinfoEncryptedResponseAlg          string          `json:"userinfo_encrypted_response_alg,omitempty"`
+	UserinfoEncryptedResponseEnc          string          `json:"userinfo_encrypted_response_enc,omitempty"`
+	BackchannelTokenDeliveryMode          string          `json:"backchannel_token_delivery_mode,omitempty"`
+	BackchannelClientNotificationEndpoint string          `json:"backchannel_client_notification_endpoint,omitempty"`
+}
+
+type ClientRegistrationResponse struct {
+	ClientId                string `json:"client_id"`
+	ClientSecret            string `json:"client_secret,omitempty"`
+	ClientIdIssuedAt        int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt   int64  `json:"client_secret_expires_at"`
+	RegistrationAccessToken string `json:"registration_access_token"`
+	RegistrationClientUri   string `json:"registration_client_uri"`
+	ClientMetadata
+}
+
+func getOrganizationByInitialAccessToken(initialAccessToken string) *Organization {
+	if initialAccessToken == "" {
+		return nil
+	}
+
+	organization := Organization{InitialAccessToken: initialAccessToken}
+	existed, err := adapter.Engine.Get(&organization)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &organization
+	}
+
+	return nil
+}
+
+// getRegisteredApplication returns the application of the client_id, if the registration access token is the one
+// that was issued for it.
+func getRegisteredApplication(clientId string, registrationAccessToken string) *Application {
+	application := GetApplicationByClientId(clientId)
+	if application == nil || registrationAccessToken == "" || application.RegistrationAccessToken != registrationAccessToken {
+		return nil
+	}
+
+	return application
+}
+
+func isRegistrationUriValid(uri string, allowEmpty bool) bool {
+	if uri == "" {
+		return allowEmpty
+	}
+
+	u, err := url.Parse(uri)
+	return err == nil && u.Scheme != "" && u.Host != "" && u.Fragment == ""
+}
+
+// applyClientMetadata validates the client metadata and sets it to the application.
+func applyClientMetadata(application *Application, metadata *ClientMetadata) *TokenError {
+	if len(metadata.GrantTypes) == 0 {
+		metadata.GrantTypes = []string{"authorization_code"}
+	}
+
+	grantTypes := []string{}
+	for _, grantType := range metadata.GrantTypes {
+		applicationGrantTypes, ok := registrationGrantTypes[grantType]
+		if !ok {
+			return &TokenError{Error: "invalid_client_metadata", ErrorDescription: fmt.Sprintf("grant_type: %s is not supported", grantType)}
+		}
+		grantTypes = append(grantTypes, applicationGrantTypes...)
+	}
+
+	responseTypes := []string{}
+	for _, responseType := range metadata.ResponseTypes {
+		normalizedResponseType := normalizeResponseType(responseType)
+		if normalizedResponseType == "" {
+			return &TokenError{Error: "invalid_client_metadata", ErrorDescription: fmt.Sprintf("response_type: %s is not supported", responseType)}
+		}
+		responseTypes = append(responseTypes, normalizedResponseType)
+	}
+
+	redirectUriRequired := false
+	for _, grantType := range metadata.GrantTypes {
+		if grantType == "authorization_code" || grantType == "implicit" {
+			redirectUriRequired = true
+		}
+	}
+	if redirectUriRequired && len(metadata.RedirectUris) == 0 {
+		return &TokenError{Error: "invalid_redirect_uri", ErrorDescription: "redirect_uris should not be empty"}
+	}
+	for _, redirectUri := range metadata.RedirectUris {
+		// the wildcard and regex redirect URIs are only for the applications set up by the administrators
+		if !isRegistrationUriValid(redirectUri, false) || isRedirectUriPattern(redirectUri) {
+			return &TokenError{Error: "invalid_redirect_uri", ErrorDescription: fmt.Sprintf("invalid redirect_uri: %s", redirectUri)}
+		}
+	}
+	for _, postLogoutRedirectUri := range metadata.PostLogoutRedirectUris {
+		if !isRegistrationUriValid(postLogoutRedirectUri, false) {
+			return &TokenError{Error: "invalid_redirect_uri", ErrorDescription: fmt.Sprintf("invalid post_logout_redirect_uri: %s", postLogoutRedirectUri)}
+		}
+	}
+
+	for _, uri := range []string{metadata.ClientUri, metadata.LogoUri, metadata.TosUri, metadata.BackchannelLogoutUri, metadata.FrontchannelLogoutUri} {
+		if !isRegistrationUriValid(uri, true) {
+			return &TokenError{Error: "invalid_client_metadata", ErrorDescription: fmt.Sprintf("invalid URI: %s", uri)}
+		}
+	}
+
+	if metadata.TokenEndpointAuthMethod == "" {
+		metadata.TokenEndpointAuthMethod = ClientSecretBasic
+	}
+	switch metadata.TokenEndpointAuthMethod {
+	case ClientSecretBasic, ClientSecretPost, ClientSecretJwt, ClientAuthMethodNone:
+	case PrivateKeyJwt:
+		if metadata.JwksUri != "" {
+			return &TokenError{Error: "invalid_client_metadata", ErrorDescription: "jwks_uri is not supported, please register the jwks instead"}
+		}
+		if len(metadata.Jwks) == 0 {
+			return &TokenError{Error: "invalid_client_metadata", ErrorDescription: "jwks is required for private_key_jwt"}
+		}
+	default:
+		return &TokenError{Error: "invalid_client_metadata", ErrorDescription: fmt.Sprintf("token_endpoint_auth_method: %s is not supported", metadata.TokenEndpointAuthMethod)}
+	}
+
+	application.DisplayName = metadata.ClientName
+	if application.DisplayName == "" {
+		application.DisplayName = application.Name
+	}
+	application.HomepageUrl = metadata.ClientUri
+	application.Logo = metadata.LogoUri
+	application.TermsOfUse = metadata.TosUri
+	application.RedirectUris = metadata.RedirectUris
+	application.GrantTypes = grantTypes
+	application.ResponseTypes = responseTypes
+	application.TokenEndpointAuthMethod = metadata.TokenEndpointAuthMethod
+	application.ClientJwks = string(metadata.Jwks)
+	application.PostLogoutRedirectUris = metadata.PostLogoutRedirectUris
+	application.BackchannelLogoutUri = metadata.BackchannelLogoutUri
+	application.FrontchannelLogoutUri = metadata.FrontchannelLogoutUri
+	application.FrontchannelLogoutSessionRequired = metadata.FrontchannelLogoutSessionRequired
+	application.UserinfoSignedResponseAlg = metadata.UserinfoSignedResponseAlg
+	application.UserinfoEncryptedResponseAlg = metadata.UserinfoEncryptedResponseAlg
+	application.UserinfoEncryptedResponseEnc = metadata.UserinfoEncryptedResponseEnc
+	application.BackchannelTokenDeliveryMode = metadata.BackchannelTokenDeliveryMode
+	application.BackchannelClientNotificationEndpoint = metadata.BackchannelClientNotificationEndpoint
+	if application.RedirectUris == nil {
+		application.RedirectUris = []string{}
+	}
+	if application.PostLogoutRedirectUris == nil {
+		application.PostLogoutRedirectUris = []string{}
+	}
+
+	err := application.CheckUserinfoResponse()
+	if err != nil {
+		return &TokenError{Error: "invalid_client_metadata", ErrorDescription: err.Error()}
+	}
+
+	err = application.CheckBackchannelAuthentication()
+	if err != nil {
+		return &TokenError{Error: "invalid_client_metadata", ErrorDescription: err.Error()}
+	}
+
+	return nil
+}
+
+func getClientRegistrationResponse(application *Application, metadata *ClientMetadata, host string) *ClientRegistrationResponse {
+	clientIdIssuedAt := int64(0)
+	createdTime, err := time.Parse(time.RFC3339, application.CreatedTime)
+	if err == nil {
+		clientIdIssuedAt = createdTime.Unix()
+	}
+
+	// a public client has no use for a client secret
+	clientSecret := application.ClientSecret
+	if application.IsPublicClient() {
+		clientSecret = ""
+	}
+
+	return &ClientRegistrationResponse{
+		ClientId:                application.ClientId,
+		ClientSecret:            clientSecret,
+		ClientIdIssuedAt:        clientIdIssuedAt,
+		ClientSecretExpiresAt:   0,
+		RegistrationAccessToken: application.RegistrationAccessToken,
+		RegistrationClientUri:   fmt.Sprintf("%s/api/oauth/register?client_id=%s", GetOidcDiscovery(host).Issuer, url.QueryEscape(application.ClientId)),
+		ClientMetadata:          *metadata,
+	}
+}
+
+// getClientMetadata returns the registered metadata of the application.
+func getClientMetadata(application *Application) *ClientMetadata {
+	grantTypes := []string{}
+	for registrationGrantType, applicationGrantTypes := range registrationGrantTypes {
+		for _, grantType := range application.GrantTypes {
+			if grantType == applicationGrantTypes[0] {
+				grantTypes = append(grantTypes, registrationGrantType)
+				break
+			}
+		}
+	}
+	sort.Strings(grantTypes)
+
+	tokenEndpointAuthMethod := application.TokenEndpointAuthMethod
+	if tokenEndpointAuthMethod == "" {
+		tokenEndpointAuthMethod = ClientSecretBasic
+	}
+
+	metadata := &ClientMetadata{
+		RedirectUris:                          application.RedirectUris,
+		TokenEndpointAuthMethod:               tokenEndpointAuthMethod,
+		GrantTypes:                            grantTypes,
+		ResponseTypes:                         application.ResponseTypes,
+		ClientName:                            application.DisplayName,
+		ClientUri:                             application.HomepageUrl,
+		LogoUri:                               application.Logo,
+		TosUri:                                application.TermsOfUse,
+		PostLogoutRedirectUris:                application.PostLogoutRedirectUris,
+		BackchannelLogoutUri:                  application.BackchannelLogoutUri,
+		FrontchannelLogoutUri:                 application.FrontchannelLogoutUri,
+		FrontchannelLogoutSessionRequired:     application.FrontchannelLogoutSessionRequired,
+		UserinfoSignedResponseAlg:             application.UserinfoSignedResponseAlg,
+		UserinfoEncryptedResponseAlg:          application.UserinfoEncryptedResponseAlg,
+		UserinfoEncryptedResponseEnc:          application.UserinfoEncryptedResponseEnc,
+		BackchannelTokenDeliveryMode:          application.BackchannelTokenDeliveryMode,
+		BackchannelClientNotificationEndpoint: application.BackchannelClientNotificationEndpoint,
+	}
+	if application.ClientJwks != "" {
+		metadata.Jwks = json.RawMessage(application.ClientJwks)
+	}
+	return metadata
+}
+
+// RegisterClient creates an application in the organization of the initial access token, for the client metadata.
+func RegisterClient(initialAccessToken string, metadata *ClientMetadata, host string) (*ClientRegistrationResponse, *TokenError) {
+	organization := getOrganizationByInitialAccessToken(initialAccessToken)
+	if organization == nil {
+		return nil, &TokenError{Error: "invalid_token", ErrorDescription: "invalid initial access token"}
+	}
+
+	// the registered client signs its tokens with the cert of the other applications of the organization,
+	// or with the default cert if the organization has none
+	cert := ""
+	if organizationApplication := GetApplicationByOrganizationName(organization.Name); organizationApplication != nil {
+		cert = organizationApplication.Cert
+	}
+
+	// the client_id and client_secret are generated by AddApplication
+	application := &Application{
+		Owner:                   organization.Owner,
+		Name:                    fmt.Sprintf("app-%s", util.GenerateClientId()),
+		CreatedTime:             util.GetCurrentTime(),
+		Organization:            organization.Name,
+		Cert:                    cert,
+		EnablePassword:          true,
+		Providers:               []*ProviderItem{},
+		SignupItems:             []*SignupItem{},
+		TokenFormat:             "JWT",
+		ExpireInHours:           168,
+		RefreshExpireInHours:    168,
+		RegistrationAccessToken: util.GenerateClientSecret(),
+	}
+
+	tokenError := applyClientMetadata(application, metadata)
+	if tokenError != nil {
+		return nil, tokenError
+	}
+
+	AddApplication(application)
+	return getClientRegistrationResponse(application, metadata, host), nil
+}
+
+func GetRegisteredClient(clientId string, registrationAccessToken string, host string) (*ClientRegistrationResponse, *TokenError) {
+	application := getRegisteredApplication(clientId, registrationAccessToken)
+	if application == nil {
+		return nil, &TokenError{Error: "invalid_token"}
+	}
+
+	return getClientRegistrationResponse(application, getClientMetadata(application), host), nil
+}
+
+// UpdateRegisteredClient replaces the metadata of the client, the omitted fields are reset to their defaults.
+func UpdateRegisteredClient(clientId string, registrationAccessToken string, metadata *ClientMetadata, host string) (*ClientRegistrationResponse, *TokenError) {
+	application := getRegisteredApplication(clientId, registrationAccessToken)
+	if application == nil {
+		return nil, &TokenError{Error: "invalid_token"}
+	}
+
+	tokenError := applyClientMetadata(application, metadata)
+	if tokenError != nil {
+		return nil, tokenError
+	}
+
+	_, err := adapter.Engine.ID(core.PK{application.Owner, application.Name}).AllCols().Update(application)
+	if err != nil {
+		panic(err)
+	}
+
+	return getClientRegistrationResponse(application, metadata, host), nil
+}
+
+func DeleteRegisteredClient(clientId string, registrationAccessToken string) *TokenError {
+	application := getRegisteredApplication(clientId, registrationAccessToken)
+	if application == nil {
+		return &TokenError{Error: "invalid_token"}
+	}
+
+	DeleteApplication(application)
+	return nil
+}
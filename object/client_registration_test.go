@@ -0,0 +1,110 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyClientMetadataAuthMethod(t *testing.T) {
+	tests := []struct {
+		method   string
+		stored   string
+		isPublic bool
+	}{
+		{"", ClientSecretBasic, false},
+		{ClientSecretBasic, ClientSecretBasic, false},
+		{ClientSecretPost, ClientSecretPost, false},
+		{ClientSecretJwt, ClientSecretJwt, false},
+		{ClientAuthMethodNone, ClientAuthMethodNone, true},
+	}
+	for _, test := range tests {
+		application := &Application{Name: "app-registered"}
+		metadata := &ClientMetadata{RedirectUris: []string{"https://client.example.com/callback"}, TokenEndpointAuthMethod: test.method}
+		if tokenError := applyClientMetadata(application, metadata); tokenError != nil {
+			t.Fatalf("applyClientMetadata(%s) = %v", test.method, tokenError)
+		}
+		if application.TokenEndpointAuthMethod != test.stored || application.IsPublicClient() != test.isPublic {
+			t.Errorf("applyClientMetadata(%s) stores %s", test.method, application.TokenEndpointAuthMethod)
+		}
+
+		// the client reads back the method it has registered, per rfc 7592
+		if readBack := getClientMetadata(application).TokenEndpointAuthMethod; readBack != test.stored {
+			t.Errorf("the client registered with %s reads back %s", test.method, readBack)
+		}
+	}
+}
+
+func TestApplyClientMetadataPrivateKeyJwt(t *testing.T) {
+	application := &Application{Name: "app-registered"}
+	metadata := &ClientMetadata{RedirectUris: []string{"https://client.example.com/callback"}, TokenEndpointAuthMethod: PrivateKeyJwt}
+	if tokenError := applyClientMetadata(application, metadata); tokenError == nil {
+		t.Errorf("private_key_jwt is accepted without a jwks")
+	}
+
+	metadata.Jwks = json.RawMessage(`{"keys":[]}`)
+	metadata.JwksUri = "https://client.example.com/jwks"
+	if tokenError := applyClientMetadata(application, metadata); tokenError == nil {
+		t.Errorf("private_key_jwt is accepted with a jwks_uri")
+	}
+
+	metadata.JwksUri = ""
+	if tokenError := applyClientMetadata(application, metadata); tokenError != nil {
+		t.Fatalf("applyClientMetadata() = %v", tokenError)
+	}
+	if application.TokenEndpointAuthMethod != PrivateKeyJwt || !application.RequiresClientAssertion() || application.ClientJwks != `{"keys":[]}` {
+		t.Errorf("the application is registered as %#v", application)
+	}
+}
+
+func TestApplyClientMetadataInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *ClientMetadata
+		error    string
+	}{
+		{"no redirect URI", &ClientMetadata{}, "invalid_redirect_uri"},
+		{"relative redirect URI", &ClientMetadata{RedirectUris: []string{"/callback"}}, "invalid_redirect_uri"},
+		{"redirect URI with fragment", &ClientMetadata{RedirectUris: []string{"https://client.example.com/callback#x"}}, "invalid_redirect_uri"},
+		{"wildcard redirect URI", &ClientMetadata{RedirectUris: []string{"https://*.example.com/callback"}}, "invalid_redirect_uri"},
+		{"regex redirect URI", &ClientMetadata{RedirectUris: []string{"regex:^https://.*$"}}, "invalid_redirect_uri"},
+		{"unknown grant type", &ClientMetadata{RedirectUris: []string{"https://client.example.com/callback"}, GrantTypes: []string{"magic"}}, "invalid_client_metadata"},
+		{"unknown auth method", &ClientMetadata{RedirectUris: []string{"https://client.example.com/callback"}, TokenEndpointAuthMethod: "tls_client_auth"}, "invalid_client_metadata"},
+		{"invalid logo URI", &ClientMetadata{RedirectUris: []string{"https://client.example.com/callback"}, LogoUri: "logo.png"}, "invalid_client_metadata"},
+	}
+	for _, test := range tests {
+		tokenError := applyClientMetadata(&Application{Name: "app-registered"}, test.metadata)
+		if tokenError == nil || tokenError.Error != test.error {
+			t.Errorf("%s: applyClientMetadata() = %v, want %s", test.name, tokenError, test.error)
+		}
+	}
+}
+
+func TestApplyClientMetadataGrantTypes(t *testing.T) {
+	application := &Application{Name: "app-registered"}
+	metadata := &ClientMetadata{GrantTypes: []string{"client_credentials", "refresh_token"}}
+	if tokenError := applyClientMetadata(application, metadata); tokenError != nil {
+		t.Fatalf("applyClientMetadata() = %v", tokenError)
+	}
+
+	if !reflect.DeepEqual(application.GrantTypes, []string{"client_credentials", "refresh_token"}) {
+		t.Errorf("the application has the grant types %v", application.GrantTypes)
+	}
+	if grantTypes := getClientMetadata(application).GrantTypes; !reflect.DeepEqual(grantTypes, []string{"client_credentials", "refresh_token"}) {
+		t.Errorf("the client reads back the grant types %v", grantTypes)
+	}
+}
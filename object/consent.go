@@ -0,0 +1,227 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+	"xorm.io/core"
+)
+
+// ConsentRequired is the error of the authorization requests that the user has to consent to first,
+// per OpenID Connect Core section 3.1.2.6
+const ConsentRequired = "consent_required"
+
+var errConsentRequired = fmt.Errorf("error: %s: the user hasn't consented to the request", ConsentRequired)
+
+// IsConsentRequired tells whether the error message of an authorization request is the consent_required error
+func IsConsentRequired(msg string) bool {
+	return msg == errConsentRequired.Error()
+}
+
+// Consent is what a user has granted an application: the scopes and the API resources that the user
+// has consented to. The authorization requests within the consent don't ask the user again.
+type Consent struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+	UpdatedTime string `xorm:"varchar(100)" json:"updatedTime"`
+
+	User        string   `xorm:"varchar(100) index" json:"user"`
+	Application string   `xorm:"varchar(100) index" json:"application"`
+	Scopes      []string `xorm:"mediumtext" json:"scopes"`
+	Resources   []string `xorm:"mediumtext" json:"resources"`
+}
+
+// GetUserConsents returns the consents of the user, whose owner is the organization of the user
+func GetUserConsents(owner string, user string) []*Consent {
+	consents := []*Consent{}
+	err := adapter.Engine.Desc("created_time").Find(&consents, &Consent{Owner: owner, User: user})
+	if err != nil {
+		panic(err)
+	}
+
+	return consents
+}
+
+func getConsent(owner string, name string) *Consent {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	consent := Consent{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&consent)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &consent
+	} else {
+		return nil
+	}
+}
+
+func GetConsent(id string) *Consent {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getConsent(owner, name)
+}
+
+func getUserApplicationConsent(user *User, application *Application) *Consent {
+	consent := Consent{Owner: user.Owner, User: user.Name, Application: application.GetId()}
+	existed, err := adapter.Engine.Get(&consent)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &consent
+	} else {
+		return nil
+	}
+}
+
+func (consent *Consent) GetId() string {
+	return fmt.Sprintf("%s/%s", consent.Owner, consent.Name)
+}
+
+// covers tells whether the consent covers the scope and the resources
+func (consent *Consent) covers(scope string, resources []string) bool {
+	granted := strings.Join(consent.Scopes, " ")
+	for _, s := range strings.Fields(scope) {
+		if !hasScope(granted, s) {
+			return false
+		}
+	}
+
+	granted = strings.Join(consent.Resources, " ")
+	for _, resource := range resources {
+		if !hasScope(granted, resource) {
+			return false
+		}
+	}
+	return true
+}
+
+// addToSet adds the values that the set doesn't have yet to it
+func addToSet(set []string, values []string) []string {
+	for _, value := range values {
+		if !hasScope(strings.Join(set, " "), value) {
+			set = append(set, value)
+		}
+	}
+	return set
+}
+
+// checkConsent returns the consent_required error if the user has to consent to the request first: the request
+// isn't within what the user has granted the application, or it asks the user again with "prompt=consent".
+// The first-party applications that skip the consent never ask the user, and the user who has just consented
+// on the consent page satisfies "prompt=consent", but the request still has to be within the stored consent.
+func checkConsent(application *Application, user *User, request *AuthorizationRequest) error {
+	if application.SkipConsent || request.FirstParty {
+		return nil
+	}
+	if hasScope(request.Prompt, "consent") && !request.ConsentGranted {
+		return errConsentRequired
+	}
+
+	consent := getUserApplicationConsent(user, application)
+	if consent == nil || !consent.covers(request.Scope, request.Resources) {
+		return errConsentRequired
+	}
+	return nil
+}
+
+// GrantConsent records that the user consents to the scope and the resources of the authorization request
+// for the application, on top of what the user has granted it before
+func GrantConsent(userId string, clientId string, requestUri string, request *AuthorizationRequest) error {
+	user := GetUser(userId)
+	if user == nil {
+		return fmt.Errorf("the user: %s doesn't exist", userId)
+	}
+
+	if requestUri != "" {
+		_, params, err := getPushedAuthRequestParameters(clientId, requestUri)
+		if err != nil {
+			return err
+		}
+		request.Scope = params.Get("scope")
+		request.Resources = params["resource"]
+	}
+
+	application := GetApplicationByClientId(clientId)
+	if application == nil {
+		return errors.New("error: invalid client_id")
+	}
+	resource, err := checkResources(application, request.Resources, request.Scope)
+	if err != nil {
+		return err
+	}
+
+	consent := getUserApplicationConsent(user, application)
+	if consent == nil {
+		consent = &Consent{
+			Owner:       user.Owner,
+			Name:        util.GenerateId(),
+			CreatedTime: util.GetCurrentTime(),
+			UpdatedTime: util.GetCurrentTime(),
+			User:        user.Name,
+			Application: application.GetId(),
+			Scopes:      addToSet([]string{}, strings.Fields(request.Scope)),
+			Resources:   strings.Fields(resource),
+		}
+		_, err = adapter.Engine.Insert(consent)
+		return err
+	}
+
+	consent.UpdatedTime = util.GetCurrentTime()
+	consent.Scopes = addToSet(consent.Scopes, strings.Fields(request.Scope))
+	consent.Resources = addToSet(consent.Resources, strings.Fields(resource))
+	_, err = adapter.Engine.ID(core.PK{consent.Owner, consent.Name}).Cols("updated_time", "scopes", "resources").Update(consent)
+	return err
+}
+
+// RevokeConsent removes the consent, along with the tokens that the user has granted the application,
+// so that the application has to ask the user for consent again
+func RevokeConsent(consent *Consent) bool {
+	session := adapter.Engine.NewSession()
+	defer session.Close()
+
+	err := session.Begin()
+	if err != nil {
+		panic(err)
+	}
+
+	affected, err := session.ID(core.PK{consent.Owner, consent.Name}).Delete(&Consent{})
+	if err != nil {
+		panic(err)
+	}
+
+	owner, name := util.GetOwnerAndNameFromIdNoCheck(consent.Application)
+	_, err = session.Where("owner = ? and application = ? and organization = ? and user = ?", owner, name, consent.Owner, consent.User).Delete(&Token{})
+	if err != nil {
+		panic(err)
+	}
+
+	err = session.Commit()
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestConsentCovers(t *testing.T) {
+	consent := &Consent{Scopes: []string{"openid", "profile"}, Resources: []string{"https://api.example.com"}}
+
+	tests := []struct {
+		scope     string
+		resources []string
+		covered   bool
+	}{
+		{"openid", nil, true},
+		{"profile openid", []string{"https://api.example.com"}, true},
+		{"", nil, true},
+		{"openid email", nil, false},
+		{"openid", []string{"https://other.example.com"}, false},
+	}
+	for _, test := range tests {
+		if consent.covers(test.scope, test.resources) != test.covered {
+			t.Errorf("covers(%s, %v) = %v", test.scope, test.resources, !test.covered)
+		}
+	}
+}
+
+func TestCheckConsentWithoutPrompt(t *testing.T) {
+	user := &User{Owner: "built-in", Name: "alice"}
+
+	// the first-party applications don't ask the user
+	if err := checkConsent(&Application{SkipConsent: true}, user, &AuthorizationRequest{Scope: "openid", Prompt: "consent"}); err != nil {
+		t.Errorf("the application that skips the consent asks the user: %v", err)
+	}
+	if err := checkConsent(&Application{}, user, &AuthorizationRequest{Scope: "openid", Prompt: "consent", FirstParty: true}); err != nil {
+		t.Errorf("the first-party request asks the user: %v", err)
+	}
+
+	err := checkConsent(&Application{}, user, &AuthorizationRequest{Scope: "openid", Prompt: "login consent"})
+	if err == nil || !IsConsentRequired(err.Error()) {
+		t.Errorf("prompt=consent doesn't ask the user: %v", err)
+	}
+}
+
+func TestCheckConsentGranted(t *testing.T) {
+	application := addTestApplication(t, &Application{})
+	user := getTestUser(t)
+
+	err := GrantConsent(user.GetId(), application.ClientId, "", &AuthorizationRequest{Scope: "openid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		RevokeConsent(getUserApplicationConsent(user, application))
+	})
+
+	// the user who has just consented satisfies prompt=consent
+	if err = checkConsent(application, user, &AuthorizationRequest{Scope: "openid", Prompt: "consent", ConsentGranted: true}); err != nil {
+		t.Errorf("the request just consented to asks the user: %v", err)
+	}
+	if err = checkConsent(application, user, &AuthorizationRequest{Scope: "openid", Prompt: "consent"}); err == nil || !IsConsentRequired(err.Error()) {
+		t.Errorf("prompt=consent doesn't ask the user: %v", err)
+	}
+
+	// but the scopes that the user hasn't consented to still need the consent
+	err = checkConsent(application, user, &AuthorizationRequest{Scope: "openid email", ConsentGranted: true})
+	if err == nil || !IsConsentRequired(err.Error()) {
+		t.Errorf("the scope beyond the consent doesn't ask the user: %v", err)
+	}
+}
+
+func TestAddToSet(t *testing.T) {
+	set := addToSet([]string{"openid"}, []string{"profile", "openid", "email", "profile"})
+	if len(set) != 3 || set[0] != "openid" || set[1] != "profile" || set[2] != "email" {
+		t.Errorf("addToSet() = %v", set)
+	}
+}
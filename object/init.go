@@ -102,6 +102,7 @@ func initBuiltInApplication() {
 		Cert:           "cert-built-in",
 		EnablePassword: true,
 		EnableSignUp:   true,
+		SkipConsent:    true,
 		Providers:      []*ProviderItem{},
 		SignupItems: []*SignupItem{
 			{Name: "ID", Visible: false, Required: true, Prompted: false, Rule: "Random"},
@@ -113,8 +114,9 @@ func initBuiltInApplication() {
 			{Name: "Phone", Visible: true, Required: true, Prompted: false, Rule: "None"},
 			{Name: "Agreement", Visible: true, Required: true, Prompted: false, Rule: "None"},
 		},
-		RedirectUris:  []string{},
-		ExpireInHours: 168,
+		RedirectUris:           []string{},
+		PostLogoutRedirectUris: []string{},
+		ExpireInHours:          168,
 	}
 	AddApplication(application)
 }
@@ -0,0 +1,227 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/astaxie/beego/logs"
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+)
+
+// The janitor removes the rows that have expired from the tables that would otherwise grow forever.
+// It is configured in app.conf:
+//   - janitorInterval: the minutes between two runs, 0 disables the janitor
+//   - janitorBatchSize: the number of rows removed in a transaction
+//   - janitorArchive: if true, the removed rows are kept in the archived_record table
+//
+// Every row is removed by its primary key, and only the instance whose delete affected the row archives it,
+// so several instances can run the janitor at the same time.
+const (
+	defaultJanitorInterval  = 60
+	defaultJanitorBatchSize = 500
+
+	// the rows are kept for a while after they expire, which covers the clock skew between the instances,
+	// and the time zone offsets of the created times that are compared as strings
+	janitorGracePeriod = 24 * time.Hour
+)
+
+// ArchivedRecord is a row removed by the janitor, in the JSON of its columns
+type ArchivedRecord struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Table    string `xorm:"varchar(100) index" json:"table"`
+	RecordId string `xorm:"varchar(200)" json:"recordId"`
+	Data     string `xorm:"mediumtext" json:"data"`
+}
+
+type janitorRule struct {
+	table     string
+	bean      interface{}
+	condition string
+	args      []interface{}
+}
+
+func getJanitorConfigInt(key string, defaultValue int) int {
+	value, err := conf.GetConfigInt64(key)
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return int(value)
+}
+
+func getJanitorRules() ([]*janitorRule, error) {
+	now := time.Now()
+	cutoff := now.Add(-janitorGracePeriod)
+
+	rules := []*janitorRule{
+		// the authorization codes that have never been exchanged for the tokens
+		{"token", &Token{}, "code_is_used = ? and code_expire_in > 0 and code_expire_in < ?", []interface{}{false, cutoff.Unix()}},
+	}
+
+	// a token row is removed once both its access token and its refresh token have expired,
+	// the rotated-out refresh tokens are kept until then to detect their reuse
+	tokenTable := adapter.Engine.TableName(&Token{})
+	appSessionTable := adapter.Engine.TableName(&AppSession{})
+	applications := []*Application{}
+	err := adapter.Engine.Find(&applications)
+	if err != nil {
+		return nil, err
+	}
+	for _, application := range applications {
+		lifetime := time.Duration(application.ExpireInHours) * time.Hour
+		if application.RefreshExpireInHours > application.ExpireInHours {
+			lifetime = time.Duration(application.RefreshExpireInHours) * time.Hour
+		}
+		createdTime := cutoff.Add(-lifetime).Format(time.RFC3339)
+		rules = append(rules,
+			&janitorRule{"token", &Token{}, "owner = ? and application = ? and created_time < ?", []interface{}{application.Owner, application.Name, createdTime}},
+			// an app session ends when the user has no tokens of the application left
+			&janitorRule{"app_session", &AppSession{}, fmt.Sprintf("owner = ? and application = ? and created_time < ? and not exists (select 1 from %[1]s where %[1]s.owner = %[2]s.owner and %[1]s.application = %[2]s.application and %[1]s.organization = %[2]s.organization and %[1]s.user = %[2]s.user)", tokenTable, appSessionTable), []interface{}{application.Owner, application.Name, createdTime}},
+		)
+	}
+
+	verificationCodeTimeout, err := conf.GetConfigInt64("verificationCodeTimeout")
+	if err != nil || verificationCodeTimeout <= 0 {
+		verificationCodeTimeout = 10
+	}
+	rules = append(rules,
+		&janitorRule{"verification_record", &VerificationRecord{}, "time < ?", []interface{}{cutoff.Unix() - verificationCodeTimeout*60}},
+		&janitorRule{"jti_record", &JtiRecord{}, "expire_in < ?", []interface{}{cutoff.Unix()}},
+		&janitorRule{"device_auth", &DeviceAuth{}, "expire_in < ?", []interface{}{cutoff.Unix()}},
+		&janitorRule{"pushed_auth_request", &PushedAuthRequest{}, "expire_in < ?", []interface{}{cutoff.Unix()}},
+		&janitorRule{"ciba_request", &CibaRequest{}, "expire_in < ?", []interface{}{cutoff.Unix()}},
+	)
+	return rules, nil
+}
+
+// removeRecords deletes the records in a transaction, and archives the ones deleted by this instance
+func removeRecords(rule *janitorRule, records []map[string]string, archive bool) (int, error) {
+	session := adapter.Engine.NewSession()
+	defer session.Close()
+
+	err := session.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, record := range records {
+		affected, err := session.Where("owner = ? and name = ?", record["owner"], record["name"]).Delete(rule.bean)
+		if err != nil {
+			_ = session.Rollback()
+			return 0, err
+		}
+		if affected == 0 {
+			// removed by another instance
+			continue
+		}
+		removed++
+
+		if archive {
+			data, err := json.Marshal(record)
+			if err != nil {
+				_ = session.Rollback()
+				return 0, err
+			}
+
+			_, err = session.Insert(&ArchivedRecord{
+				Owner:       "admin",
+				Name:        util.GenerateId(),
+				CreatedTime: util.GetCurrentTime(),
+				Table:       rule.table,
+				RecordId:    fmt.Sprintf("%s/%s", record["owner"], record["name"]),
+				Data:        string(data),
+			})
+			if err != nil {
+				_ = session.Rollback()
+				return 0, err
+			}
+		}
+	}
+
+	return removed, session.Commit()
+}
+
+func runJanitorRule(rule *janitorRule, batchSize int, archive bool) (int, error) {
+	removed := 0
+	for {
+		records, err := adapter.Engine.Table(rule.bean).Where(rule.condition, rule.args...).Limit(batchSize).QueryString()
+		if err != nil {
+			return removed, err
+		}
+		if len(records) == 0 {
+			return removed, nil
+		}
+
+		count, err := removeRecords(rule, records, archive)
+		removed += count
+		if err != nil {
+			return removed, err
+		}
+		// another instance is removing the same rows, leave the rest to it
+		if count == 0 || len(records) < batchSize {
+			return removed, nil
+		}
+	}
+}
+
+// RunJanitor removes the expired rows once, and returns the number of rows removed from each table
+func RunJanitor() (map[string]int, error) {
+	batchSize := getJanitorConfigInt("janitorBatchSize", defaultJanitorBatchSize)
+	if batchSize == 0 {
+		batchSize = defaultJanitorBatchSize
+	}
+	archive, _ := conf.GetConfigBool("janitorArchive")
+
+	rules, err := getJanitorRules()
+	if err != nil {
+		return nil, err
+	}
+
+	report := map[string]int{}
+	for _, rule := range rules {
+		removed, err := runJanitorRule(rule, batchSize, archive)
+		report[rule.table] += removed
+		if err != nil {
+			return report, fmt.Errorf("failed to clean up the table: %s, error: %s", rule.table, err.Error())
+		}
+	}
+	return report, nil
+}
+
+func RunJanitorJob() {
+	interval := getJanitorConfigInt("janitorInterval", defaultJanitorInterval)
+	if interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		report, err := RunJanitor()
+		if err != nil {
+			logs.Error("janitor: %s", err.Error())
+		}
+		logs.Info("janitor: removed the expired rows: %v", report)
+		<-ticker.C
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func isRecordExisted(bean interface{}, owner string, name string) bool {
+	existed, err := adapter.Engine.Table(bean).Where("owner = ? and name = ?", owner, name).Exist()
+	if err != nil {
+		panic(err)
+	}
+	return existed
+}
+
+func setTestRecordColumn(bean interface{}, owner string, name string, column string, value interface{}) {
+	_, err := adapter.Engine.Table(bean).Where("owner = ? and name = ?", owner, name).Update(map[string]interface{}{column: value})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRunJanitor(t *testing.T) {
+	application := addTestApplication(t, &Application{})
+	user := getTestUser(t)
+	expiredTime := time.Now().Add(-janitorGracePeriod - 2*time.Hour)
+
+	expired := addTestGrant(t, application, user)
+	setTestRecordColumn(&Token{}, expired.Owner, expired.Name, "created_time", expiredTime.Format(time.RFC3339))
+	unusedCode := addTestGrant(t, application, user)
+	setTestRecordColumn(&Token{}, unusedCode.Owner, unusedCode.Name, "code_expire_in", expiredTime.Unix())
+	fresh := addTestGrant(t, application, user)
+
+	appSession := addAppSession(application, user)
+	defer deleteAppSession(appSession)
+	setTestRecordColumn(&AppSession{}, appSession.Owner, appSession.Name, "created_time", expiredTime.Format(time.RFC3339))
+
+	report, err := RunJanitor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report["token"] < 2 {
+		t.Errorf("the janitor has removed %d tokens", report["token"])
+	}
+	if isRecordExisted(&Token{}, expired.Owner, expired.Name) || isRecordExisted(&Token{}, unusedCode.Owner, unusedCode.Name) {
+		t.Errorf("the expired token or the expired code is kept")
+	}
+	if !isRecordExisted(&Token{}, fresh.Owner, fresh.Name) {
+		t.Errorf("the fresh token is removed")
+	}
+
+	// the app session ends with the last token of the user for the application
+	if !isRecordExisted(&AppSession{}, appSession.Owner, appSession.Name) {
+		t.Errorf("the app session with a fresh token is removed")
+	}
+	DeleteToken(fresh)
+	if _, err = RunJanitor(); err != nil {
+		t.Fatal(err)
+	}
+	if isRecordExisted(&AppSession{}, appSession.Owner, appSession.Name) {
+		t.Errorf("the app session without tokens is kept")
+	}
+}
+
+func TestRemoveRecordsOnce(t *testing.T) {
+	application := addTestApplication(t, &Application{})
+	token := addTestGrant(t, application, getTestUser(t))
+	recordId := fmt.Sprintf("%s/%s", token.Owner, token.Name)
+	t.Cleanup(func() {
+		_, err := adapter.Engine.Where("record_id = ?", recordId).Delete(&ArchivedRecord{})
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	// the instances that remove the same row race, only the one whose delete affects the row archives it
+	rule := &janitorRule{table: "token", bean: &Token{}}
+	records := []map[string]string{{"owner": token.Owner, "name": token.Name}}
+	for i, want := range []int{1, 0} {
+		removed, err := removeRecords(rule, records, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if removed != want {
+			t.Errorf("removeRecords() #%d = %d, want %d", i, removed, want)
+		}
+	}
+
+	count, err := adapter.Engine.Where("record_id = ?", recordId).Count(&ArchivedRecord{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || isRecordExisted(&Token{}, token.Owner, token.Name) {
+		t.Errorf("the token is archived %d times", count)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+// JtiRecord remembers the jti of a one-time JWT (e.g. a client assertion) until the JWT expires,
+// so that a replayed JWT can be rejected.
+type JtiRecord struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Category string `xorm:"varchar(100)" json:"category"`
+	ExpireIn int64  `xorm:"index" json:"expireIn"`
+}
+
+func getJtiRecordName(category string, jti string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%s/%s", category, jti))))
+}
+
+func getJtiRecord(owner string, category string, jti string) *JtiRecord {
+	jtiRecord := JtiRecord{Owner: owner, Name: getJtiRecordName(category, jti)}
+	existed, err := adapter.Engine.Get(&jtiRecord)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &jtiRecord
+	}
+
+	return nil
+}
+
+func addJtiRecord(owner string, category string, jti string, expireIn int64) bool {
+	jtiRecord := JtiRecord{
+		Owner:       owner,
+		Name:        getJtiRecordName(category, jti),
+		CreatedTime: util.GetCurrentTime(),
+		Category:    category,
+		ExpireIn:    expireIn,
+	}
+
+	// if another request with the same jti wins the race, the insert fails on the primary key
+	affected, err := adapter.Engine.Insert(&jtiRecord)
+	return err == nil && affected != 0
+}
+
+// useJti records the jti, it returns false if the jti has already been used in the category.
+func useJti(owner string, category string, jti string, expireIn int64) bool {
+	if getJtiRecord(owner, category, jti) != nil {
+		return false
+	}
+
+	return addJtiRecord(owner, category, jti, expireIn)
+}
@@ -0,0 +1,312 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// OpenID Connect Back-Channel Logout and Front-Channel Logout
+// https://openid.net/specs/openid-connect-backchannel-1_0.html
+// https://openid.net/specs/openid-connect-frontchannel-1_0.html
+const (
+	backchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+	logoutTokenExpireInSeconds = 120
+	backchannelLogoutTimeout   = 10 * time.Second
+)
+
+// AppSession records that a user has signed in to an application through Casdoor,
+// its name is the sid that the tokens of the application carry.
+type AppSession struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Application  string `xorm:"varchar(100) index" json:"application"`
+	Organization string `xorm:"varchar(100) index" json:"organization"`
+	User         string `xorm:"varchar(100) index" json:"user"`
+}
+
+type LogoutClaims struct {
+	Events map[string]interface{} `json:"events"`
+	Sid    string                 `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func getAppSession(application *Application, user *User) *AppSession {
+	appSession := AppSession{Owner: application.Owner, Application: application.Name, Organization: user.Owner, User: user.Name}
+	existed, err := adapter.Engine.Get(&appSession)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &appSession
+	}
+
+	return nil
+}
+
+func getAppSessionByName(owner string, name string) *AppSession {
+	appSession := AppSession{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&appSession)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &appSession
+	}
+
+	return nil
+}
+
+func getAppSessionsByUser(organization string, user string) []*AppSession {
+	appSessions := []*AppSession{}
+	err := adapter.Engine.Find(&appSessions, &AppSession{Organization: organization, User: user})
+	if err != nil {
+		panic(err)
+	}
+
+	return appSessions
+}
+
+// addAppSession records the session of the user in the application, if there isn't one yet.
+func addAppSession(application *Application, user *User) *AppSession {
+	appSession := getAppSession(application, user)
+	if appSession != nil {
+		return appSession
+	}
+
+	appSession = &AppSession{
+		Owner:        application.Owner,
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Application:  application.Name,
+		Organization: user.Owner,
+		User:         user.Name,
+	}
+	_, err := adapter.Engine.Insert(appSession)
+	if err != nil {
+		panic(err)
+	}
+
+	return appSession
+}
+
+func deleteAppSession(appSession *AppSession) bool {
+	affected, err := adapter.Engine.Delete(&AppSession{Owner: appSession.Owner, Name: appSession.Name})
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func getLogoutToken(application *Application, appSession *AppSession, host string) (string, error) {
+	user := getUser(appSession.Organization, appSession.User)
+	if user == nil {
+		return "", fmt.Errorf("the user: %s/%s doesn't exist", appSession.Organization, appSession.User)
+	}
+
+	nowTime := time.Now()
+	claims := LogoutClaims{
+		Events: map[string]interface{}{backchannelLogoutEvent: map[string]interface{}{}},
+		Sid:    appSession.Name,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    getJwtIssuer(application, host),
+			Subject:   user.Id,
+			Audience:  []string{application.ClientId},
+			ExpiresAt: jwt.NewNumericDate(nowTime.Add(time.Second * logoutTokenExpireInSeconds)),
+			IssuedAt:  jwt.NewNumericDate(nowTime),
+			ID:        util.GenerateId(),
+		},
+	}
+
+	return signJwtClaims(application, claims, "logout+jwt")
+}
+
+func sendBackchannelLogout(application *Application, logoutToken string) error {
+	client := &http.Client{Timeout: backchannelLogoutTimeout}
+	resp, err := client.PostForm(application.BackchannelLogoutUri, url.Values{"logout_token": {logoutToken}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the backchannel_logout_uri responded with status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func getFrontchannelLogoutUri(application *Application, appSession *AppSession, host string) string {
+	if !application.FrontchannelLogoutSessionRequired {
+		return application.FrontchannelLogoutUri
+	}
+
+	separator := "?"
+	if strings.Contains(application.FrontchannelLogoutUri, "?") {
+		separator = "&"
+	}
+	params := url.Values{"iss": {getJwtIssuer(application, host)}, "sid": {appSession.Name}}
+	return fmt.Sprintf("%s%s%s", application.FrontchannelLogoutUri, separator, params.Encode())
+}
+
+// GetFrontchannelLogoutUris returns the front-channel logout URIs of the applications that the user has signed in to.
+func GetFrontchannelLogoutUris(userId string, host string) []string {
+	organization, name := util.GetOwnerAndNameFromIdNoCheck(userId)
+
+	uris := []string{}
+	for _, appSession := range getAppSessionsByUser(organization, name) {
+		application := getApplication(appSession.Owner, appSession.Application)
+		if application != nil && application.FrontchannelLogoutUri != "" {
+			uris = append(uris, getFrontchannelLogoutUri(application, appSession, host))
+		}
+	}
+	return uris
+}
+
+// LogoutAppSessions ends the sessions of the user in all the applications. The applications with
+// a backchannel_logout_uri are sent a logout token, and the front-channel logout URIs are returned
+// for the browser of the user to load.
+func LogoutAppSessions(userId string, host string) []string {
+	if userId == "" {
+		return []string{}
+	}
+
+	uris := GetFrontchannelLogoutUris(userId, host)
+
+	organization, name := util.GetOwnerAndNameFromIdNoCheck(userId)
+	for _, appSession := range getAppSessionsByUser(organization, name) {
+		deleteAppSession(appSession)
+
+		application := getApplication(appSession.Owner, appSession.Application)
+		if application == nil || application.BackchannelLogoutUri == "" {
+			continue
+		}
+
+		logoutToken, err := getLogoutToken(application, appSession, host)
+		if err != nil {
+			logs.Error("failed to generate the logout token for application: %s, error: %s", application.Name, err.Error())
+			continue
+		}
+
+		util.SafeGoroutine(func() {
+			err := sendBackchannelLogout(application, logoutToken)
+			if err != nil {
+				logs.Error("failed to send the back-channel logout to application: %s, error: %s", application.Name, err.Error())
+			}
+		})
+	}
+
+	return uris
+}
+
+// IsPostLogoutRedirectUriValid checks the post_logout_redirect_uri of an end session request,
+// it must exactly match one of the post logout redirect URIs of the application.
+func (application *Application) IsPostLogoutRedirectUriValid(postLogoutRedirectUri string) bool {
+	for _, uri := range application.PostLogoutRedirectUris {
+		if uri == postLogoutRedirectUri {
+			return true
+		}
+	}
+	return false
+}
+
+// IdTokenHint is what the id_token_hint of an end session request stands for: the application that it was
+// issued to, the user, and the sid of the session of the user in the application
+type IdTokenHint struct {
+	Application *Application
+	UserId      string
+	Sid         string
+}
+
+// ParseIdTokenHint returns what the id_token_hint of an end session request stands for.
+// The ID token may have expired, but it must be signed by the application's cert.
+func ParseIdTokenHint(idTokenHint string) (*IdTokenHint, error) {
+	unverifiedClaims := IdTokenClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(idTokenHint, &unverifiedClaims)
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid id_token_hint: %s", err.Error())
+	}
+	if len(unverifiedClaims.Audience) == 0 {
+		return nil, errors.New("error: invalid id_token_hint: the aud is empty")
+	}
+
+	application := GetApplicationByClientId(unverifiedClaims.Audience[0])
+	if application == nil {
+		return nil, fmt.Errorf("error: invalid id_token_hint: the application with client_id: %s doesn't exist", unverifiedClaims.Audience[0])
+	}
+
+	claims, err := ParseIdToken(idTokenHint, getCertByApplication(application))
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid id_token_hint: %s", err.Error())
+	}
+
+	hint := &IdTokenHint{Application: application, Sid: claims.Sid}
+	user := getUserById(application.Organization, claims.Subject)
+	if user != nil {
+		hint.UserId = user.GetId()
+	}
+	return hint, nil
+}
+
+// IsOfUser tells whether the id_token_hint was issued for the user. Its sid must not name the session
+// of another user, the sid of a session that has already ended doesn't count.
+func (hint *IdTokenHint) IsOfUser(userId string) bool {
+	if hint.UserId == "" || hint.UserId != userId {
+		return false
+	}
+	if hint.Sid == "" {
+		return true
+	}
+
+	appSession := getAppSessionByName(hint.Application.Owner, hint.Sid)
+	return appSession == nil || fmt.Sprintf("%s/%s", appSession.Organization, appSession.User) == userId
+}
+
+// EndAppSession ends the session that the id_token_hint names, along with the tokens issued in it. The other
+// applications of the user are left signed in, as the hint alone doesn't prove that the user is logging out.
+// The hint may have expired, so it ends nothing unless it names a session of its user that still exists.
+func EndAppSession(hint *IdTokenHint) int {
+	if hint.UserId == "" || hint.Sid == "" {
+		return 0
+	}
+
+	application := hint.Application
+	appSession := getAppSessionByName(application.Owner, hint.Sid)
+	if appSession == nil || appSession.Application != application.Name || fmt.Sprintf("%s/%s", appSession.Organization, appSession.User) != hint.UserId {
+		return 0
+	}
+	deleteAppSession(appSession)
+
+	affected, err := adapter.Engine.Where("owner = ? and application = ? and sid = ?", application.Owner, application.Name, appSession.Name).Delete(&Token{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(affected)
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestIdTokenHintIsOfUser(t *testing.T) {
+	hint := &IdTokenHint{Application: &Application{Owner: "admin", Name: "app-example"}, UserId: "example/alice"}
+	if !hint.IsOfUser("example/alice") {
+		t.Errorf("the hint isn't of its user")
+	}
+	if hint.IsOfUser("example/bob") {
+		t.Errorf("the hint is of another user")
+	}
+
+	// the hint of a user who no longer exists isn't of anybody
+	hint.UserId = ""
+	if hint.IsOfUser("") || hint.IsOfUser("example/alice") {
+		t.Errorf("the hint without a user is of a user")
+	}
+}
+
+func TestGetLogoutToken(t *testing.T) {
+	application := addTestApplication(t, &Application{BackchannelLogoutUri: "https://client.example.com/logout"})
+	user := getTestUser(t)
+	appSession := addAppSession(application, user)
+	defer deleteAppSession(appSession)
+
+	logoutToken, err := getLogoutToken(application, appSession, testHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := parseJwtClaims(logoutToken, &claims, getCertByApplication(application))
+	if err != nil {
+		t.Fatalf("failed to verify the logout token: %v", err)
+	}
+	if token.Header["typ"] != "logout+jwt" {
+		t.Errorf("the typ of the logout token is %v", token.Header["typ"])
+	}
+
+	// a logout token carries the logout event and the sid, and never a nonce, per the back-channel logout spec section 2.4
+	events, ok := claims["events"].(map[string]interface{})
+	if _, hasEvent := events[backchannelLogoutEvent]; !ok || !hasEvent {
+		t.Errorf("the events of the logout token are %v", claims["events"])
+	}
+	if claims["sid"] != appSession.Name || claims["sub"] != user.Id {
+		t.Errorf("the logout token is for sid: %v, sub: %v", claims["sid"], claims["sub"])
+	}
+	if _, hasNonce := claims["nonce"]; hasNonce {
+		t.Errorf("the logout token has a nonce")
+	}
+	if !claims.VerifyAudience(application.ClientId, true) || claims["jti"] == "" || claims["iat"] == nil || claims["exp"] == nil {
+		t.Errorf("the registered claims of the logout token are %v", claims)
+	}
+}
+
+func TestEndAppSession(t *testing.T) {
+	application := addTestApplication(t, &Application{})
+	otherApplication := addTestApplication(t, &Application{})
+	user := getTestUser(t)
+
+	// the token issued outside of the session, like on another device before the session, isn't the session's
+	outsideGrant := addTestGrant(t, application, user)
+	appSession := addAppSession(application, user)
+	otherAppSession := addAppSession(otherApplication, user)
+	defer deleteAppSession(otherAppSession)
+	grant := addTestGrant(t, application, user)
+	otherGrant := addTestGrant(t, otherApplication, user)
+	if grant.Sid != appSession.Name || outsideGrant.Sid != "" {
+		t.Fatalf("the sids of the tokens are %s, %s", grant.Sid, outsideGrant.Sid)
+	}
+
+	// the hint without a sid names no session
+	if affected := EndAppSession(&IdTokenHint{Application: application, UserId: user.GetId()}); affected != 0 {
+		t.Errorf("EndAppSession() without a sid ends %d tokens", affected)
+	}
+
+	// the hint alone only ends the session that it names
+	hint := &IdTokenHint{Application: application, UserId: user.GetId(), Sid: appSession.Name}
+	if affected := EndAppSession(hint); affected != 1 {
+		t.Errorf("EndAppSession() ends %d tokens", affected)
+	}
+	if getAppSession(application, user) != nil || GetTokenByAccessToken(grant.AccessToken) != nil {
+		t.Errorf("the session of the hint hasn't ended")
+	}
+	if GetTokenByAccessToken(outsideGrant.AccessToken) == nil {
+		t.Errorf("the token outside of the session has ended")
+	}
+	if getAppSession(otherApplication, user) == nil || GetTokenByAccessToken(otherGrant.AccessToken) == nil {
+		t.Errorf("the session of the other application has ended")
+	}
+
+	// the old hint of the ended session ends nothing of the new session
+	newAppSession := addAppSession(application, user)
+	defer deleteAppSession(newAppSession)
+	newGrant := addTestGrant(t, application, user)
+	if affected := EndAppSession(hint); affected != 0 || GetTokenByAccessToken(newGrant.AccessToken) == nil {
+		t.Errorf("the old hint ends %d tokens of the new session", affected)
+	}
+	if !hint.IsOfUser(user.GetId()) {
+		t.Errorf("the hint of the ended session isn't of its user")
+	}
+}
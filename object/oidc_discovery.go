@@ -18,30 +18,73 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/casdoor/casdoor/conf"
 	"gopkg.in/square/go-jose.v2"
 )
 
+// AuthorizationServerMetadata describes the OAuth 2.0 authorization server, per rfc 8414
+type AuthorizationServerMetadata struct {
+	Issuer                                             string   `json:"issuer"`
+	AuthorizationEndpoint                              string   `json:"authorization_endpoint"`
+	TokenEndpoint                                      string   `json:"token_endpoint"`
+	JwksUri                                            string   `json:"jwks_uri"`
+	RegistrationEndpoint                               string   `json:"registration_endpoint"`
+	ScopesSupported                                    []string `json:"scopes_supported"`
+	ResponseTypesSupported                             []string `json:"response_types_supported"`
+	ResponseModesSupported                             []string `json:"response_modes_supported"`
+	GrantTypesSupported                                []string `json:"grant_types_supported"`
+	TokenEndpointAuthMethodsSupported                  []string `json:"token_endpoint_auth_methods_supported"`
+	TokenEndpointAuthSigningAlgValuesSupported         []string `json:"token_endpoint_auth_signing_alg_values_supported"`
+	RevocationEndpoint                                 string   `json:"revocation_endpoint"`
+	RevocationEndpointAuthMethodsSupported             []string `json:"revocation_endpoint_auth_methods_supported"`
+	IntrospectionEndpoint                              string   `json:"introspection_endpoint"`
+	IntrospectionEndpointAuthMethodsSupported          []string `json:"introspection_endpoint_auth_methods_supported"`
+	IntrospectionEndpointAuthSigningAlgValuesSupported []string `json:"introspection_endpoint_auth_signing_alg_values_supported"`
+	CodeChallengeMethodsSupported                      []string `json:"code_challenge_methods_supported"`
+	DeviceAuthorizationEndpoint                        string   `json:"device_authorization_endpoint"`
+	PushedAuthorizationRequestEndpoint                 string   `json:"pushed_authorization_request_endpoint"`
+	DpopSigningAlgValuesSupported                      []string `json:"dpop_signing_alg_values_supported"`
+}
+
+// OidcDiscovery is the OpenID Provider metadata, which extends the authorization server metadata
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
 type OidcDiscovery struct {
-	Issuer                                 string   `json:"issuer"`
-	AuthorizationEndpoint                  string   `json:"authorization_endpoint"`
-	TokenEndpoint                          string   `json:"token_endpoint"`
+	AuthorizationServerMetadata
 	UserinfoEndpoint                       string   `json:"userinfo_endpoint"`
-	JwksUri                                string   `json:"jwks_uri"`
-	IntrospectionEndpoint                  string   `json:"introspection_endpoint"`
-	ResponseTypesSupported                 []string `json:"response_types_supported"`
-	ResponseModesSupported                 []string `json:"response_modes_supported"`
-	GrantTypesSupported                    []string `json:"grant_types_supported"`
+	EndSessionEndpoint                     string   `json:"end_session_endpoint"`
 	SubjectTypesSupported                  []string `json:"subject_types_supported"`
 	IdTokenSigningAlgValuesSupported       []string `json:"id_token_signing_alg_values_supported"`
-	ScopesSupported                        []string `json:"scopes_supported"`
+	UserinfoSigningAlgValuesSupported      []string `json:"userinfo_signing_alg_values_supported"`
+	UserinfoEncryptionAlgValuesSupported   []string `json:"userinfo_encryption_alg_values_supported"`
+	UserinfoEncryptionEncValuesSupported   []string `json:"userinfo_encryption_enc_values_supported"`
+	AcrValuesSupported                     []string `json:"acr_values_supported"`
 	ClaimsSupported                        []string `json:"claims_supported"`
+	BackchannelLogoutSupported             bool     `json:"backchannel_logout_supported"`
+	BackchannelLogoutSessionSupported      bool     `json:"backchannel_logout_session_supported"`
+	FrontchannelLogoutSupported            bool     `json:"frontchannel_logout_supported"`
+	FrontchannelLogoutSessionSupported     bool     `json:"frontchannel_logout_session_supported"`
 	RequestParameterSupported              bool     `json:"request_parameter_supported"`
 	RequestObjectSigningAlgValuesSupported []string `json:"request_object_signing_alg_values_supported"`
+	BackchannelAuthenticationEndpoint      string   `json:"backchannel_authentication_endpoint"`
+	BackchannelTokenDeliveryModesSupported []string `json:"backchannel_token_delivery_modes_supported"`
+	BackchannelUserCodeParameterSupported  bool     `json:"backchannel_user_code_parameter_supported"`
 }
 
+// the grant types that the token endpoint and the authorization endpoint support
+var supportedGrantTypes = []string{"authorization_code", "implicit", "password", "client_credentials", "refresh_token", DeviceCodeGrantType, CibaGrantType, TokenExchangeGrantType}
+
+// the client authentication methods of the endpoints, "none" is for the public clients that use PKCE
+var (
+	tokenEndpointAuthMethods         = []string{ClientSecretBasic, ClientSecretPost, ClientSecretJwt, PrivateKeyJwt, ClientAuthMethodNone}
+	revocationEndpointAuthMethods    = []string{ClientSecretBasic, ClientSecretPost, ClientSecretJwt, PrivateKeyJwt, ClientAuthMethodNone}
+	introspectionEndpointAuthMethods = []string{ClientSecretBasic, ClientSecretPost, ClientSecretJwt, PrivateKeyJwt}
+)
+
+var codeChallengeMethods = []string{"S256"}
+
 func getOriginFromHost(host string) (string, string) {
 	protocol := "https://"
 	if strings.HasPrefix(host, "localhost") {
@@ -55,7 +98,8 @@ func getOriginFromHost(host string) (string, string) {
 	}
 }
 
-func GetOidcDiscovery(host string) OidcDiscovery {
+// getOrigin returns the origins of the frontend and the backend, which the "origin" config overrides
+func getOrigin(host string) (string, string) {
 	originFrontend, originBackend := getOriginFromHost(host)
 
 	origin := conf.GetConfigString("origin")
@@ -63,6 +107,82 @@ func GetOidcDiscovery(host string) OidcDiscovery {
 		originFrontend = origin
 		originBackend = origin
 	}
+	return originFrontend, originBackend
+}
+
+// getOrganizationIssuer returns the issuer of the organization that has its own issuer,
+// whose OpenID Provider metadata is at the issuer + "/.well-known/openid-configuration"
+func getOrganizationIssuer(originBackend string, organization string) string {
+	return fmt.Sprintf("%s/organizations/%s", originBackend, url.PathEscape(organization))
+}
+
+func getAuthorizationServerMetadata(host string) AuthorizationServerMetadata {
+	originFrontend, originBackend := getOrigin(host)
+
+	return AuthorizationServerMetadata{
+		Issuer:                            originBackend,
+		AuthorizationEndpoint:             fmt.Sprintf("%s/login/oauth/authorize", originFrontend),
+		TokenEndpoint:                     fmt.Sprintf("%s/api/login/oauth/access_token", originBackend),
+		JwksUri:                           fmt.Sprintf("%s/.well-known/jwks", originBackend),
+		RegistrationEndpoint:              fmt.Sprintf("%s/api/oauth/register", originBackend),
+		ScopesSupported:                   userScopes,
+		ResponseTypesSupported:            supportedResponseTypes,
+		ResponseModesSupported:            responseModes,
+		GrantTypesSupported:               supportedGrantTypes,
+		TokenEndpointAuthMethodsSupported: tokenEndpointAuthMethods,
+		TokenEndpointAuthSigningAlgValuesSupported:         append(append([]string{}, clientSecretJwtMethods...), privateKeyJwtMethods...),
+		RevocationEndpoint:                                 fmt.Sprintf("%s/api/login/oauth/revoke", originBackend),
+		RevocationEndpointAuthMethodsSupported:             revocationEndpointAuthMethods,
+		IntrospectionEndpoint:                              fmt.Sprintf("%s/api/login/oauth/introspect", originBackend),
+		IntrospectionEndpointAuthMethodsSupported:          introspectionEndpointAuthMethods,
+		IntrospectionEndpointAuthSigningAlgValuesSupported: append(append([]string{}, clientSecretJwtMethods...), privateKeyJwtMethods...),
+		CodeChallengeMethodsSupported:                      codeChallengeMethods,
+		DeviceAuthorizationEndpoint:                        fmt.Sprintf("%s/api/login/oauth/device_authorization", originBackend),
+		PushedAuthorizationRequestEndpoint:                 fmt.Sprintf("%s/api/login/oauth/par", originBackend),
+		DpopSigningAlgValuesSupported:                      dpopSigningMethods,
+	}
+}
+
+// GetAuthorizationServerMetadata returns the authorization server metadata of the server, or of the organization
+// that has its own issuer if the organization is given
+func GetAuthorizationServerMetadata(host string, organization string) (*AuthorizationServerMetadata, error) {
+	metadata := getAuthorizationServerMetadata(host)
+	if organization != "" {
+		err := setOrganizationMetadata(&metadata, organization)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &metadata, nil
+}
+
+// setOrganizationMetadata sets the issuer of the organization to the metadata,
+// and adds the scopes of the API resources of the organization
+func setOrganizationMetadata(metadata *AuthorizationServerMetadata, organization string) error {
+	organizationObj := getOrganization("admin", organization)
+	if organizationObj == nil || !organizationObj.UseOrganizationIssuer {
+		return fmt.Errorf("the organization: %s doesn't exist or doesn't have its own issuer", organization)
+	}
+
+	metadata.Issuer = getOrganizationIssuer(metadata.Issuer, organization)
+
+	scopes := append([]string{}, metadata.ScopesSupported...)
+	for _, apiResource := range GetApiResources(organization) {
+		if !apiResource.IsEnabled {
+			continue
+		}
+		for _, scope := range apiResource.Scopes {
+			if !hasScope(strings.Join(scopes, " "), scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	metadata.ScopesSupported = scopes
+	return nil
+}
+
+func GetOidcDiscovery(host string) OidcDiscovery {
+	_, originBackend := getOrigin(host)
 
 	// Examples:
 	// https://login.okta.com/.well-known/openid-configuration
@@ -70,26 +190,87 @@ func GetOidcDiscovery(host string) OidcDiscovery {
 	// https://accounts.google.com/.well-known/openid-configuration
 	// https://access.line.me/.well-known/openid-configuration
 	oidcDiscovery := OidcDiscovery{
-		Issuer:                                 originBackend,
-		AuthorizationEndpoint:                  fmt.Sprintf("%s/login/oauth/authorize", originFrontend),
-		TokenEndpoint:                          fmt.Sprintf("%s/api/login/oauth/access_token", originBackend),
+		AuthorizationServerMetadata:            getAuthorizationServerMetadata(host),
 		UserinfoEndpoint:                       fmt.Sprintf("%s/api/userinfo", originBackend),
-		JwksUri:                                fmt.Sprintf("%s/.well-known/jwks", originBackend),
-		IntrospectionEndpoint:                  fmt.Sprintf("%s/api/login/oauth/introspect", originBackend),
-		ResponseTypesSupported:                 []string{"code", "token", "id_token", "code token", "code id_token", "token id_token", "code token id_token", "none"},
-		ResponseModesSupported:                 []string{"login", "code", "link"},
-		GrantTypesSupported:                    []string{"password", "authorization_code"},
+		EndSessionEndpoint:                     fmt.Sprintf("%s/api/login/oauth/end_session", originBackend),
 		SubjectTypesSupported:                  []string{"public"},
-		IdTokenSigningAlgValuesSupported:       []string{"RS256"},
-		ScopesSupported:                        []string{"openid", "email", "profile", "address", "phone", "offline_access"},
-		ClaimsSupported:                        []string{"iss", "ver", "sub", "aud", "iat", "exp", "id", "type", "displayName", "avatar", "permanentAvatar", "email", "phone", "location", "affiliation", "title", "homepage", "bio", "tag", "region", "language", "score", "ranking", "isOnline", "isAdmin", "isGlobalAdmin", "isForbidden", "signupApplication", "ldap"},
+		IdTokenSigningAlgValuesSupported:       getSupportedSigningAlgorithms(),
+		UserinfoSigningAlgValuesSupported:      getSupportedSigningAlgorithms(),
+		UserinfoEncryptionAlgValuesSupported:   userinfoEncryptionAlgorithms,
+		UserinfoEncryptionEncValuesSupported:   userinfoEncryptionEncodings,
+		AcrValuesSupported:                     supportedAcrValues,
+		ClaimsSupported:                        []string{"iss", "sub", "aud", "iat", "exp", "auth_time", "nonce", "acr", "amr", "at_hash", "c_hash", "sid", "name", "given_name", "family_name", "preferred_username", "picture", "website", "gender", "birthdate", "locale", "updated_at", "email", "email_verified", "phone_number", "phone_number_verified", "address"},
+		BackchannelLogoutSupported:             true,
+		BackchannelLogoutSessionSupported:      true,
+		FrontchannelLogoutSupported:            true,
+		FrontchannelLogoutSessionSupported:     true,
 		RequestParameterSupported:              true,
 		RequestObjectSigningAlgValuesSupported: []string{"HS256", "HS384", "HS512"},
+		BackchannelAuthenticationEndpoint:      fmt.Sprintf("%s/api/login/oauth/bc-authorize", originBackend),
+		BackchannelTokenDeliveryModesSupported: cibaDeliveryModes,
+		BackchannelUserCodeParameterSupported:  false,
 	}
 
 	return oidcDiscovery
 }
 
+// GetOrganizationOidcDiscovery returns the OpenID Provider metadata of the organization that has its own issuer
+func GetOrganizationOidcDiscovery(host string, organization string) (*OidcDiscovery, error) {
+	oidcDiscovery := GetOidcDiscovery(host)
+	err := setOrganizationMetadata(&oidcDiscovery.AuthorizationServerMetadata, organization)
+	if err != nil {
+		return nil, err
+	}
+	return &oidcDiscovery, nil
+}
+
+// getSupportedSigningAlgorithms returns the algorithms that the keys of the certs can sign the tokens with
+func getSupportedSigningAlgorithms() []string {
+	supported := map[string]bool{}
+	for _, cert := range GetCerts("admin") {
+		publicKey, err := getCertPublicKey(cert)
+		if err != nil {
+			continue
+		}
+
+		for _, algorithm := range getKeySigningAlgorithms(publicKey) {
+			supported[algorithm] = true
+		}
+	}
+
+	res := []string{}
+	for _, algorithm := range signingAlgorithms {
+		if supported[algorithm] {
+			res = append(res, algorithm)
+		}
+	}
+	return res
+}
+
+func getJsonWebKey(cert *Cert, kid string, publicKeyPem string) (*jose.JSONWebKey, error) {
+	publicKey, err := parsePublicKeyPem(publicKeyPem, cert.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk := jose.JSONWebKey{
+		Key:   publicKey,
+		KeyID: kid,
+		Use:   "sig",
+	}
+	certDerBlock, _ := pem.Decode([]byte(publicKeyPem))
+	if x509Cert, err := x509.ParseCertificate(certDerBlock.Bytes); err == nil {
+		jwk.Certificates = []*x509.Certificate{x509Cert}
+	}
+	// the previous key may be of another type, if the crypto algorithm of the cert has changed
+	if canKeySignWith(publicKey, cert.CryptoAlgorithm) {
+		jwk.Algorithm = cert.CryptoAlgorithm
+	}
+	return &jwk, nil
+}
+
+// GetJsonWebKeySet returns the keys of the certs: the current keys, the next keys that will take over after
+// the rotation, and the previous keys that still verify the tokens they have signed.
 func GetJsonWebKeySet() (jose.JSONWebKeySet, error) {
 	certs := GetCerts("admin")
 	jwks := jose.JSONWebKeySet{}
@@ -97,17 +278,26 @@ func GetJsonWebKeySet() (jose.JSONWebKeySet, error) {
 	//link here: https://self-issued.info/docs/draft-ietf-jose-json-web-key.html
 	//or https://datatracker.ietf.org/doc/html/draft-ietf-jose-json-web-key
 	for _, cert := range certs {
-		certPemBlock := []byte(cert.PublicKey)
-		certDerBlock, _ := pem.Decode(certPemBlock)
-		x509Cert, _ := x509.ParseCertificate(certDerBlock.Bytes)
+		keys := map[string]string{getCertKeyId(cert): cert.PublicKey}
+		if cert.NextPublicKey != "" {
+			keys[cert.NextKeyId] = cert.NextPublicKey
+		}
+		if isCertPreviousKeyValid(cert) {
+			keys[cert.PreviousKeyId] = cert.PreviousPublicKey
+		}
 
-		var jwk jose.JSONWebKey
-		jwk.Key = x509Cert.PublicKey
-		jwk.Certificates = []*x509.Certificate{x509Cert}
-		jwk.KeyID = cert.Name
-		jwk.Algorithm = cert.CryptoAlgorithm
-		jwk.Use = "sig"
-		jwks.Keys = append(jwks.Keys, jwk)
+		for _, kid := range []string{getCertKeyId(cert), cert.NextKeyId, cert.PreviousKeyId} {
+			publicKeyPem, ok := keys[kid]
+			if !ok {
+				continue
+			}
+
+			jwk, err := getJsonWebKey(cert, kid, publicKeyPem)
+			if err != nil {
+				continue
+			}
+			jwks.Keys = append(jwks.Keys, *jwk)
+		}
 	}
 
 	return jwks, nil
@@ -0,0 +1,52 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetAuthorizationServerMetadata(t *testing.T) {
+	metadata := getAuthorizationServerMetadata("door.casdoor.com")
+	if metadata.Issuer != "https://door.casdoor.com" {
+		t.Errorf("issuer = %s", metadata.Issuer)
+	}
+
+	grantTypes := strings.Join(metadata.GrantTypesSupported, " ")
+	for _, grantType := range []string{"authorization_code", "password", "client_credentials", "refresh_token", DeviceCodeGrantType} {
+		if !hasScope(grantTypes, grantType) {
+			t.Errorf("grant_types_supported doesn't have %s", grantType)
+		}
+	}
+
+	if metadata.RevocationEndpoint != "https://door.casdoor.com/api/login/oauth/revoke" {
+		t.Errorf("revocation_endpoint = %s", metadata.RevocationEndpoint)
+	}
+	// the clients revoke their tokens with the same authentication as they get them
+	if strings.Join(metadata.RevocationEndpointAuthMethodsSupported, " ") != strings.Join(metadata.TokenEndpointAuthMethodsSupported, " ") {
+		t.Errorf("revocation_endpoint_auth_methods_supported = %v", metadata.RevocationEndpointAuthMethodsSupported)
+	}
+	if strings.Join(metadata.CodeChallengeMethodsSupported, " ") != "S256" {
+		t.Errorf("code_challenge_methods_supported = %v", metadata.CodeChallengeMethodsSupported)
+	}
+}
+
+func TestGetOrganizationIssuer(t *testing.T) {
+	issuer := getOrganizationIssuer("https://door.casdoor.com", "my org")
+	if issuer != "https://door.casdoor.com/organizations/my%20org" {
+		t.Errorf("issuer = %s", issuer)
+	}
+}
@@ -34,8 +34,11 @@ type Organization struct {
 	DefaultAvatar      string   `xorm:"varchar(100)" json:"defaultAvatar"`
 	Tags               []string `xorm:"mediumtext" json:"tags"`
 	MasterPassword     string   `xorm:"varchar(100)" json:"masterPassword"`
+	InitialAccessToken string   `xorm:"varchar(100) index" json:"initialAccessToken"`
 	EnableSoftDeletion bool     `json:"enableSoftDeletion"`
 	IsProfilePublic    bool     `json:"isProfilePublic"`
+
+	UseOrganizationIssuer bool `json:"useOrganizationIssuer"`
 }
 
 func GetOrganizationCount(owner, field, value string) int {
@@ -100,6 +103,9 @@ func GetMaskedOrganization(organization *Organization) *Organization {
 	if organization.MasterPassword != "" {
 		organization.MasterPassword = "***"
 	}
+	if organization.InitialAccessToken != "" {
+		organization.InitialAccessToken = "***"
+	}
 	return organization
 }
 
@@ -144,6 +150,9 @@ func UpdateOrganization(id string, organization *Organization) bool {
 	if organization.MasterPassword == "***" {
 		session.Omit("master_password")
 	}
+	if organization.InitialAccessToken == "***" {
+		session.Omit("initial_access_token")
+	}
 	affected, err := session.Update(organization)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,131 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// The redirect URI of an authorization request must exactly match one of the redirect URIs of the application,
+// except for the entries that opt in to the rules below:
+//   - "https://*.example.com/callback" matches the redirect URIs on any subdomain of one label of example.com
+//   - "regex:^https://app-[0-9]+\.example\.com/callback$" matches the redirect URIs that the whole regex matches
+//   - "http://127.0.0.1/callback" matches the loopback redirect URIs of native apps on any port, per rfc 8252 section 7.3
+const redirectUriRegexPrefix = "regex:"
+
+var loopbackHosts = []string{"127.0.0.1", "::1", "localhost"}
+
+func isLoopbackHost(host string) bool {
+	for _, loopbackHost := range loopbackHosts {
+		if host == loopbackHost {
+			return true
+		}
+	}
+	return false
+}
+
+// isRedirectUriPattern returns whether the redirect URI entry is a wildcard subdomain or a regex instead of a URI
+func isRedirectUriPattern(uri string) bool {
+	return strings.HasPrefix(uri, redirectUriRegexPrefix) || strings.Contains(uri, "://*.")
+}
+
+func getRedirectUriRegex(uri string) (*regexp.Regexp, error) {
+	expr := strings.TrimPrefix(uri, redirectUriRegexPrefix)
+	return regexp.Compile(fmt.Sprintf("^(?:%s)$", expr))
+}
+
+func isUriPartsEqual(u *url.URL, v *url.URL) bool {
+	return u.Scheme == v.Scheme && u.User.String() == v.User.String() && u.EscapedPath() == v.EscapedPath() && u.RawQuery == v.RawQuery
+}
+
+func isRedirectUriMatched(uri string, redirectUri string) bool {
+	if uri == redirectUri {
+		return true
+	}
+
+	if strings.HasPrefix(uri, redirectUriRegexPrefix) {
+		regex, err := getRedirectUriRegex(uri)
+		return err == nil && regex.MatchString(redirectUri)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	r, err := url.Parse(redirectUri)
+	if err != nil || !isUriPartsEqual(u, r) {
+		return false
+	}
+
+	if strings.HasPrefix(u.Host, "*.") {
+		domain := strings.TrimPrefix(u.Hostname(), "*")
+		label := strings.TrimSuffix(r.Hostname(), domain)
+		return strings.HasSuffix(r.Hostname(), domain) && label != "" && !strings.Contains(label, ".") && u.Port() == r.Port()
+	}
+
+	// the native app listens on the loopback interface on a port that the OS picks at the time of the request
+	return u.Scheme == "http" && isLoopbackHost(u.Hostname()) && u.Hostname() == r.Hostname()
+}
+
+// CheckRedirectUri returns an error if the redirect URI of an authorization request isn't allowed for the application
+func (application *Application) CheckRedirectUri(redirectUri string) error {
+	if redirectUri == "" {
+		return errors.New("error: redirect_uri is required")
+	}
+
+	r, err := url.Parse(redirectUri)
+	if err != nil || !r.IsAbs() {
+		return fmt.Errorf("error: redirect_uri: \"%s\" is not an absolute URI", redirectUri)
+	}
+	if r.Fragment != "" || strings.Contains(redirectUri, "#") {
+		return fmt.Errorf("error: redirect_uri: \"%s\" should not contain a fragment", redirectUri)
+	}
+
+	if len(application.RedirectUris) == 0 {
+		return fmt.Errorf("error: the application: %s has no redirect URIs, please add the redirect_uri: \"%s\" to its Redirect URLs", application.Name, redirectUri)
+	}
+
+	for _, uri := range application.RedirectUris {
+		if isRedirectUriMatched(uri, redirectUri) {
+			return nil
+		}
+	}
+	return fmt.Errorf("error: redirect_uri: \"%s\" doesn't match any of the Redirect URLs of the application: %s", redirectUri, application.Name)
+}
+
+// CheckRedirectUris returns an error if one of the redirect URIs of the application is an invalid pattern
+func (application *Application) CheckRedirectUris() error {
+	for _, uri := range application.RedirectUris {
+		if strings.HasPrefix(uri, redirectUriRegexPrefix) {
+			if _, err := getRedirectUriRegex(uri); err != nil {
+				return fmt.Errorf("invalid regex of the Redirect URL: \"%s\": %s", uri, err.Error())
+			}
+			continue
+		}
+
+		u, err := url.Parse(uri)
+		if err != nil {
+			return fmt.Errorf("invalid Redirect URL: \"%s\": %s", uri, err.Error())
+		}
+		if strings.Contains(uri, "*") && (!strings.HasPrefix(u.Host, "*.") || strings.Count(uri, "*") != 1 || !strings.Contains(strings.TrimPrefix(u.Hostname(), "*."), ".")) {
+			return fmt.Errorf("invalid Redirect URL: \"%s\", the wildcard is only allowed as the leftmost label of the domain, e.g. https://*.example.com/callback", uri)
+		}
+	}
+	return nil
+}
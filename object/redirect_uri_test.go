@@ -0,0 +1,68 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestCheckRedirectUri(t *testing.T) {
+	application := &Application{
+		Name: "app-test",
+		RedirectUris: []string{
+			"https://app.example.com/callback",
+			"https://*.example.org/callback",
+			`regex:https://app-[0-9]+\.example\.net/callback`,
+			"http://127.0.0.1/callback",
+			"com.example.app:/callback",
+		},
+	}
+
+	scenarios := map[string]bool{
+		"https://app.example.com/callback":                   true,
+		"https://app.example.com/callback/":                  false,
+		"https://app.example.com/callback?next=/":            false,
+		"https://app.example.com.evil.com/callback":          false,
+		"https://evil.com/?https://app.example.com/callback": false,
+		"https://app.example.com/callback#fragment":          false,
+		"https://a.example.org/callback":                     true,
+		"https://a.b.example.org/callback":                   false,
+		"https://example.org/callback":                       false,
+		"https://a.example.org.evil.com/callback":            false,
+		"https://app-42.example.net/callback":                true,
+		"https://app-42.example.net/callback/evil":           false,
+		"https://app-x.example.net/callback":                 false,
+		"http://127.0.0.1:51004/callback":                    true,
+		"http://127.0.0.1/callback":                          true,
+		"http://127.0.0.1:51004/other":                       false,
+		"https://127.0.0.1:51004/callback":                   false,
+		"com.example.app:/callback":                          true,
+		"":                                                   false,
+	}
+
+	for redirectUri, expected := range scenarios {
+		err := application.CheckRedirectUri(redirectUri)
+		if (err == nil) != expected {
+			t.Errorf("CheckRedirectUri(%q) = %v, expected valid: %v", redirectUri, err, expected)
+		}
+	}
+}
+
+func TestCheckRedirectUris(t *testing.T) {
+	for _, uri := range []string{"https://*.com/callback", "https://app.*.example.com/callback", "regex:(", "https://*.example.com/*"} {
+		application := &Application{RedirectUris: []string{uri}}
+		if application.CheckRedirectUris() == nil {
+			t.Errorf("the Redirect URL: %q should be invalid", uri)
+		}
+	}
+}
@@ -115,6 +115,8 @@ func (syncer *Syncer) setUserByKeyValue(user *User, key string, value string) {
 		user.EmailVerified = util.ParseBool(value)
 	case "Phone":
 		user.Phone = value
+	case "PhoneVerified":
+		user.PhoneVerified = util.ParseBool(value)
 	case "Location":
 		user.Location = value
 	case "Address":
@@ -19,6 +19,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/casdoor/casdoor/idp"
@@ -44,40 +46,64 @@ type Token struct {
 	Organization string `xorm:"varchar(100)" json:"organization"`
 	User         string `xorm:"varchar(100)" json:"user"`
 
-	Code          string `xorm:"varchar(100)" json:"code"`
-	AccessToken   string `xorm:"mediumtext" json:"accessToken"`
-	RefreshToken  string `xorm:"mediumtext" json:"refreshToken"`
-	ExpiresIn     int    `json:"expiresIn"`
-	Scope         string `xorm:"varchar(100)" json:"scope"`
+	Code         string `xorm:"varchar(100)" json:"code"`
+	AccessToken  string `xorm:"mediumtext" json:"accessToken"`
+	RefreshToken string `xorm:"mediumtext" json:"refreshToken"`
+	IdToken      string `xorm:"mediumtext" json:"idToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+	Scope        string `xorm:"varchar(100)" json:"scope"`
+	// the space-delimited API resources that the grant covers, per rfc 8707
+	Resource      string `xorm:"varchar(1000)" json:"resource"`
 	TokenType     string `xorm:"varchar(100)" json:"tokenType"`
 	CodeChallenge string `xorm:"varchar(100)" json:"codeChallenge"`
 	CodeIsUsed    bool   `json:"codeIsUsed"`
 	CodeExpireIn  int64  `json:"codeExpireIn"`
+
+	// refresh token rotation: all the tokens refreshed from the same grant share a family,
+	// and a rotated-out refresh token is kept to detect its reuse
+	Family              string `xorm:"varchar(100) index" json:"family"`
+	RefreshTokenRotated bool   `json:"refreshTokenRotated"`
+
+	// the JWK SHA-256 thumbprint of the DPoP key that the token is bound to
+	DpopJkt string `xorm:"varchar(100)" json:"dpopJkt"`
+	// the name of the app session that the token is issued in, which the logout of the session ends along with it
+	Sid string `xorm:"varchar(100) index" json:"sid"`
+
+	// the SHA-256 hashes of the opaque tokens handed out in place of the access token and the refresh token
+	AccessTokenHash  string `xorm:"varchar(100) index" json:"accessTokenHash"`
+	RefreshTokenHash string `xorm:"varchar(100) index" json:"refreshTokenHash"`
 }
 
 type TokenWrapper struct {
-	AccessToken  string `json:"access_token"`
-	IdToken      string `json:"id_token"`
-	RefreshToken string `json:"refresh_token"`
-	TokenType    string `json:"token_type"`
-	ExpiresIn    int    `json:"expires_in"`
-	Scope        string `json:"scope"`
-	Error        string `json:"error,omitempty"`
+	AccessToken     string `json:"access_token"`
+	IdToken         string `json:"id_token"`
+	RefreshToken    string `json:"refresh_token"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int    `json:"expires_in"`
+	Scope           string `json:"scope"`
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+type TokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
 }
 
 type IntrospectionResponse struct {
-	Active    bool     `json:"active"`
-	Scope     string   `json:"scope,omitempty"`
-	ClientId  string   `json:"client_id,omitempty"`
-	Username  string   `json:"username,omitempty"`
-	TokenType string   `json:"token_type,omitempty"`
-	Exp       int64    `json:"exp,omitempty"`
-	Iat       int64    `json:"iat,omitempty"`
-	Nbf       int64    `json:"nbf,omitempty"`
-	Sub       string   `json:"sub,omitempty"`
-	Aud       []string `json:"aud,omitempty"`
-	Iss       string   `json:"iss,omitempty"`
-	Jti       string   `json:"jti,omitempty"`
+	Active    bool      `json:"active"`
+	Scope     string    `json:"scope,omitempty"`
+	ClientId  string    `json:"client_id,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	TokenType string    `json:"token_type,omitempty"`
+	Exp       int64     `json:"exp,omitempty"`
+	Iat       int64     `json:"iat,omitempty"`
+	Nbf       int64     `json:"nbf,omitempty"`
+	Sub       string    `json:"sub,omitempty"`
+	Aud       []string  `json:"aud,omitempty"`
+	Iss       string    `json:"iss,omitempty"`
+	Jti       string    `json:"jti,omitempty"`
+	Cnf       *CnfClaim `json:"cnf,omitempty"`
 }
 
 func GetTokenCount(owner, field, value string) int {
@@ -172,6 +198,13 @@ func UpdateToken(id string, token *Token) bool {
 }
 
 func AddToken(token *Token) bool {
+	if token.Sid == "" && token.User != "" {
+		appSession := getAppSession(&Application{Owner: token.Owner, Name: token.Application}, &User{Owner: token.Organization, Name: token.User})
+		if appSession != nil {
+			token.Sid = appSession.Name
+		}
+	}
+
 	affected, err := adapter.Engine.Insert(token)
 	if err != nil {
 		panic(err)
@@ -190,98 +223,338 @@ func DeleteToken(token *Token) bool {
 }
 
 func DeleteTokenByAceessToken(accessToken string) (bool, *Application) {
-	token := Token{AccessToken: accessToken}
-	existed, err := adapter.Engine.Get(&token)
-	if err != nil {
-		panic(err)
-	}
-
-	if !existed {
+	token := GetTokenByAccessToken(accessToken)
+	if token == nil {
 		return false, nil
 	}
+
 	application := getApplication(token.Owner, token.Application)
-	affected, err := adapter.Engine.Where("access_token=?", accessToken).Delete(&Token{})
+	return DeleteToken(token), application
+}
+
+// DeleteTokenByIdToken deletes the token that the ID token was issued along with
+func DeleteTokenByIdToken(idToken string) bool {
+	if idToken == "" {
+		return false
+	}
+
+	affected, err := adapter.Engine.Where("id_token = ?", idToken).Delete(&Token{})
 	if err != nil {
 		panic(err)
 	}
 
-	return affected != 0, application
+	return affected != 0
 }
 
 func GetTokenByAccessToken(accessToken string) *Token {
+	if accessToken == "" {
+		return nil
+	}
+
 	//Check if the accessToken is in the database
-	token := Token{AccessToken: accessToken}
-	existed, err := adapter.Engine.Get(&token)
+	token := Token{}
+	existed, err := adapter.Engine.Where(getTokenValueCondition("access_token"), accessToken, getOpaqueTokenHash(accessToken)).Get(&token)
 	if err != nil || !existed {
 		return nil
 	}
 	return &token
 }
 
+func getTokenByRefreshToken(refreshToken string) *Token {
+	if refreshToken == "" {
+		return nil
+	}
+
+	token := Token{}
+	existed, err := adapter.Engine.Where(getTokenValueCondition("refresh_token"), refreshToken, getOpaqueTokenHash(refreshToken)).Get(&token)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &token
+	}
+
+	return nil
+}
+
+// rotateRefreshToken marks the refresh token as rotated out and invalidates its access token.
+// It returns false if the refresh token had already been rotated, e.g. by a concurrent request.
+func rotateRefreshToken(token *Token) bool {
+	token.AccessToken = ""
+	token.AccessTokenHash = ""
+	token.RefreshTokenRotated = true
+	affected, err := adapter.Engine.ID(core.PK{token.Owner, token.Name}).Where("refresh_token_rotated = ?", false).Cols("access_token", "access_token_hash", "refresh_token_rotated").Update(token)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// getFamily returns the family of the token, the first token of a grant is the root of its family
+func (token *Token) getFamily() string {
+	if token.Family != "" {
+		return token.Family
+	}
+	return token.Name
+}
+
+// revokeTokenFamily revokes all the tokens refreshed from the same grant as the token, it is used when
+// a rotated-out refresh token is presented again. The other grants of the user stay signed in.
+func revokeTokenFamily(token *Token) int {
+	family := token.getFamily()
+	affected, err := adapter.Engine.Where("owner = ? and application = ? and (family = ? or name = ?)", token.Owner, token.Application, family, family).Delete(&Token{})
+	if err != nil {
+		panic(err)
+	}
+
+	return int(affected)
+}
+
+// RevokeToken revokes an access token or a refresh token issued to the application, per rfc 7009.
+// The access token and the refresh token issued together live in the same Token row,
+// so revoking either of them invalidates both.
+func RevokeToken(application *Application, tokenValue string, tokenTypeHint string) bool {
+	if tokenValue == "" {
+		return false
+	}
+
+	var token *Token
+	if tokenTypeHint == "refresh_token" {
+		token = getTokenByRefreshToken(tokenValue)
+		if token == nil {
+			token = GetTokenByAccessToken(tokenValue)
+		}
+	} else {
+		token = GetTokenByAccessToken(tokenValue)
+		if token == nil {
+			token = getTokenByRefreshToken(tokenValue)
+		}
+	}
+
+	// a client can only revoke its own tokens
+	if token == nil || token.Owner != application.Owner || token.Application != application.Name {
+		return false
+	}
+
+	return DeleteToken(token)
+}
+
 func GetTokenByTokenAndApplication(token string, application string) *Token {
+	if token == "" {
+		return nil
+	}
+
+	tokenHash := getOpaqueTokenHash(token)
 	tokenResult := Token{}
-	existed, err := adapter.Engine.Where("(refresh_token = ? or access_token = ? ) and application = ?", token, token, application).Get(&tokenResult)
+	condition := fmt.Sprintf("(%s or %s) and application = ?", getTokenValueCondition("refresh_token"), getTokenValueCondition("access_token"))
+	existed, err := adapter.Engine.Where(condition, token, tokenHash, token, tokenHash, application).Get(&tokenResult)
 	if err != nil || !existed {
 		return nil
 	}
 	return &tokenResult
 }
 
-func CheckOAuthLogin(clientId string, responseType string, redirectUri string, scope string, state string) (string, *Application) {
-	if responseType != "code" && responseType != "token" && responseType != "id_token" {
-		return fmt.Sprintf("error: grant_type: %s is not supported in this application", responseType), nil
+// checkAuthorizationRequest validates the parameters of an authorization request against the application
+func checkAuthorizationRequest(application *Application, responseType string, redirectUri string, scope string, state string) string {
+	if !application.IsResponseTypeEnabled(responseType) {
+		return fmt.Sprintf("error: response_type: %s is not enabled for the application: %s", responseType, application.Name)
 	}
 
+	err := application.CheckRedirectUri(redirectUri)
+	if err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+func CheckOAuthLogin(clientId string, responseType string, redirectUri string, scope string, state string, requestUri string) (string, *Application) {
 	application := GetApplicationByClientId(clientId)
 	if application == nil {
 		return "Invalid client_id", nil
 	}
 
-	/* TODO: Temp disable
-	validUri := false
-	for _, tmpUri := range application.RedirectUris {
-		if strings.Contains(redirectUri, tmpUri) {
-			validUri = true
-			break
+	if requestUri != "" {
+		// the pushed parameters take the place of the ones on the query string
+		_, params, err := getPushedAuthRequestParameters(clientId, requestUri)
+		if err != nil {
+			return err.Error(), application
 		}
+
+		responseType = params.Get("response_type")
+		redirectUri = params.Get("redirect_uri")
+		scope = params.Get("scope")
+		state = params.Get("state")
+	} else if application.RequirePushedAuthorizationRequests {
+		return fmt.Sprintf("error: the application: %s requires pushed authorization requests (request_uri)", application.Name), application
+	}
+
+	msg := checkAuthorizationRequest(application, responseType, redirectUri, scope, state)
+	if msg != "" {
+		return msg, nil
 	}
-	if !validUri {
-		return fmt.Sprintf("Redirect URI: \"%s\" doesn't exist in the allowed Redirect URI list", redirectUri), application
-	}*/
 
 	// Mask application for /api/get-app-login
 	application.ClientSecret = ""
 	return "", application
 }
 
-func GetOAuthCode(userId string, clientId string, responseType string, redirectUri string, scope string, state string, nonce string, challenge string, host string) *Code {
+// checkFirstPartyLogin checks the authorization request of a first-party sign-in form, like the OTT login, which hands
+// the code back in its response instead of redirecting, so there is no redirect_uri to check unless the form supplies one
+func checkFirstPartyLogin(clientId string, responseType string) (string, *Application) {
+	application := GetApplicationByClientId(clientId)
+	if application == nil {
+		return "Invalid client_id", nil
+	}
+
+	if !application.IsResponseTypeEnabled(responseType) {
+		return fmt.Sprintf("error: response_type: %s is not enabled for the application: %s", responseType, application.Name), nil
+	}
+	return "", application
+}
+
+// AuthorizationRequest holds the parameters of an authorization request
+type AuthorizationRequest struct {
+	ResponseType  string
+	ResponseMode  string
+	RedirectUri   string
+	Scope         string
+	State         string
+	Nonce         string
+	CodeChallenge string
+	Resources     []string
+	Prompt        string
+	AcrValues     string
+	MaxAge        string
+
+	// the user has just consented to the request on the consent page
+	ConsentGranted bool
+	// the request comes from a sign-in form of a first-party application that can't show the consent page,
+	// like the OTT login, so the user is never asked for consent
+	FirstParty bool
+	// when and how the user of the session has authenticated, the authentication is unknown if it is nil
+	Authentication *Authentication
+}
+
+// authorizeRequest checks the authorization request of the user. The parameters of a pushed authorization request
+// take the place of the ones in the request, and the pushed request is used up.
+func authorizeRequest(userId string, clientId string, requestUri string, request *AuthorizationRequest) (*User, *Application, error) {
 	user := GetUser(userId)
 	if user == nil {
-		return &Code{
-			Message: fmt.Sprintf("The user: %s doesn't exist", userId),
-			Code:    "",
-		}
+		return nil, nil, fmt.Errorf("The user: %s doesn't exist", userId)
 	}
 	if user.IsForbidden {
-		return &Code{
-			Message: "error: the user is forbidden to sign in, please contact the administrator",
-			Code:    "",
-		}
+		return nil, nil, errors.New("error: the user is forbidden to sign in, please contact the administrator")
 	}
 
-	msg, application := CheckOAuthLogin(clientId, responseType, redirectUri, scope, state)
+	var pushedAuthRequest *PushedAuthRequest
+	if requestUri != "" {
+		var params url.Values
+		var err error
+		pushedAuthRequest, params, err = getPushedAuthRequestParameters(clientId, requestUri)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		request.ResponseType = params.Get("response_type")
+		request.ResponseMode = params.Get("response_mode")
+		request.RedirectUri = params.Get("redirect_uri")
+		request.Scope = params.Get("scope")
+		request.State = params.Get("state")
+		request.Nonce = params.Get("nonce")
+		request.CodeChallenge = params.Get("code_challenge")
+		request.Resources = params["resource"]
+		request.Prompt = params.Get("prompt")
+		request.AcrValues = params.Get("acr_values")
+		request.MaxAge = params.Get("max_age")
+	}
+
+	var msg string
+	var application *Application
+	if request.FirstParty && request.RedirectUri == "" && requestUri == "" {
+		msg, application = checkFirstPartyLogin(clientId, request.ResponseType)
+	} else {
+		msg, application = CheckOAuthLogin(clientId, request.ResponseType, request.RedirectUri, request.Scope, request.State, requestUri)
+	}
 	if msg != "" {
+		return nil, nil, errors.New(msg)
+	}
+
+	err := checkResponseMode(request.ResponseType, request.ResponseMode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resource, err := checkResources(application, request.Resources, request.Scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	request.Resources = strings.Fields(resource)
+
+	// the nonce ties the ID token issued by the authorization endpoint to the session of the client
+	if hasResponseType(request.ResponseType, ResponseTypeIdToken) && request.Nonce == "" {
+		return nil, nil, fmt.Errorf("error: nonce is required for the response_type: %s", request.ResponseType)
+	}
+
+	// the user signs in again first, then consents to the request
+	authentication := request.Authentication
+	if authentication == nil {
+		authentication = &Authentication{User: userId}
+	}
+	err = checkAuthentication(authentication, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the pushed request is kept for the request that follows the consent
+	err = checkConsent(application, user, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if pushedAuthRequest != nil && !usePushedAuthRequest(pushedAuthRequest) {
+		return nil, nil, errors.New("error: invalid request_uri")
+	}
+
+	return user, application, nil
+}
+
+func GetOAuthCode(userId string, clientId string, request *AuthorizationRequest, host string, requestUri string) *Code {
+	user, application, err := authorizeRequest(userId, clientId, requestUri, request)
+	if err != nil {
 		return &Code{
-			Message: msg,
+			Message: err.Error(),
 			Code:    "",
 		}
 	}
 
-	accessToken, refreshToken, err := generateJwtToken(application, user, nonce, scope, host)
+	addAppSession(application, user)
+	token, err := addOAuthCodeToken(application, user, request, host)
 	if err != nil {
 		panic(err)
 	}
 
+	return &Code{
+		Message: "",
+		Code:    token.Code,
+	}
+}
+
+// addOAuthCodeToken adds the tokens that the authorization code of the request will be exchanged for
+func addOAuthCodeToken(application *Application, user *User, request *AuthorizationRequest, host string) (*Token, error) {
+	resource := strings.Join(request.Resources, " ")
+	accessToken, refreshToken, err := generateJwtToken(application, user, request.Scope, resource, host, nil, request.Authentication)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := generateIdToken(application, user, request.Nonce, request.Scope, host, accessToken, "", request.Authentication)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := request.CodeChallenge
 	if challenge == "null" {
 		challenge = ""
 	}
@@ -296,26 +569,74 @@ func GetOAuthCode(userId string, clientId string, responseType string, redirectU
 		Code:          util.GenerateClientId(),
 		AccessToken:   accessToken,
 		RefreshToken:  refreshToken,
+		IdToken:       idToken,
 		ExpiresIn:     application.ExpireInHours * hourSeconds,
-		Scope:         scope,
+		Scope:         request.Scope,
+		Resource:      resource,
 		TokenType:     "Bearer",
 		CodeChallenge: challenge,
 		CodeIsUsed:    false,
 		CodeExpireIn:  time.Now().Add(time.Minute * 5).Unix(),
 	}
 	AddToken(token)
+	return token, nil
+}
 
-	return &Code{
-		Message: "",
-		Code:    token.Code,
+// getTokenClient returns the client application of a token endpoint request. A client that authenticates
+// with a client_assertion has proven that it holds its credentials, so the assertion stands in for the
+// client secret that the grants check.
+func getTokenClient(clientId string, clientSecret string, clientAssertionType string, clientAssertion string, host string) (*Application, string, error) {
+	if clientAssertion != "" {
+		application, err := ValidateClientAssertion(clientId, clientAssertionType, clientAssertion, host)
+		if err != nil {
+			return nil, "", err
+		}
+		return application, application.ClientSecret, nil
 	}
-}
 
-func GetOAuthToken(grantType string, clientId string, clientSecret string, code string, verifier string, scope string, username string, password string, host string, tag string, avatar string) *TokenWrapper {
-	var errString string
 	application := GetApplicationByClientId(clientId)
 	if application == nil {
-		errString = "error: invalid client_id"
+		return nil, "", errors.New("error: invalid client_id")
+	}
+	if application.RequiresClientAssertion() {
+		return nil, "", fmt.Errorf("error: the application: %s should authenticate with a client_assertion (%s)", application.Name, application.TokenEndpointAuthMethod)
+	}
+	return application, clientSecret, nil
+}
+
+// AuthenticateClient authenticates the client of a revocation request or a pushed authorization request with
+// its client secret or its client_assertion. A public client has no credentials, so it only identifies itself,
+// per rfc 7009 section 2.1 and rfc 9126 section 2.
+func AuthenticateClient(clientId string, clientSecret string, clientAssertionType string, clientAssertion string, host string) (*Application, error) {
+	application, clientSecret, err := getTokenClient(clientId, clientSecret, clientAssertionType, clientAssertion, host)
+	if err != nil {
+		return nil, err
+	}
+
+	err = application.checkClientSecret(clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// checkClientSecret returns an error if the client secret isn't the application's,
+// only a public client may leave it out
+func (application *Application) checkClientSecret(clientSecret string) error {
+	if application.IsPublicClient() && clientSecret == "" {
+		return nil
+	}
+	if clientSecret == "" || application.ClientSecret != clientSecret {
+		return errors.New("error: invalid client_secret")
+	}
+	return nil
+}
+
+func GetOAuthToken(grantType string, clientId string, clientSecret string, code string, verifier string, scope string, username string, password string, host string, tag string, avatar string, deviceCode string, authReqId string, subjectToken string, subjectTokenType string, actorToken string, actorTokenType string, audience string, clientAssertionType string, clientAssertion string, dpopProof string, resources []string) *TokenWrapper {
+	var errString string
+	application, clientSecret, err := getTokenClient(clientId, clientSecret, clientAssertionType, clientAssertion, host)
+	if err != nil {
+		errString = err.Error()
 		return &TokenWrapper{
 			AccessToken: errString,
 			TokenType:   "",
@@ -338,15 +659,35 @@ func GetOAuthToken(grantType string, clientId string, clientSecret string, code
 		}
 	}
 
+	var jkt string
+	if dpopProof != "" {
+		jkt, err = CheckDpopProof(dpopProof, "POST", GetDpopRequestUri(host, "/api/login/oauth/access_token"), "", application.RequireDpopNonce)
+		if err != nil {
+			errString = err.Error()
+			return &TokenWrapper{
+				AccessToken: errString,
+				TokenType:   "",
+				ExpiresIn:   0,
+				Scope:       "",
+				Error:       errString,
+			}
+		}
+	}
+
 	var token *Token
-	var err error
 	switch grantType {
 	case "authorization_code": // Authorization Code Grant
-		token, err = GetAuthorizationCodeToken(application, clientSecret, code, verifier)
+		token, err = GetAuthorizationCodeToken(application, clientSecret, code, verifier, resources, host)
 	case "password": //	Resource Owner Password Credentials Grant
-		token, err = GetPasswordToken(application, username, password, scope, host)
+		token, err = GetPasswordToken(application, username, password, scope, resources, host)
 	case "client_credentials": // Client Credentials Grant
-		token, err = GetClientCredentialsToken(application, clientSecret, scope, host)
+		token, err = GetClientCredentialsToken(application, clientSecret, scope, resources, host)
+	case DeviceCodeGrantType: // Device Authorization Grant
+		token, err = GetDeviceCodeToken(application, clientSecret, deviceCode, host)
+	case CibaGrantType: // Client Initiated Backchannel Authentication Grant
+		token, err = GetCibaToken(application, clientSecret, authReqId, host)
+	case TokenExchangeGrantType: // Token Exchange Grant
+		token, err = GetTokenExchangeToken(application, clientSecret, subjectToken, subjectTokenType, actorToken, actorTokenType, audience, scope, host)
 	}
 
 	if tag == "wechat_miniprogram" {
@@ -367,19 +708,46 @@ func GetOAuthToken(grantType string, clientId string, clientSecret string, code
 
 	token.CodeIsUsed = true
 	updateUsedByCode(token)
+	err = issueOpaqueToken(application, token)
+	if err != nil {
+		errString = err.Error()
+		return &TokenWrapper{
+			AccessToken: errString,
+			TokenType:   "",
+			ExpiresIn:   0,
+			Scope:       "",
+			Error:       errString,
+		}
+	}
+	if jkt != "" {
+		err = bindTokenToDpopKey(application, token, jkt)
+		if err != nil {
+			errString = err.Error()
+			return &TokenWrapper{
+				AccessToken: errString,
+				TokenType:   "",
+				ExpiresIn:   0,
+				Scope:       "",
+				Error:       errString,
+			}
+		}
+	}
 	tokenWrapper := &TokenWrapper{
 		AccessToken:  token.AccessToken,
-		IdToken:      token.AccessToken,
+		IdToken:      token.IdToken,
 		RefreshToken: token.RefreshToken,
 		TokenType:    token.TokenType,
 		ExpiresIn:    token.ExpiresIn,
 		Scope:        token.Scope,
 	}
+	if grantType == TokenExchangeGrantType {
+		tokenWrapper.IssuedTokenType = AccessTokenType
+	}
 
 	return tokenWrapper
 }
 
-func RefreshToken(grantType string, refreshToken string, scope string, clientId string, clientSecret string, host string) *TokenWrapper {
+func RefreshToken(grantType string, refreshToken string, scope string, clientId string, clientSecret string, host string, clientAssertionType string, clientAssertion string, dpopProof string, resources []string) *TokenWrapper {
 	var errString string
 	// check parameters
 	if grantType != "refresh_token" {
@@ -392,9 +760,9 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 			Error:       errString,
 		}
 	}
-	application := GetApplicationByClientId(clientId)
-	if application == nil {
-		errString = "error: invalid client_id"
+	application, clientSecret, err := getTokenClient(clientId, clientSecret, clientAssertionType, clientAssertion, host)
+	if err != nil {
+		errString = err.Error()
 		return &TokenWrapper{
 			AccessToken: errString,
 			TokenType:   "",
@@ -414,9 +782,8 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 		}
 	}
 	// check whether the refresh token is valid, and has not expired.
-	token := Token{RefreshToken: refreshToken}
-	existed, err := adapter.Engine.Get(&token)
-	if err != nil || !existed {
+	tokenObj := getTokenByRefreshToken(refreshToken)
+	if tokenObj == nil || tokenObj.Application != application.Name {
 		errString = "error: invalid refresh_token"
 		return &TokenWrapper{
 			AccessToken: errString,
@@ -426,9 +793,24 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 			Error:       errString,
 		}
 	}
+	token := *tokenObj
+
+	// a rotated-out refresh token is presented again: it may have been stolen,
+	// so revoke all the tokens refreshed from the same grant
+	if token.RefreshTokenRotated {
+		revokeTokenFamily(&token)
+		errString = "error: the refresh_token has already been used, all the tokens of this grant have been revoked"
+		return &TokenWrapper{
+			AccessToken: errString,
+			TokenType:   "",
+			ExpiresIn:   0,
+			Scope:       "",
+			Error:       errString,
+		}
+	}
 
 	cert := getCertByApplication(application)
-	_, err = ParseJwtToken(refreshToken, cert)
+	refreshClaims, err := ParseJwtToken(token.GetJwtToken(refreshToken), cert)
 	if err != nil {
 		errString := fmt.Sprintf("error: %s", err.Error())
 		return &TokenWrapper{
@@ -439,6 +821,25 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 			Error:       errString,
 		}
 	}
+
+	// a refresh token bound to a DPoP key can only be used with a proof signed by the same key
+	var jkt string
+	if dpopProof != "" || token.DpopJkt != "" {
+		jkt, err = CheckDpopProof(dpopProof, "POST", GetDpopRequestUri(host, "/api/login/oauth/refresh_token"), "", application.RequireDpopNonce)
+		if err == nil && token.DpopJkt != "" && jkt != token.DpopJkt {
+			err = errors.New("error: the DPoP proof is not signed by the key that the refresh_token is bound to")
+		}
+		if err != nil {
+			errString = err.Error()
+			return &TokenWrapper{
+				AccessToken: errString,
+				TokenType:   "",
+				ExpiresIn:   0,
+				Scope:       "",
+				Error:       errString,
+			}
+		}
+	}
 	// generate a new token
 	user := getUser(application.Organization, token.User)
 	if user.IsForbidden {
@@ -451,11 +852,43 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 			Error:       errString,
 		}
 	}
-	newAccessToken, newRefreshToken, err := generateJwtToken(application, user, "", scope, host)
+	// the refreshed tokens may be issued for fewer resources than the original grant
+	resource, err := checkGrantedResources(application, resources, scope, token.Resource)
+	if err != nil {
+		errString = err.Error()
+		return &TokenWrapper{
+			AccessToken: errString,
+			TokenType:   "",
+			ExpiresIn:   0,
+			Scope:       "",
+			Error:       errString,
+		}
+	}
+	// the refreshed tokens keep the time and the methods of the original authentication
+	authentication := getClaimsAuthentication(refreshClaims)
+	newAccessToken, newRefreshToken, err := generateJwtToken(application, user, scope, resource, host, nil, authentication)
+	if err != nil {
+		panic(err)
+	}
+	newIdToken, err := generateIdToken(application, user, "", scope, host, newAccessToken, "", authentication)
 	if err != nil {
 		panic(err)
 	}
 
+	if application.EnableRefreshTokenRotation {
+		if !rotateRefreshToken(&token) {
+			revokeTokenFamily(&token)
+			errString = "error: the refresh_token has already been used, all the tokens of this grant have been revoked"
+			return &TokenWrapper{
+				AccessToken: errString,
+				TokenType:   "",
+				ExpiresIn:   0,
+				Scope:       "",
+				Error:       errString,
+			}
+		}
+	}
+
 	newToken := &Token{
 		Owner:        application.Owner,
 		Name:         util.GenerateId(),
@@ -466,16 +899,31 @@ func RefreshToken(grantType string, refreshToken string, scope string, clientId
 		Code:         util.GenerateClientId(),
 		AccessToken:  newAccessToken,
 		RefreshToken: newRefreshToken,
+		IdToken:      newIdToken,
 		ExpiresIn:    application.ExpireInHours * hourSeconds,
 		Scope:        scope,
+		Resource:     token.Resource,
 		TokenType:    "Bearer",
+		Family:       token.getFamily(),
 	}
 	AddToken(newToken)
-	DeleteToken(&token)
+	err = issueOpaqueToken(application, newToken)
+	if err != nil {
+		panic(err)
+	}
+	if jkt != "" {
+		err = bindTokenToDpopKey(application, newToken, jkt)
+		if err != nil {
+			panic(err)
+		}
+	}
+	if !application.EnableRefreshTokenRotation {
+		DeleteToken(&token)
+	}
 
 	tokenWrapper := &TokenWrapper{
 		AccessToken:  newToken.AccessToken,
-		IdToken:      newToken.AccessToken,
+		IdToken:      newToken.IdToken,
 		RefreshToken: newToken.RefreshToken,
 		TokenType:    newToken.TokenType,
 		ExpiresIn:    newToken.ExpiresIn,
@@ -507,7 +955,7 @@ func IsGrantTypeValid(method string, grantTypes []string) bool {
 }
 
 // Authorization code flow
-func GetAuthorizationCodeToken(application *Application, clientSecret string, code string, verifier string) (*Token, error) {
+func GetAuthorizationCodeToken(application *Application, clientSecret string, code string, verifier string, resources []string, host string) (*Token, error) {
 	if code == "" {
 		return nil, errors.New("error: authorization code should not be empty")
 	}
@@ -545,11 +993,22 @@ func GetAuthorizationCodeToken(application *Application, clientSecret string, co
 		// code must be used within 5 minutes
 		return nil, errors.New("error: authorization code has expired")
 	}
+
+	resource, err := checkGrantedResources(application, resources, token.Scope, token.Resource)
+	if err != nil {
+		return nil, err
+	}
+	if resource != token.Resource {
+		err = reissueTokenForResource(application, token, resource, host)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return token, nil
 }
 
 // Resource Owner Password Credentials flow
-func GetPasswordToken(application *Application, username string, password string, scope string, host string) (*Token, error) {
+func GetPasswordToken(application *Application, username string, password string, scope string, resources []string, host string) (*Token, error) {
 	user := getUser(application.Organization, username)
 	if user == nil {
 		return nil, errors.New("error: the user does not exist")
@@ -561,7 +1020,17 @@ func GetPasswordToken(application *Application, username string, password string
 	if user.IsForbidden {
 		return nil, errors.New("error: the user is forbidden to sign in, please contact the administrator")
 	}
-	accessToken, refreshToken, err := generateJwtToken(application, user, "", scope, host)
+	resource, err := checkResources(application, resources, scope)
+	if err != nil {
+		return nil, err
+	}
+	addAppSession(application, user)
+	authentication := NewAuthentication(user.GetId(), AmrPassword, nil)
+	accessToken, refreshToken, err := generateJwtToken(application, user, scope, resource, host, nil, authentication)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := generateIdToken(application, user, "", scope, host, accessToken, "", authentication)
 	if err != nil {
 		return nil, err
 	}
@@ -575,8 +1044,10 @@ func GetPasswordToken(application *Application, username string, password string
 		Code:         util.GenerateClientId(),
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+		IdToken:      idToken,
 		ExpiresIn:    application.ExpireInHours * hourSeconds,
 		Scope:        scope,
+		Resource:     resource,
 		TokenType:    "Bearer",
 		CodeIsUsed:   true,
 	}
@@ -585,16 +1056,20 @@ func GetPasswordToken(application *Application, username string, password string
 }
 
 // Client Credentials flow
-func GetClientCredentialsToken(application *Application, clientSecret string, scope string, host string) (*Token, error) {
+func GetClientCredentialsToken(application *Application, clientSecret string, scope string, resources []string, host string) (*Token, error) {
 	if application.ClientSecret != clientSecret {
 		return nil, errors.New("error: invalid client_secret")
 	}
+	resource, err := checkResources(application, resources, scope)
+	if err != nil {
+		return nil, err
+	}
 	nullUser := &User{
 		Owner: application.Owner,
 		Id:    application.GetId(),
 		Name:  fmt.Sprintf("app/%s", application.Name),
 	}
-	accessToken, _, err := generateJwtToken(application, nullUser, "", scope, host)
+	accessToken, _, err := generateJwtToken(application, nullUser, scope, resource, host, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -609,6 +1084,7 @@ func GetClientCredentialsToken(application *Application, clientSecret string, sc
 		AccessToken:  accessToken,
 		ExpiresIn:    application.ExpireInHours * hourSeconds,
 		Scope:        scope,
+		Resource:     resource,
 		TokenType:    "Bearer",
 		CodeIsUsed:   true,
 	}
@@ -616,9 +1092,15 @@ func GetClientCredentialsToken(application *Application, clientSecret string, sc
 	return token, nil
 }
 
-// Implicit flow
+// GetTokenByUser issues the tokens to the user without an authorization code, e.g. once the user has approved a device
 func GetTokenByUser(application *Application, user *User, scope string, host string) (*Token, error) {
-	accessToken, refreshToken, err := generateJwtToken(application, user, "", scope, host)
+	addAppSession(application, user)
+	authentication := NewAuthentication(user.GetId(), "", nil)
+	accessToken, refreshToken, err := generateJwtToken(application, user, scope, "", host, nil, authentication)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := generateIdToken(application, user, "", scope, host, accessToken, "", authentication)
 	if err != nil {
 		return nil, err
 	}
@@ -632,12 +1114,17 @@ func GetTokenByUser(application *Application, user *User, scope string, host str
 		Code:         util.GenerateClientId(),
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+		IdToken:      idToken,
 		ExpiresIn:    application.ExpireInHours * hourSeconds,
 		Scope:        scope,
 		TokenType:    "Bearer",
 		CodeIsUsed:   true,
 	}
 	AddToken(token)
+	err = issueOpaqueToken(application, token)
+	if err != nil {
+		return nil, err
+	}
 	return token, nil
 }
 
@@ -688,7 +1175,12 @@ func GetWechatMiniProgramToken(application *Application, code string, host strin
 		AddUser(user)
 	}
 
-	accessToken, refreshToken, err := generateJwtToken(application, user, "", "", host)
+	authentication := NewAuthentication(user.GetId(), AmrFederated, nil)
+	accessToken, refreshToken, err := generateJwtToken(application, user, "", "", host, nil, authentication)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := generateIdToken(application, user, "", "", host, accessToken, "", authentication)
 	if err != nil {
 		return nil, err
 	}
@@ -703,6 +1195,7 @@ func GetWechatMiniProgramToken(application *Application, code string, host strin
 		Code:         session.SessionKey, //a trick, because miniprogram does not use the code, so use the code field to save the session_key
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+		IdToken:      idToken,
 		ExpiresIn:    application.ExpireInHours * 60,
 		Scope:        "",
 		TokenType:    "Bearer",
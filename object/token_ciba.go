@@ -0,0 +1,399 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/astaxie/beego/logs"
+	"github.com/casdoor/casdoor/util"
+	"xorm.io/core"
+)
+
+// Client Initiated Backchannel Authentication, per OpenID CIBA Core 1.0
+// https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html
+const (
+	CibaGrantType = "urn:openid:params:grant-type:ciba"
+
+	CibaDeliveryModePoll = "poll"
+	CibaDeliveryModePing = "ping"
+
+	cibaExpireInSeconds    = 300
+	cibaMaxExpireInSeconds = 600
+	cibaInterval           = 5
+	cibaPingTimeout        = 5 * time.Second
+
+	CibaRequestStatePending  = "Pending"
+	CibaRequestStateApproved = "Approved"
+	CibaRequestStateDenied   = "Denied"
+	CibaRequestStateUsed     = "Used"
+)
+
+var cibaDeliveryModes = []string{CibaDeliveryModePoll, CibaDeliveryModePing}
+
+type CibaRequest struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Application  string `xorm:"varchar(100)" json:"application"`
+	Organization string `xorm:"varchar(100) index" json:"organization"`
+	User         string `xorm:"varchar(100) index" json:"user"`
+
+	AuthReqId               string `xorm:"varchar(100) index" json:"authReqId"`
+	Scope                   string `xorm:"varchar(100)" json:"scope"`
+	BindingMessage          string `xorm:"varchar(100)" json:"bindingMessage"`
+	ClientNotificationToken string `xorm:"varchar(1000)" json:"clientNotificationToken"`
+	State                   string `xorm:"varchar(100)" json:"state"`
+	Interval                int    `json:"interval"`
+	ExpireIn                int64  `json:"expireIn"`
+	LastPollTime            int64  `json:"lastPollTime"`
+
+	ApplicationObj *Application `xorm:"-" json:"applicationObj"`
+}
+
+type CibaAuthResponse struct {
+	AuthReqId string `json:"auth_req_id"`
+	ExpiresIn int    `json:"expires_in"`
+	Interval  int    `json:"interval"`
+}
+
+func getCibaRequest(owner string, name string) *CibaRequest {
+	if owner == "" || name == "" {
+		return nil
+	}
+
+	cibaRequest := CibaRequest{Owner: owner, Name: name}
+	existed, err := adapter.Engine.Get(&cibaRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &cibaRequest
+	}
+
+	return nil
+}
+
+func getCibaRequestByAuthReqId(authReqId string) *CibaRequest {
+	if authReqId == "" {
+		return nil
+	}
+
+	cibaRequest := CibaRequest{AuthReqId: authReqId}
+	existed, err := adapter.Engine.Get(&cibaRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &cibaRequest
+	}
+
+	return nil
+}
+
+func AddCibaRequest(cibaRequest *CibaRequest) bool {
+	affected, err := adapter.Engine.Insert(cibaRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// updateCibaRequestPoll records the poll of the client on the pending request, the decision of the user
+// that races with the poll is kept
+func updateCibaRequestPoll(cibaRequest *CibaRequest) bool {
+	affected, err := adapter.Engine.ID(core.PK{cibaRequest.Owner, cibaRequest.Name}).Where("state = ?", CibaRequestStatePending).Cols("interval", "last_poll_time").Update(cibaRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// decideCibaRequest records the decision of the user, only once even if several decisions race
+func decideCibaRequest(cibaRequest *CibaRequest, state string) bool {
+	cibaRequest.State = state
+	affected, err := adapter.Engine.ID(core.PK{cibaRequest.Owner, cibaRequest.Name}).Where("state = ?", CibaRequestStatePending).Cols("state").Update(cibaRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func useCibaRequest(cibaRequest *CibaRequest) bool {
+	cibaRequest.State = CibaRequestStateUsed
+	affected, err := adapter.Engine.ID(core.PK{cibaRequest.Owner, cibaRequest.Name}).Where("state = ?", CibaRequestStateApproved).Cols("state", "last_poll_time").Update(cibaRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func getCibaVerificationUri(host string) string {
+	originFrontend, _ := getOrigin(host)
+	return fmt.Sprintf("%s/login/oauth/ciba", originFrontend)
+}
+
+// CheckBackchannelAuthentication checks the token delivery mode of the backchannel authentication,
+// the ping mode needs the endpoint that the client is notified at
+func (application *Application) CheckBackchannelAuthentication() error {
+	switch application.BackchannelTokenDeliveryMode {
+	case "", CibaDeliveryModePoll:
+	case CibaDeliveryModePing:
+		if !isRegistrationUriValid(application.BackchannelClientNotificationEndpoint, false) {
+			return errors.New("error: the ping token delivery mode requires a valid client notification endpoint")
+		}
+	default:
+		return fmt.Errorf("error: backchannel_token_delivery_mode: %s is not supported", application.BackchannelTokenDeliveryMode)
+	}
+	return nil
+}
+
+func (application *Application) getBackchannelTokenDeliveryMode() string {
+	if application.BackchannelTokenDeliveryMode == "" {
+		return CibaDeliveryModePoll
+	}
+	return application.BackchannelTokenDeliveryMode
+}
+
+// getCibaExpireIn returns the lifetime of the request in seconds, the client may ask for a shorter or
+// a longer one with requested_expiry, up to cibaMaxExpireInSeconds
+func getCibaExpireIn(requestedExpiry string) (int, error) {
+	if requestedExpiry == "" {
+		return cibaExpireInSeconds, nil
+	}
+
+	expireIn, err := strconv.Atoi(requestedExpiry)
+	if err != nil || expireIn <= 0 {
+		return 0, fmt.Errorf("invalid requested_expiry: %s", requestedExpiry)
+	}
+	if expireIn > cibaMaxExpireInSeconds {
+		expireIn = cibaMaxExpireInSeconds
+	}
+	return expireIn, nil
+}
+
+// AuthorizeBackchannel starts the authentication of the user that the login_hint identifies, on behalf of the client,
+// and alerts the user to approve it. The errors are the error codes defined in CIBA Core section 13.
+func AuthorizeBackchannel(clientId string, clientSecret string, clientAssertionType string, clientAssertion string, scope string, loginHint string, bindingMessage string, clientNotificationToken string, requestedExpiry string, host string) (*CibaAuthResponse, *TokenError) {
+	// the backchannel authentication is only for the confidential clients
+	application, clientSecret, err := getTokenClient(clientId, clientSecret, clientAssertionType, clientAssertion, host)
+	if err != nil || clientSecret == "" || application.ClientSecret != clientSecret {
+		return nil, &TokenError{Error: "invalid_client"}
+	}
+
+	if !IsGrantTypeValid(CibaGrantType, application.GrantTypes) {
+		return nil, &TokenError{Error: "unauthorized_client"}
+	}
+
+	if !hasScope(scope, "openid") {
+		return nil, &TokenError{Error: "invalid_scope", ErrorDescription: "the scope should contain openid"}
+	}
+
+	if loginHint == "" {
+		return nil, &TokenError{Error: "invalid_request", ErrorDescription: "the login_hint parameter is missing"}
+	}
+
+	mode := application.getBackchannelTokenDeliveryMode()
+	if mode == CibaDeliveryModePing && clientNotificationToken == "" {
+		return nil, &TokenError{Error: "invalid_request", ErrorDescription: "the client_notification_token parameter is required in the ping mode"}
+	}
+
+	expireIn, err := getCibaExpireIn(requestedExpiry)
+	if err != nil {
+		return nil, &TokenError{Error: "invalid_request", ErrorDescription: err.Error()}
+	}
+
+	user := GetUserByFields(application.Organization, loginHint)
+	if user == nil || user.IsForbidden {
+		return nil, &TokenError{Error: "unknown_user_id"}
+	}
+
+	cibaRequest := &CibaRequest{
+		Owner:                   application.Owner,
+		Name:                    util.GenerateId(),
+		CreatedTime:             util.GetCurrentTime(),
+		Application:             application.Name,
+		Organization:            user.Owner,
+		User:                    user.Name,
+		AuthReqId:               util.GenerateClientSecret(),
+		Scope:                   scope,
+		BindingMessage:          bindingMessage,
+		ClientNotificationToken: clientNotificationToken,
+		State:                   CibaRequestStatePending,
+		Interval:                cibaInterval,
+		ExpireIn:                time.Now().Add(time.Duration(expireIn) * time.Second).Unix(),
+	}
+
+	err = notifyCibaUser(application, user, cibaRequest, getCibaVerificationUri(host))
+	if err != nil {
+		return nil, &TokenError{Error: "unknown_user_id", ErrorDescription: err.Error()}
+	}
+
+	AddCibaRequest(cibaRequest)
+	return &CibaAuthResponse{
+		AuthReqId: cibaRequest.AuthReqId,
+		ExpiresIn: expireIn,
+		Interval:  cibaRequest.Interval,
+	}, nil
+}
+
+// GetUserCibaRequests returns the pending backchannel authentication requests of the user,
+// with the masked applications that the user is asked to sign in to
+func GetUserCibaRequests(userId string) []*CibaRequest {
+	organization, name := util.GetOwnerAndNameFromIdNoCheck(userId)
+
+	cibaRequests := []*CibaRequest{}
+	err := adapter.Engine.Desc("created_time").Where("state = ? and expire_in > ?", CibaRequestStatePending, time.Now().Unix()).Find(&cibaRequests, &CibaRequest{Organization: organization, User: name})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, cibaRequest := range cibaRequests {
+		cibaRequest.AuthReqId = ""
+		cibaRequest.ClientNotificationToken = ""
+		cibaRequest.ApplicationObj = GetMaskedApplication(getApplication(cibaRequest.Owner, cibaRequest.Application), "")
+	}
+	return cibaRequests
+}
+
+// VerifyCibaRequest records the decision of the signed-in user on the backchannel authentication request,
+// which should be the user that the request was made for. In the ping mode, the client is notified of it.
+func VerifyCibaRequest(userId string, id string, approved bool) error {
+	user := GetUser(userId)
+	if user == nil {
+		return fmt.Errorf("error: the user: %s doesn't exist", userId)
+	}
+	if user.IsForbidden {
+		return errors.New("error: the user is forbidden to sign in, please contact the administrator")
+	}
+
+	owner, name := util.GetOwnerAndNameFromIdNoCheck(id)
+	cibaRequest := getCibaRequest(owner, name)
+	if cibaRequest == nil || cibaRequest.Organization != user.Owner || cibaRequest.User != user.Name || cibaRequest.State != CibaRequestStatePending {
+		return errors.New("error: invalid backchannel authentication request")
+	}
+	if time.Now().Unix() > cibaRequest.ExpireIn {
+		return errors.New("error: the backchannel authentication request has expired")
+	}
+
+	application := getApplication(cibaRequest.Owner, cibaRequest.Application)
+	if application == nil {
+		return fmt.Errorf("error: the application: %s does not exist", cibaRequest.Application)
+	}
+
+	state := CibaRequestStateDenied
+	if approved {
+		state = CibaRequestStateApproved
+	}
+	if !decideCibaRequest(cibaRequest, state) {
+		return errors.New("error: invalid backchannel authentication request")
+	}
+
+	if application.getBackchannelTokenDeliveryMode() == CibaDeliveryModePing {
+		util.SafeGoroutine(func() {
+			err := sendCibaPing(application, cibaRequest)
+			if err != nil {
+				logs.Error("failed to send the backchannel authentication ping to application: %s, error: %s", application.Name, err.Error())
+			}
+		})
+	}
+	return nil
+}
+
+// sendCibaPing tells the client that the result of the request is ready to be fetched from the token endpoint
+func sendCibaPing(application *Application, cibaRequest *CibaRequest) error {
+	body, err := json.Marshal(map[string]string{"auth_req_id": cibaRequest.AuthReqId})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", application.BackchannelClientNotificationEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", cibaRequest.ClientNotificationToken))
+
+	client := &http.Client{Timeout: cibaPingTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the client notification endpoint responded with status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Backchannel authentication flow, the errors are the error codes defined in CIBA Core section 11
+func GetCibaToken(application *Application, clientSecret string, authReqId string, host string) (*Token, error) {
+	if application.ClientSecret != clientSecret {
+		return nil, errors.New("invalid_client")
+	}
+
+	cibaRequest := getCibaRequestByAuthReqId(authReqId)
+	if cibaRequest == nil || cibaRequest.Owner != application.Owner || cibaRequest.Application != application.Name || cibaRequest.State == CibaRequestStateUsed {
+		return nil, errors.New("invalid_grant")
+	}
+
+	now := time.Now().Unix()
+	if now > cibaRequest.ExpireIn {
+		return nil, errors.New("expired_token")
+	}
+
+	// the client is polling faster than the agreed interval: back off by 5 seconds
+	if cibaRequest.State == CibaRequestStatePending && cibaRequest.LastPollTime != 0 && now-cibaRequest.LastPollTime < int64(cibaRequest.Interval) {
+		cibaRequest.Interval += cibaInterval
+		cibaRequest.LastPollTime = now
+		updateCibaRequestPoll(cibaRequest)
+		return nil, errors.New("slow_down")
+	}
+	cibaRequest.LastPollTime = now
+
+	switch cibaRequest.State {
+	case CibaRequestStatePending:
+		updateCibaRequestPoll(cibaRequest)
+		return nil, errors.New("authorization_pending")
+	case CibaRequestStateDenied:
+		return nil, errors.New("access_denied")
+	}
+
+	user := getUser(cibaRequest.Organization, cibaRequest.User)
+	if user == nil || user.IsForbidden {
+		return nil, errors.New("access_denied")
+	}
+
+	// the auth_req_id is exchanged for a token only once, even if several polls race
+	if !useCibaRequest(cibaRequest) {
+		return nil, errors.New("invalid_grant")
+	}
+
+	return GetTokenByUser(application, user, cibaRequest.Scope, host)
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"xorm.io/core"
+)
+
+func TestCheckBackchannelAuthentication(t *testing.T) {
+	tests := []struct {
+		mode     string
+		endpoint string
+		valid    bool
+	}{
+		{"", "", true},
+		{CibaDeliveryModePoll, "", true},
+		{CibaDeliveryModePing, "https://client.example.com/ciba", true},
+		{CibaDeliveryModePing, "", false},
+		{"push", "https://client.example.com/ciba", false},
+	}
+	for _, test := range tests {
+		application := &Application{BackchannelTokenDeliveryMode: test.mode, BackchannelClientNotificationEndpoint: test.endpoint}
+		err := application.CheckBackchannelAuthentication()
+		if (err == nil) != test.valid {
+			t.Errorf("CheckBackchannelAuthentication(%s, %s) = %v", test.mode, test.endpoint, err)
+		}
+	}
+}
+
+func TestGetCibaExpireIn(t *testing.T) {
+	tests := []struct {
+		requestedExpiry string
+		expireIn        int
+		valid           bool
+	}{
+		{"", cibaExpireInSeconds, true},
+		{"120", 120, true},
+		{"86400", cibaMaxExpireInSeconds, true},
+		{"0", 0, false},
+		{"soon", 0, false},
+	}
+	for _, test := range tests {
+		expireIn, err := getCibaExpireIn(test.requestedExpiry)
+		if (err == nil) != test.valid || expireIn != test.expireIn {
+			t.Errorf("getCibaExpireIn(%s) = %d, %v", test.requestedExpiry, expireIn, err)
+		}
+	}
+}
+
+type testCibaNotifier struct {
+	provider *Provider
+}
+
+func (notifier *testCibaNotifier) Notify(application *Application, user *User, cibaRequest *CibaRequest, link string) error {
+	return nil
+}
+
+func TestGetCibaNotifiers(t *testing.T) {
+	RegisterCibaNotifier("Test", func(provider *Provider) CibaNotifier { return &testCibaNotifier{provider: provider} })
+	defer delete(cibaNotifierFactories, "Test")
+
+	providers := []*Provider{
+		{Name: "provider_storage", Category: "Storage"},
+		{Name: "provider_test", Category: "Test"},
+		{Name: "provider_email", Category: "Email"},
+		{Name: "provider_sms", Category: "SMS"},
+	}
+	application := &Application{Providers: []*ProviderItem{{Name: "provider_sms"}, {Name: "provider_storage"}, {Name: "provider_test"}, {Name: "provider_missing"}}}
+
+	// the notifiers follow the order of the providers of the application
+	notifiers := getCibaNotifiers(application, providers)
+	if len(notifiers) != 2 {
+		t.Fatalf("getCibaNotifiers() returns %d notifiers", len(notifiers))
+	}
+	if notifier, ok := notifiers[0].(*SmsCibaNotifier); !ok || notifier.Provider.Name != "provider_sms" {
+		t.Errorf("the first notifier is %#v", notifiers[0])
+	}
+	if notifier, ok := notifiers[1].(*testCibaNotifier); !ok || notifier.provider.Name != "provider_test" {
+		t.Errorf("the second notifier is %#v", notifiers[1])
+	}
+}
+
+func TestCibaPollAfterDecision(t *testing.T) {
+	application := addTestApplication(t, &Application{})
+	cibaRequest := &CibaRequest{
+		Owner:        application.Owner,
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Application:  application.Name,
+		Organization: "built-in",
+		User:         "admin",
+		AuthReqId:    util.GenerateId(),
+		Scope:        "openid",
+		State:        CibaRequestStatePending,
+		Interval:     cibaInterval,
+		ExpireIn:     time.Now().Add(time.Minute).Unix(),
+	}
+	AddCibaRequest(cibaRequest)
+	t.Cleanup(func() {
+		_, err := adapter.Engine.ID(core.PK{cibaRequest.Owner, cibaRequest.Name}).Delete(&CibaRequest{})
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	// the poll that has read the request before the user approves it doesn't undo the approval
+	poll := getCibaRequest(cibaRequest.Owner, cibaRequest.Name)
+	if !decideCibaRequest(getCibaRequest(cibaRequest.Owner, cibaRequest.Name), CibaRequestStateApproved) {
+		t.Fatal("failed to approve the request")
+	}
+	poll.Interval += cibaInterval
+	poll.LastPollTime = time.Now().Unix()
+	if updateCibaRequestPoll(poll) {
+		t.Errorf("the poll is recorded on the decided request")
+	}
+	if decided := getCibaRequest(cibaRequest.Owner, cibaRequest.Name); decided.State != CibaRequestStateApproved || decided.Interval != cibaInterval {
+		t.Errorf("the decided request is %#v", decided)
+	}
+
+	if _, err := GetCibaToken(application, application.ClientSecret, cibaRequest.AuthReqId, testHost); err != nil {
+		t.Errorf("failed to get the token of the approved request: %v", err)
+	}
+}
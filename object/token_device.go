@@ -0,0 +1,305 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+	"xorm.io/core"
+)
+
+// Device Authorization Grant, per rfc 8628
+// https://datatracker.ietf.org/doc/html/rfc8628
+const (
+	DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	deviceCodeExpireInSeconds = 600
+	deviceCodeInterval        = 5
+
+	DeviceAuthStatePending  = "Pending"
+	DeviceAuthStateApproved = "Approved"
+	DeviceAuthStateDenied   = "Denied"
+	DeviceAuthStateUsed     = "Used"
+)
+
+type DeviceAuth struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Application  string `xorm:"varchar(100)" json:"application"`
+	Organization string `xorm:"varchar(100)" json:"organization"`
+	User         string `xorm:"varchar(100)" json:"user"`
+
+	DeviceCode   string `xorm:"varchar(100) index" json:"deviceCode"`
+	UserCode     string `xorm:"varchar(100) index" json:"userCode"`
+	Scope        string `xorm:"varchar(100)" json:"scope"`
+	State        string `xorm:"varchar(100)" json:"state"`
+	Interval     int    `json:"interval"`
+	ExpireIn     int64  `json:"expireIn"`
+	LastPollTime int64  `json:"lastPollTime"`
+}
+
+type DeviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+func getDeviceAuthByDeviceCode(deviceCode string) *DeviceAuth {
+	if deviceCode == "" {
+		return nil
+	}
+
+	deviceAuth := DeviceAuth{DeviceCode: deviceCode}
+	existed, err := adapter.Engine.Get(&deviceAuth)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &deviceAuth
+	}
+
+	return nil
+}
+
+func getDeviceAuthByUserCode(userCode string) *DeviceAuth {
+	userCode = strings.ToUpper(strings.TrimSpace(userCode))
+	if userCode == "" {
+		return nil
+	}
+
+	deviceAuth := DeviceAuth{UserCode: userCode}
+	existed, err := adapter.Engine.Get(&deviceAuth)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &deviceAuth
+	}
+
+	return nil
+}
+
+// updateDeviceAuthPoll records the poll of the client on the pending device code, the decision of the user
+// that races with the poll is kept
+func updateDeviceAuthPoll(deviceAuth *DeviceAuth) bool {
+	affected, err := adapter.Engine.ID(core.PK{deviceAuth.Owner, deviceAuth.Name}).Where("state = ?", DeviceAuthStatePending).Cols("interval", "last_poll_time").Update(deviceAuth)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// decideDeviceAuth records the decision of the user along with the user, only once even if several decisions race
+func decideDeviceAuth(deviceAuth *DeviceAuth, user *User, state string) bool {
+	deviceAuth.Organization = user.Owner
+	deviceAuth.User = user.Name
+	deviceAuth.State = state
+	affected, err := adapter.Engine.ID(core.PK{deviceAuth.Owner, deviceAuth.Name}).Where("state = ?", DeviceAuthStatePending).Cols("organization", "user", "state").Update(deviceAuth)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func useDeviceAuth(deviceAuth *DeviceAuth) bool {
+	deviceAuth.State = DeviceAuthStateUsed
+	affected, err := adapter.Engine.ID(core.PK{deviceAuth.Owner, deviceAuth.Name}).Where("state = ?", DeviceAuthStateApproved).Cols("state", "last_poll_time").Update(deviceAuth)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func AddDeviceAuth(deviceAuth *DeviceAuth) bool {
+	affected, err := adapter.Engine.Insert(deviceAuth)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+func getDeviceVerificationUri(host string) string {
+	originFrontend, _ := getOriginFromHost(host)
+	origin := conf.GetConfigString("origin")
+	if origin != "" {
+		originFrontend = origin
+	}
+
+	return fmt.Sprintf("%s/login/oauth/device", originFrontend)
+}
+
+// GetDeviceAuthorization issues a device code to the client, which authenticates like it does at the token endpoint.
+// The device flow is mostly used by public clients, which have no client secret to authenticate with.
+func GetDeviceAuthorization(clientId string, clientSecret string, clientAssertionType string, clientAssertion string, scope string, host string) (*DeviceAuthResponse, error) {
+	application, err := AuthenticateClient(clientId, clientSecret, clientAssertionType, clientAssertion, host)
+	if err != nil {
+		return nil, errors.New("invalid_client")
+	}
+
+	if !IsGrantTypeValid(DeviceCodeGrantType, application.GrantTypes) {
+		return nil, errors.New("unauthorized_client")
+	}
+
+	deviceAuth := &DeviceAuth{
+		Owner:        application.Owner,
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Application:  application.Name,
+		Organization: application.Organization,
+		DeviceCode:   util.GenerateClientSecret(),
+		UserCode:     util.GenerateUserCode(),
+		Scope:        scope,
+		State:        DeviceAuthStatePending,
+		Interval:     deviceCodeInterval,
+		ExpireIn:     time.Now().Add(time.Second * deviceCodeExpireInSeconds).Unix(),
+	}
+	AddDeviceAuth(deviceAuth)
+
+	verificationUri := getDeviceVerificationUri(host)
+	return &DeviceAuthResponse{
+		DeviceCode:              deviceAuth.DeviceCode,
+		UserCode:                deviceAuth.UserCode,
+		VerificationUri:         verificationUri,
+		VerificationUriComplete: fmt.Sprintf("%s?user_code=%s", verificationUri, url.QueryEscape(deviceAuth.UserCode)),
+		ExpiresIn:               deviceCodeExpireInSeconds,
+		Interval:                deviceAuth.Interval,
+	}, nil
+}
+
+// GetDeviceAuthApplication returns the masked application that a pending user_code
+// belongs to, so the verification page can show the user what they are approving.
+func GetDeviceAuthApplication(userCode string) (*Application, error) {
+	deviceAuth := getDeviceAuthByUserCode(userCode)
+	if deviceAuth == nil || deviceAuth.State != DeviceAuthStatePending {
+		return nil, errors.New("error: invalid user code")
+	}
+	if time.Now().Unix() > deviceAuth.ExpireIn {
+		return nil, errors.New("error: the user code has expired")
+	}
+
+	application := getApplication(deviceAuth.Owner, deviceAuth.Application)
+	if application == nil {
+		return nil, fmt.Errorf("error: the application: %s does not exist", deviceAuth.Application)
+	}
+
+	application.ClientSecret = ""
+	return application, nil
+}
+
+// canSignInToApplication tells whether the user may be issued tokens of the application:
+// only the users of its organization and the global administrators may
+func canSignInToApplication(user *User, application *Application) bool {
+	return user.Owner == application.Organization || user.Owner == "built-in" || user.IsGlobalAdmin
+}
+
+// VerifyDeviceAuth binds the signed-in user to the user_code and records the user's decision.
+func VerifyDeviceAuth(userId string, userCode string, approved bool) error {
+	user := GetUser(userId)
+	if user == nil {
+		return fmt.Errorf("error: the user: %s doesn't exist", userId)
+	}
+	if user.IsForbidden {
+		return errors.New("error: the user is forbidden to sign in, please contact the administrator")
+	}
+
+	deviceAuth := getDeviceAuthByUserCode(userCode)
+	if deviceAuth == nil || deviceAuth.State != DeviceAuthStatePending {
+		return errors.New("error: invalid user code")
+	}
+	if time.Now().Unix() > deviceAuth.ExpireIn {
+		return errors.New("error: the user code has expired")
+	}
+
+	application := getApplication(deviceAuth.Owner, deviceAuth.Application)
+	if application == nil {
+		return fmt.Errorf("error: the application: %s does not exist", deviceAuth.Application)
+	}
+
+	if !canSignInToApplication(user, application) {
+		return fmt.Errorf("error: the user: %s doesn't belong to the organization of the application: %s", userId, application.Name)
+	}
+
+	state := DeviceAuthStateDenied
+	if approved {
+		state = DeviceAuthStateApproved
+	}
+	if !decideDeviceAuth(deviceAuth, user, state) {
+		return errors.New("error: invalid user code")
+	}
+	return nil
+}
+
+// Device Authorization flow, the errors are the error codes defined in rfc 8628 section 3.5
+func GetDeviceCodeToken(application *Application, clientSecret string, deviceCode string, host string) (*Token, error) {
+	if application.checkClientSecret(clientSecret) != nil {
+		return nil, errors.New("invalid_client")
+	}
+
+	deviceAuth := getDeviceAuthByDeviceCode(deviceCode)
+	if deviceAuth == nil || deviceAuth.Application != application.Name || deviceAuth.State == DeviceAuthStateUsed {
+		return nil, errors.New("invalid_grant")
+	}
+
+	now := time.Now().Unix()
+	if now > deviceAuth.ExpireIn {
+		return nil, errors.New("expired_token")
+	}
+
+	// the client is polling faster than the agreed interval: back off by 5 seconds
+	if deviceAuth.State == DeviceAuthStatePending && deviceAuth.LastPollTime != 0 && now-deviceAuth.LastPollTime < int64(deviceAuth.Interval) {
+		deviceAuth.Interval += deviceCodeInterval
+		deviceAuth.LastPollTime = now
+		updateDeviceAuthPoll(deviceAuth)
+		return nil, errors.New("slow_down")
+	}
+	deviceAuth.LastPollTime = now
+
+	switch deviceAuth.State {
+	case DeviceAuthStatePending:
+		updateDeviceAuthPoll(deviceAuth)
+		return nil, errors.New("authorization_pending")
+	case DeviceAuthStateDenied:
+		return nil, errors.New("access_denied")
+	}
+
+	user := getUser(deviceAuth.Organization, deviceAuth.User)
+	if user == nil || user.IsForbidden {
+		return nil, errors.New("access_denied")
+	}
+
+	// the device code is exchanged for a token only once, even if several polls race
+	if !useDeviceAuth(deviceAuth) {
+		return nil, errors.New("invalid_grant")
+	}
+
+	return GetTokenByUser(application, user, deviceAuth.Scope, host)
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestCanSignInToApplication(t *testing.T) {
+	application := &Application{Owner: "admin", Name: "app-example", Organization: "example"}
+
+	tests := []struct {
+		user    *User
+		allowed bool
+	}{
+		{&User{Owner: "example", Name: "alice"}, true},
+		{&User{Owner: "other", Name: "bob"}, false},
+		{&User{Owner: "other", Name: "carol", IsAdmin: true}, false},
+		{&User{Owner: "other", Name: "dave", IsGlobalAdmin: true}, true},
+		{&User{Owner: "built-in", Name: "admin"}, true},
+	}
+	for _, test := range tests {
+		if canSignInToApplication(test.user, application) != test.allowed {
+			t.Errorf("canSignInToApplication(%s) != %v", test.user.GetId(), test.allowed)
+		}
+	}
+}
+
+func addTestDeviceAuth(t *testing.T, application *Application) *DeviceAuth {
+	resp, err := GetDeviceAuthorization(application.ClientId, application.ClientSecret, "", "", "openid", testHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, err := adapter.Engine.Where("owner = ? and application = ?", application.Owner, application.Name).Delete(&DeviceAuth{})
+		if err != nil {
+			panic(err)
+		}
+	})
+	return getDeviceAuthByDeviceCode(resp.DeviceCode)
+}
+
+func getTestDeviceCodeTokenError(application *Application, clientSecret string, deviceCode string) string {
+	_, err := GetDeviceCodeToken(application, clientSecret, deviceCode, testHost)
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestGetDeviceAuthorizationOfClient(t *testing.T) {
+	application := addTestApplication(t, &Application{GrantTypes: []string{DeviceCodeGrantType}})
+	deviceAuth := addTestDeviceAuth(t, application)
+
+	// a confidential client authenticates for the device code and for the token
+	if _, err := GetDeviceAuthorization(application.ClientId, "", "", "", "openid", testHost); err == nil || err.Error() != "invalid_client" {
+		t.Errorf("the confidential client without its secret gets a device code: %v", err)
+	}
+	if errString := getTestDeviceCodeTokenError(application, "", deviceAuth.DeviceCode); errString != "invalid_client" {
+		t.Errorf("the confidential client without its secret polls: %s", errString)
+	}
+
+	// while a public client has no secret
+	publicApplication := addTestApplication(t, &Application{GrantTypes: []string{DeviceCodeGrantType}, TokenEndpointAuthMethod: ClientAuthMethodNone})
+	resp, err := GetDeviceAuthorization(publicApplication.ClientId, "", "", "", "openid", testHost)
+	if err != nil {
+		t.Fatalf("the public client doesn't get a device code: %v", err)
+	}
+	t.Cleanup(func() {
+		_, err := adapter.Engine.Where("owner = ? and application = ?", publicApplication.Owner, publicApplication.Name).Delete(&DeviceAuth{})
+		if err != nil {
+			panic(err)
+		}
+	})
+	if errString := getTestDeviceCodeTokenError(publicApplication, "", resp.DeviceCode); errString != "authorization_pending" {
+		t.Errorf("the public client polls: %s", errString)
+	}
+}
+
+func TestGetDeviceCodeToken(t *testing.T) {
+	application := addTestApplication(t, &Application{GrantTypes: []string{DeviceCodeGrantType}})
+	user := getTestUser(t)
+	deviceAuth := addTestDeviceAuth(t, application)
+	t.Cleanup(func() {
+		if appSession := getAppSession(application, user); appSession != nil {
+			deleteAppSession(appSession)
+		}
+	})
+
+	if errString := getTestDeviceCodeTokenError(application, application.ClientSecret, deviceAuth.DeviceCode); errString != "authorization_pending" {
+		t.Errorf("the first poll: %s", errString)
+	}
+	if errString := getTestDeviceCodeTokenError(application, application.ClientSecret, deviceAuth.DeviceCode); errString != "slow_down" {
+		t.Errorf("the poll within the interval: %s", errString)
+	}
+	if polled := getDeviceAuthByDeviceCode(deviceAuth.DeviceCode); polled.Interval != 2*deviceCodeInterval {
+		t.Errorf("the interval after slowing down is %d", polled.Interval)
+	}
+
+	// the poll that has read the device code before the user approves it doesn't undo the approval
+	poll := getDeviceAuthByDeviceCode(deviceAuth.DeviceCode)
+	if err := VerifyDeviceAuth(user.GetId(), deviceAuth.UserCode, true); err != nil {
+		t.Fatal(err)
+	}
+	poll.LastPollTime = 0
+	if updateDeviceAuthPoll(poll) {
+		t.Errorf("the poll is recorded on the approved device code")
+	}
+	if err := VerifyDeviceAuth(user.GetId(), deviceAuth.UserCode, false); err == nil {
+		t.Errorf("the approved device code is decided again")
+	}
+
+	// the approved device code is exchanged for a token only once
+	token, err := GetDeviceCodeToken(application, application.ClientSecret, deviceAuth.DeviceCode, testHost)
+	if err != nil || token.User != user.Name {
+		t.Fatalf("failed to get the token of the approved device code: %v", err)
+	}
+	if errString := getTestDeviceCodeTokenError(application, application.ClientSecret, deviceAuth.DeviceCode); errString != "invalid_grant" {
+		t.Errorf("the used device code: %s", errString)
+	}
+}
+
+func TestGetDeviceCodeTokenDenied(t *testing.T) {
+	application := addTestApplication(t, &Application{GrantTypes: []string{DeviceCodeGrantType}})
+	user := getTestUser(t)
+	deviceAuth := addTestDeviceAuth(t, application)
+
+	if err := VerifyDeviceAuth(user.GetId(), deviceAuth.UserCode, false); err != nil {
+		t.Fatal(err)
+	}
+	if errString := getTestDeviceCodeTokenError(application, application.ClientSecret, deviceAuth.DeviceCode); errString != "access_denied" {
+		t.Errorf("the denied device code: %s", errString)
+	}
+	if err := VerifyDeviceAuth(user.GetId(), deviceAuth.UserCode, true); err == nil {
+		t.Errorf("the denied device code is approved afterwards")
+	}
+	if errString := getTestDeviceCodeTokenError(application, application.ClientSecret, deviceAuth.DeviceCode); errString != "access_denied" {
+		t.Errorf("the denied device code is polled again: %s", errString)
+	}
+}
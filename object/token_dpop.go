@@ -0,0 +1,253 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
+	"gopkg.in/square/go-jose.v2"
+	"xorm.io/core"
+)
+
+// Demonstrating Proof of Possession (DPoP), per rfc 9449
+// https://datatracker.ietf.org/doc/html/rfc9449
+const (
+	DpopTokenType = "DPoP"
+
+	dpopProofMaxAgeSeconds   = 300
+	dpopNonceExpireInSeconds = 300
+
+	dpopJtiCategory   = "dpop"
+	dpopNonceCategory = "dpop_nonce"
+)
+
+// ErrUseDpopNonce means that the DPoP proof should carry a nonce provided by Casdoor in the DPoP-Nonce header.
+var ErrUseDpopNonce = errors.New("use_dpop_nonce")
+
+var dpopSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type DpopClaims struct {
+	Htm   string `json:"htm"`
+	Htu   string `json:"htu"`
+	Ath   string `json:"ath,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GetDpopNonce issues a nonce that the DPoP proofs can carry until it expires.
+func GetDpopNonce() string {
+	nonce := util.GenerateClientSecret()
+	addJtiRecord("admin", dpopNonceCategory, nonce, time.Now().Add(time.Second*dpopNonceExpireInSeconds).Unix())
+	return nonce
+}
+
+func isDpopNonceValid(nonce string) bool {
+	jtiRecord := getJtiRecord("admin", dpopNonceCategory, nonce)
+	return jtiRecord != nil && time.Now().Unix() <= jtiRecord.ExpireIn
+}
+
+// getDpopHtu returns the http URI of the request, without the query and fragment parts, per rfc 9449 section 4.3
+func getDpopHtu(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s://%s%s", strings.ToLower(u.Scheme), strings.ToLower(u.Host), u.Path)
+}
+
+// GetDpopRequestUri returns the URI that Casdoor is reached at for the path, for checking the htu of the DPoP proofs.
+func GetDpopRequestUri(host string, path string) string {
+	origin := conf.GetConfigString("origin")
+	_, originBackend := getOriginFromHost(host)
+	if origin != "" {
+		originBackend = origin
+	}
+
+	return fmt.Sprintf("%s%s", originBackend, path)
+}
+
+func getAccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// CheckDpopProof validates the DPoP proof and returns the JWK SHA-256 thumbprint of its key.
+// When the method is empty, htm and htu are not checked: this is the case of a proof forwarded to the
+// introspection endpoint, which is made for the request to the resource server. When the access token is
+// not empty, the proof must carry its hash in ath.
+func CheckDpopProof(proof string, method string, uri string, accessToken string, requireNonce bool) (string, error) {
+	claims, jkt, err := parseDpopProof(proof, method, uri, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	if claims.Nonce != "" || requireNonce {
+		if !isDpopNonceValid(claims.Nonce) {
+			return "", ErrUseDpopNonce
+		}
+	}
+
+	if !useJti("admin", fmt.Sprintf("%s/%s", dpopJtiCategory, jkt), claims.ID, claims.IssuedAt.Add(time.Second*dpopProofMaxAgeSeconds).Unix()) {
+		return "", errors.New("error: the DPoP proof has already been used")
+	}
+
+	return jkt, nil
+}
+
+// parseDpopProof verifies the DPoP proof with the key in its header and checks its claims against the request,
+// it returns the claims and the JWK SHA-256 thumbprint of the key. The nonce and the replay are left to the caller.
+func parseDpopProof(proof string, method string, uri string, accessToken string) (*DpopClaims, string, error) {
+	if proof == "" {
+		return nil, "", errors.New("error: the DPoP proof is missing")
+	}
+
+	var jwk jose.JSONWebKey
+	claims := DpopClaims{}
+	_, err := jwt.NewParser(jwt.WithValidMethods(dpopSigningMethods)).ParseWithClaims(proof, &claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != "dpop+jwt" {
+			return nil, errors.New("the typ should be dpop+jwt")
+		}
+
+		jwkJson, err := json.Marshal(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+		err = jwk.UnmarshalJSON(jwkJson)
+		if err != nil {
+			return nil, err
+		}
+		if !jwk.IsPublic() {
+			return nil, errors.New("the jwk should be a public key")
+		}
+
+		return jwk.Key, nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("error: invalid DPoP proof: %s", err.Error())
+	}
+
+	if method != "" {
+		if claims.Htm != method {
+			return nil, "", fmt.Errorf("error: the htm of the DPoP proof should be %s", method)
+		}
+		if getDpopHtu(claims.Htu) != getDpopHtu(uri) {
+			return nil, "", fmt.Errorf("error: the htu of the DPoP proof should be %s", getDpopHtu(uri))
+		}
+	}
+
+	if claims.IssuedAt == nil {
+		return nil, "", errors.New("error: the DPoP proof should have an iat")
+	}
+	if time.Since(claims.IssuedAt.Time) > time.Second*dpopProofMaxAgeSeconds || time.Until(claims.IssuedAt.Time) > time.Second*dpopProofMaxAgeSeconds {
+		return nil, "", errors.New("error: the iat of the DPoP proof is out of the acceptable range")
+	}
+
+	if accessToken != "" && claims.Ath != getAccessTokenHash(accessToken) {
+		return nil, "", errors.New("error: the ath of the DPoP proof doesn't match the access token")
+	}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, "", fmt.Errorf("error: invalid DPoP proof: %s", err.Error())
+	}
+	jkt := base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	if claims.ID == "" {
+		return nil, "", errors.New("error: the DPoP proof should have a jti")
+	}
+
+	return &claims, jkt, nil
+}
+
+// bindTokenToDpopKey re-issues the access token and refresh token with the cnf claim of the DPoP key,
+// so that they are only accepted along with proofs signed by that key. The at_hash of the ID token follows
+// the re-issued access token.
+func bindTokenToDpopKey(application *Application, token *Token, jkt string) error {
+	// an opaque token carries no cnf claim, the binding is only recorded in the Token row
+	if token.AccessTokenHash != "" {
+		token.TokenType = DpopTokenType
+		token.DpopJkt = jkt
+		_, err := adapter.Engine.ID(core.PK{token.Owner, token.Name}).Cols("token_type", "dpop_jkt").Update(token)
+		if err != nil {
+			panic(err)
+		}
+
+		return nil
+	}
+
+	cert := getCertByApplication(application)
+
+	accessToken, err := getDpopBoundJwtToken(application, cert, token.AccessToken, jkt, AccessTokenJwtType)
+	if err != nil {
+		return err
+	}
+
+	refreshToken := token.RefreshToken
+	if refreshToken != "" {
+		refreshToken, err = getDpopBoundJwtToken(application, cert, refreshToken, jkt, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	idToken := token.IdToken
+	if idToken != "" {
+		idToken, err = getIdTokenWithAtHash(application, idToken, accessToken)
+		if err != nil {
+			return err
+		}
+	}
+
+	token.AccessToken = accessToken
+	token.RefreshToken = refreshToken
+	token.IdToken = idToken
+	token.TokenType = DpopTokenType
+	token.DpopJkt = jkt
+	_, err = adapter.Engine.ID(core.PK{token.Owner, token.Name}).Cols("access_token", "refresh_token", "id_token", "token_type", "dpop_jkt").Update(token)
+	if err != nil {
+		panic(err)
+	}
+
+	return nil
+}
+
+// getDpopBoundJwtToken re-signs the token with the cnf claim, all the other claims are kept as they are
+func getDpopBoundJwtToken(application *Application, cert *Cert, tokenString string, jkt string, typ string) (string, error) {
+	_, err := ParseJwtToken(tokenString, cert)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(tokenString, claims)
+	if err != nil {
+		return "", err
+	}
+
+	claims["cnf"] = &CnfClaim{Jkt: jkt}
+	return signJwtClaims(application, claims, typ)
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gopkg.in/square/go-jose.v2"
+)
+
+const testDpopUri = "https://door.casdoor.com/api/login/oauth/access_token"
+
+func getTestDpopClaims() DpopClaims {
+	return DpopClaims{
+		Htm: "POST",
+		Htu: testDpopUri,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       "jti-1",
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+}
+
+func signTestDpopProof(t *testing.T, key *ecdsa.PrivateKey, jwk interface{}, typ string, claims DpopClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = typ
+	token.Header["jwk"] = jwk
+	proof, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proof
+}
+
+func TestParseDpopProof(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk := jose.JSONWebKey{Key: &key.PublicKey}
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof := signTestDpopProof(t, key, jwk, "dpop+jwt", getTestDpopClaims())
+	_, jkt, err := parseDpopProof(proof, "POST", testDpopUri+"?x=1", "")
+	if err != nil {
+		t.Fatalf("the valid proof is refused: %v", err)
+	}
+	if jkt != base64.RawURLEncoding.EncodeToString(thumbprint) {
+		t.Errorf("the jkt of the proof is %s", jkt)
+	}
+
+	// the proof forwarded to the introspection endpoint is made for the request to the resource server
+	accessToken := "access-token"
+	claims := getTestDpopClaims()
+	claims.Htm = "GET"
+	claims.Htu = "https://api.example.com/resource"
+	claims.Ath = getAccessTokenHash(accessToken)
+	if _, _, err = parseDpopProof(signTestDpopProof(t, key, jwk, "dpop+jwt", claims), "", "", accessToken); err != nil {
+		t.Errorf("the forwarded proof is refused: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		proof       string
+		method      string
+		accessToken string
+	}{
+		{"missing proof", "", "POST", ""},
+		{"wrong typ", signTestDpopProof(t, key, jwk, "JWT", getTestDpopClaims()), "POST", ""},
+		{"private jwk", signTestDpopProof(t, key, jose.JSONWebKey{Key: key}, "dpop+jwt", getTestDpopClaims()), "POST", ""},
+		{"signed by another key", signTestDpopProof(t, otherKey, jwk, "dpop+jwt", getTestDpopClaims()), "POST", ""},
+		{"wrong htm", signTestDpopProof(t, key, jwk, "dpop+jwt", getTestDpopClaims()), "GET", ""},
+		{"wrong htu", signTestDpopProof(t, key, jwk, "dpop+jwt", func() DpopClaims {
+			claims := getTestDpopClaims()
+			claims.Htu = "https://attacker.example.com/api/login/oauth/access_token"
+			return claims
+		}()), "POST", ""},
+		{"stale iat", signTestDpopProof(t, key, jwk, "dpop+jwt", func() DpopClaims {
+			claims := getTestDpopClaims()
+			claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+			return claims
+		}()), "POST", ""},
+		{"no jti", signTestDpopProof(t, key, jwk, "dpop+jwt", func() DpopClaims {
+			claims := getTestDpopClaims()
+			claims.ID = ""
+			return claims
+		}()), "POST", ""},
+		{"missing ath", signTestDpopProof(t, key, jwk, "dpop+jwt", getTestDpopClaims()), "", accessToken},
+		{"ath of another token", signTestDpopProof(t, key, jwk, "dpop+jwt", func() DpopClaims {
+			claims := getTestDpopClaims()
+			claims.Ath = getAccessTokenHash("other-token")
+			return claims
+		}()), "", accessToken},
+	}
+	for _, test := range tests {
+		if _, _, err := parseDpopProof(test.proof, test.method, testDpopUri, test.accessToken); err == nil {
+			t.Errorf("%s: the proof is accepted", test.name)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+// OAuth 2.0 Token Exchange, per rfc 8693
+// https://datatracker.ietf.org/doc/html/rfc8693
+const (
+	TokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	AccessTokenType = "urn:ietf:params:oauth:token-type:access_token"
+	JwtTokenType    = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+func isTokenTypeSupported(tokenType string) bool {
+	return tokenType == AccessTokenType || tokenType == JwtTokenType
+}
+
+// isScopeSubset checks that every scope in the requested scope is also granted by the scope,
+// an exchanged token can only be down-scoped.
+func isScopeSubset(requestedScope string, scope string) bool {
+	granted := map[string]bool{}
+	for _, s := range strings.Fields(scope) {
+		granted[s] = true
+	}
+
+	for _, s := range strings.Fields(requestedScope) {
+		if !granted[s] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsTokenExchangeAudienceAllowed checks the token exchange policy of the client application:
+// it may only exchange tokens for the applications listed in its TokenExchangeAudiences.
+func (application *Application) IsTokenExchangeAudienceAllowed(audience *Application) bool {
+	for _, name := range application.TokenExchangeAudiences {
+		if name == "*" || name == audience.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// canExchangeSubjectToken tells whether the client application may exchange the subject token issued to the subject
+// application: the token must be issued to the client, be addressed to it, or be issued to an application that the
+// client may exchange tokens for. The exchange never crosses the organization of the client.
+func (application *Application) canExchangeSubjectToken(subjectApplication *Application, subjectClaims *Claims) bool {
+	if subjectApplication.Organization != application.Organization {
+		return false
+	}
+	if subjectApplication.Owner == application.Owner && subjectApplication.Name == application.Name {
+		return true
+	}
+
+	for _, audience := range subjectClaims.Audience {
+		if audience == application.ClientId {
+			return true
+		}
+	}
+	return application.IsTokenExchangeAudienceAllowed(subjectApplication)
+}
+
+// getValidatedToken returns the Token row, the application that it is issued to and its parsed claims
+// for an access token issued by Casdoor, the token must not be revoked or expired.
+func getValidatedToken(tokenValue string, tokenType string, name string) (*Token, *Application, *Claims, error) {
+	if tokenValue == "" {
+		return nil, nil, nil, fmt.Errorf("error: %s should not be empty", name)
+	}
+	if !isTokenTypeSupported(tokenType) {
+		return nil, nil, nil, fmt.Errorf("error: %s_type: %s is not supported", name, tokenType)
+	}
+
+	token := GetTokenByAccessToken(tokenValue)
+	if token == nil {
+		return nil, nil, nil, fmt.Errorf("error: invalid %s", name)
+	}
+
+	tokenApplication := getApplication(token.Owner, token.Application)
+	if tokenApplication == nil {
+		return nil, nil, nil, fmt.Errorf("error: invalid %s", name)
+	}
+
+	claims, err := ParseJwtTokenByApplication(token.GetJwtToken(tokenValue), tokenApplication)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error: invalid %s: %s", name, err.Error())
+	}
+
+	return token, tokenApplication, claims, nil
+}
+
+// Token Exchange flow. Without an actor token, the issued token impersonates the subject.
+// With an actor token, the issued token is delegated and records the actor in the act claim.
+func GetTokenExchangeToken(application *Application, clientSecret string, subjectToken string, subjectTokenType string, actorToken string, actorTokenType string, audience string, scope string, host string) (*Token, error) {
+	if clientSecret == "" || application.ClientSecret != clientSecret {
+		return nil, errors.New("error: invalid client_secret")
+	}
+
+	subject, subjectApplication, subjectClaims, err := getValidatedToken(subjectToken, subjectTokenType, "subject_token")
+	if err != nil {
+		return nil, err
+	}
+	if !application.canExchangeSubjectToken(subjectApplication, subjectClaims) {
+		return nil, fmt.Errorf("error: the application: %s is not allowed to exchange the subject_token issued to the application: %s", application.Name, subjectApplication.Name)
+	}
+
+	user := getUser(subject.Organization, subject.User)
+	if user == nil {
+		return nil, errors.New("error: the subject_token is not issued to a user")
+	}
+	if user.IsForbidden {
+		return nil, errors.New("error: the user is forbidden to sign in, please contact the administrator")
+	}
+
+	targetApplication := application
+	if audience != "" {
+		targetApplication = GetApplicationByClientId(audience)
+		if targetApplication == nil {
+			return nil, fmt.Errorf("error: invalid audience: %s", audience)
+		}
+	}
+	if targetApplication.Organization != application.Organization {
+		return nil, fmt.Errorf("error: the audience: %s is not in the organization of the application: %s", targetApplication.Name, application.Name)
+	}
+	if targetApplication.Name != application.Name && !application.IsTokenExchangeAudienceAllowed(targetApplication) {
+		return nil, fmt.Errorf("error: the application: %s is not allowed to exchange tokens for the audience: %s", application.Name, targetApplication.Name)
+	}
+
+	if scope == "" {
+		scope = subjectClaims.Scope
+	} else if !isScopeSubset(scope, subjectClaims.Scope) {
+		return nil, fmt.Errorf("error: the scope: %s exceeds the scope of the subject_token", scope)
+	}
+
+	var act *ActClaim
+	if actorToken != "" {
+		_, _, actorClaims, err := getValidatedToken(actorToken, actorTokenType, "actor_token")
+		if err != nil {
+			return nil, err
+		}
+
+		act = &ActClaim{
+			Sub: actorClaims.Subject,
+			Act: subjectClaims.Act,
+		}
+	} else {
+		// impersonation keeps the delegation chain of the subject token, if any
+		act = subjectClaims.Act
+	}
+
+	accessToken, _, err := generateJwtToken(targetApplication, user, scope, "", host, act, getClaimsAuthentication(subjectClaims))
+	if err != nil {
+		return nil, err
+	}
+
+	token := &Token{
+		Owner:        targetApplication.Owner,
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Application:  targetApplication.Name,
+		Organization: user.Owner,
+		User:         user.Name,
+		Code:         util.GenerateClientId(),
+		AccessToken:  accessToken,
+		ExpiresIn:    targetApplication.ExpireInHours * hourSeconds,
+		Scope:        scope,
+		TokenType:    "Bearer",
+		CodeIsUsed:   true,
+	}
+	AddToken(token)
+	return token, nil
+}
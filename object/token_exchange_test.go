@@ -0,0 +1,63 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestCanExchangeSubjectToken(t *testing.T) {
+	application := &Application{Owner: "admin", Name: "app-api", Organization: "example", ClientId: "client-api", TokenExchangeAudiences: []string{"app-backend"}}
+
+	tests := []struct {
+		name               string
+		subjectApplication *Application
+		audience           []string
+		allowed            bool
+	}{
+		{"issued to the client", &Application{Owner: "admin", Name: "app-api", Organization: "example"}, []string{"client-api"}, true},
+		{"addressed to the client", &Application{Owner: "admin", Name: "app-web", Organization: "example"}, []string{"client-web", "client-api"}, true},
+		{"issued to an allowed application", &Application{Owner: "admin", Name: "app-backend", Organization: "example"}, []string{"client-backend"}, true},
+		{"issued to another application", &Application{Owner: "admin", Name: "app-web", Organization: "example"}, []string{"client-web"}, false},
+		{"issued in another organization", &Application{Owner: "admin", Name: "app-backend", Organization: "other"}, []string{"client-api"}, false},
+	}
+	for _, test := range tests {
+		claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{Audience: test.audience}}
+		if application.canExchangeSubjectToken(test.subjectApplication, claims) != test.allowed {
+			t.Errorf("%s: canExchangeSubjectToken() != %v", test.name, test.allowed)
+		}
+	}
+
+	// the wildcard policy still doesn't cross the organization
+	application.TokenExchangeAudiences = []string{"*"}
+	claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{Audience: []string{"client-other"}}}
+	if application.canExchangeSubjectToken(&Application{Owner: "admin", Name: "app-other", Organization: "other"}, claims) {
+		t.Errorf("the subject_token of another organization can be exchanged")
+	}
+	if !application.canExchangeSubjectToken(&Application{Owner: "admin", Name: "app-other", Organization: "example"}, claims) {
+		t.Errorf("the wildcard policy doesn't allow the applications of the organization")
+	}
+}
+
+func TestIsScopeSubset(t *testing.T) {
+	if !isScopeSubset("read", "read write") || !isScopeSubset("", "read") {
+		t.Errorf("the down-scoped request is refused")
+	}
+	if isScopeSubset("read admin", "read write") {
+		t.Errorf("the up-scoped request is accepted")
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// The ID token of OpenID Connect, which is distinct from the access token
+// https://openid.net/specs/openid-connect-core-1_0.html#IDToken
+const (
+	AccessTokenJwtType = "at+jwt"
+
+	// the authentication context class reference of signing in with a single factor, like a password
+	AcrSingleFactor = "1"
+	// the authentication context class reference of signing in with two factors, like a password and a verification code
+	AcrMultiFactor = "2"
+)
+
+type IdTokenClaims struct {
+	Nonce    string   `json:"nonce,omitempty"`
+	AuthTime int64    `json:"auth_time,omitempty"`
+	Acr      string   `json:"acr,omitempty"`
+	Amr      []string `json:"amr,omitempty"`
+	AtHash   string   `json:"at_hash,omitempty"`
+	CHash    string   `json:"c_hash,omitempty"`
+	Sid      string   `json:"sid,omitempty"`
+
+	UserClaims
+
+	jwt.RegisteredClaims
+}
+
+// UserClaims are the standard claims about the user that the ID token and the userinfo carry for the scopes
+// https://openid.net/specs/openid-connect-core-1_0.html#StandardClaims
+type UserClaims struct {
+	// profile scope
+	Name              string `json:"name,omitempty"`
+	GivenName         string `json:"given_name,omitempty"`
+	FamilyName        string `json:"family_name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	Website           string `json:"website,omitempty"`
+	Gender            string `json:"gender,omitempty"`
+	Birthdate         string `json:"birthdate,omitempty"`
+	Locale            string `json:"locale,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+
+	// email scope
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+
+	// phone scope
+	PhoneNumber         string `json:"phone_number,omitempty"`
+	PhoneNumberVerified *bool  `json:"phone_number_verified,omitempty"`
+
+	// address scope
+	Address *AddressClaim `json:"address,omitempty"`
+}
+
+type AddressClaim struct {
+	Formatted string `json:"formatted,omitempty"`
+	Country   string `json:"country,omitempty"`
+}
+
+func hasScope(scope string, target string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
+
+// getTokenHash returns the at_hash of an access token or the c_hash of a code: the base64url encoding of the left half
+// of its hash, with the hash function of the signing algorithm of the ID token (SHA-512 for EdDSA with Ed25519).
+func getTokenHash(value string, algorithm string) string {
+	if value == "" {
+		return ""
+	}
+
+	var sum []byte
+	switch {
+	case strings.HasSuffix(algorithm, "384"):
+		hash := sha512.Sum384([]byte(value))
+		sum = hash[:]
+	case strings.HasSuffix(algorithm, "512") || algorithm == "EdDSA":
+		hash := sha512.Sum512([]byte(value))
+		sum = hash[:]
+	default:
+		hash := sha256.Sum256([]byte(value))
+		sum = hash[:]
+	}
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+func getUpdatedAt(user *User) int64 {
+	updatedTime := user.UpdatedTime
+	if updatedTime == "" {
+		updatedTime = user.CreatedTime
+	}
+
+	t, err := time.Parse(time.RFC3339, updatedTime)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// setUserClaims sets the claims about the user that the scope asks for
+func setUserClaims(claims *UserClaims, user *User, scope string) {
+	if hasScope(scope, "profile") {
+		claims.Name = user.DisplayName
+		claims.GivenName = user.FirstName
+		claims.FamilyName = user.LastName
+		claims.PreferredUsername = user.Name
+		claims.Picture = user.Avatar
+		claims.Website = user.Homepage
+		claims.Gender = user.Gender
+		claims.Birthdate = user.Birthday
+		claims.Locale = user.Language
+		claims.UpdatedAt = getUpdatedAt(user)
+	}
+
+	if hasScope(scope, "email") && user.Email != "" {
+		emailVerified := user.EmailVerified
+		claims.Email = user.Email
+		claims.EmailVerified = &emailVerified
+	}
+
+	if hasScope(scope, "phone") && user.Phone != "" {
+		phoneNumberVerified := user.PhoneVerified
+		claims.PhoneNumber = user.Phone
+		claims.PhoneNumberVerified = &phoneNumberVerified
+	}
+
+	if hasScope(scope, "address") {
+		formatted := strings.Join(user.Address, "\n")
+		if formatted == "" {
+			formatted = user.Location
+		}
+		if formatted != "" || user.Region != "" {
+			claims.Address = &AddressClaim{Formatted: formatted, Country: user.Region}
+		}
+	}
+}
+
+// generateIdToken returns the ID token of the user for the application. The at_hash and c_hash bind the ID token
+// to the access token and the code that are issued along with it, they are left out when those are empty.
+// In "JWT-Empty" token format, the ID token carries no claims about the user except for the sub.
+func generateIdToken(application *Application, user *User, nonce string, scope string, host string, accessToken string, code string, authentication *Authentication) (string, error) {
+	nowTime := time.Now()
+	expireTime := nowTime.Add(time.Duration(application.ExpireInHours) * time.Hour)
+	algorithm := getSigningAlgorithm(application, getCertByApplication(application))
+
+	claims := IdTokenClaims{
+		Nonce:  nonce,
+		AtHash: getTokenHash(accessToken, algorithm),
+		CHash:  getTokenHash(code, algorithm),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    getJwtIssuer(application, host),
+			Subject:   user.Id,
+			Audience:  []string{application.ClientId},
+			ExpiresAt: jwt.NewNumericDate(expireTime),
+			IssuedAt:  jwt.NewNumericDate(nowTime),
+			ID:        util.GenerateId(),
+		},
+	}
+
+	if authentication != nil {
+		claims.AuthTime = authentication.AuthTime
+		claims.Acr = authentication.GetAcr()
+		claims.Amr = authentication.Amr
+	}
+
+	appSession := getAppSession(application, user)
+	if appSession != nil {
+		claims.Sid = appSession.Name
+	}
+
+	if application.TokenFormat != "JWT-Empty" {
+		setUserClaims(&claims.UserClaims, user, scope)
+	}
+
+	return signJwtClaims(application, claims, "")
+}
+
+// ParseIdToken verifies the signature of the ID token with the cert, even if it has expired.
+func ParseIdToken(idToken string, cert *Cert) (*IdTokenClaims, error) {
+	claims := IdTokenClaims{}
+	_, err := parseJwtClaims(idToken, &claims, cert, jwt.WithoutClaimsValidation())
+	if err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+// getIdTokenWithAtHash re-signs the ID token with the at_hash of the access token that replaces the original one
+func getIdTokenWithAtHash(application *Application, idToken string, accessToken string) (string, error) {
+	cert := getCertByApplication(application)
+	claims, err := ParseIdToken(idToken, cert)
+	if err != nil {
+		return "", err
+	}
+
+	claims.AtHash = getTokenHash(accessToken, getSigningAlgorithm(application, cert))
+	return signJwtClaims(application, *claims, "")
+}
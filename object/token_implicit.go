@@ -0,0 +1,221 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+// The implicit flow and the hybrid flow issue tokens from the authorization endpoint
+// https://openid.net/specs/openid-connect-core-1_0.html#ImplicitFlowAuth
+// https://openid.net/specs/openid-connect-core-1_0.html#HybridFlowAuth
+// https://openid.net/specs/oauth-v2-multiple-response-types-1_0.html
+// https://openid.net/specs/oauth-v2-form-post-response-mode-1_0.html
+const (
+	ResponseTypeCode    = "code"
+	ResponseTypeToken   = "token"
+	ResponseTypeIdToken = "id_token"
+
+	ResponseModeQuery    = "query"
+	ResponseModeFragment = "fragment"
+	ResponseModeFormPost = "form_post"
+)
+
+// the values of a response type are in this order once it is normalized
+var responseTypeValues = []string{ResponseTypeCode, ResponseTypeIdToken, ResponseTypeToken}
+
+var supportedResponseTypes = []string{"code", "token", "id_token", "id_token token", "code id_token", "code token", "code id_token token"}
+
+var responseModes = []string{ResponseModeQuery, ResponseModeFragment, ResponseModeFormPost}
+
+// AuthorizationResponse holds the parameters that the implicit flow and the hybrid flow send to the redirect URI
+type AuthorizationResponse struct {
+	Code        string `json:"code,omitempty"`
+	AccessToken string `json:"access_token,omitempty"`
+	TokenType   string `json:"token_type,omitempty"`
+	ExpiresIn   int    `json:"expires_in,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+	IdToken     string `json:"id_token,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+// normalizeResponseType returns the response type with its space-delimited values in a fixed order,
+// as the order of the values doesn't matter. It returns "" if the response type is invalid.
+func normalizeResponseType(responseType string) string {
+	values := strings.Fields(responseType)
+	res := []string{}
+	for _, responseTypeValue := range responseTypeValues {
+		count := 0
+		for _, value := range values {
+			if value == responseTypeValue {
+				count++
+			}
+		}
+		if count > 1 {
+			return ""
+		}
+		if count == 1 {
+			res = append(res, responseTypeValue)
+		}
+	}
+
+	if len(res) == 0 || len(res) != len(values) {
+		return ""
+	}
+	return strings.Join(res, " ")
+}
+
+func hasResponseType(responseType string, value string) bool {
+	for _, responseTypeValue := range strings.Fields(responseType) {
+		if responseTypeValue == value {
+			return true
+		}
+	}
+	return false
+}
+
+// IsImplicitResponseType returns whether the response type issues tokens from the authorization endpoint,
+// which is the case of all the response types except "code"
+func IsImplicitResponseType(responseType string) bool {
+	responseType = normalizeResponseType(responseType)
+	return responseType != "" && responseType != ResponseTypeCode
+}
+
+// IsResponseTypeEnabled returns whether the application accepts the response type. If the application hasn't
+// enabled any, "code" is accepted, and so are "token" and "id_token" when they are in its grant types.
+func (application *Application) IsResponseTypeEnabled(responseType string) bool {
+	responseType = normalizeResponseType(responseType)
+	if responseType == "" {
+		return false
+	}
+
+	if len(application.ResponseTypes) == 0 {
+		return responseType == ResponseTypeCode || (!strings.Contains(responseType, " ") && IsGrantTypeValid(responseType, application.GrantTypes))
+	}
+
+	for _, enabledResponseType := range application.ResponseTypes {
+		if normalizeResponseType(enabledResponseType) == responseType {
+			return true
+		}
+	}
+	return false
+}
+
+// getResponseMode returns the response mode of the request, which defaults to "query" for the authorization code
+// and to "fragment" when tokens are issued
+func getResponseMode(responseType string, responseMode string) string {
+	if responseMode != "" {
+		return responseMode
+	}
+	if normalizeResponseType(responseType) == ResponseTypeCode {
+		return ResponseModeQuery
+	}
+	return ResponseModeFragment
+}
+
+func checkResponseMode(responseType string, responseMode string) error {
+	if responseMode == "" {
+		return nil
+	}
+	supported := false
+	for _, mode := range responseModes {
+		if mode == responseMode {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return fmt.Errorf("error: response_mode: %s is not supported", responseMode)
+	}
+
+	// tokens in the query string would leak through the server logs and the Referer header
+	if responseMode == ResponseModeQuery && IsImplicitResponseType(responseType) {
+		return fmt.Errorf("error: response_mode: query is not allowed for the response_type: %s", responseType)
+	}
+	return nil
+}
+
+// addImplicitToken adds an access token issued by the authorization endpoint, which comes without a refresh token
+func addImplicitToken(application *Application, user *User, scope string, resource string, host string, authentication *Authentication) (*Token, error) {
+	accessToken, _, err := generateJwtToken(application, user, scope, resource, host, nil, authentication)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &Token{
+		Owner:        application.Owner,
+		Name:         util.GenerateId(),
+		CreatedTime:  util.GetCurrentTime(),
+		Application:  application.Name,
+		Organization: user.Owner,
+		User:         user.Name,
+		Code:         util.GenerateClientId(),
+		AccessToken:  accessToken,
+		ExpiresIn:    application.ExpireInHours * hourSeconds,
+		Scope:        scope,
+		Resource:     resource,
+		TokenType:    "Bearer",
+		CodeIsUsed:   true,
+	}
+	AddToken(token)
+	err = issueOpaqueToken(application, token)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// GetOAuthResponse returns the authorization response of the implicit flow or the hybrid flow, and the response mode
+// to send it to the redirect URI with. The ID token carries the at_hash and the c_hash of the access token and the
+// authorization code issued along with it.
+func GetOAuthResponse(userId string, clientId string, request *AuthorizationRequest, host string, requestUri string) (*AuthorizationResponse, string, error) {
+	user, application, err := authorizeRequest(userId, clientId, requestUri, request)
+	if err != nil {
+		return nil, "", err
+	}
+
+	addAppSession(application, user)
+	response := &AuthorizationResponse{State: request.State}
+	if hasResponseType(request.ResponseType, ResponseTypeCode) {
+		token, err := addOAuthCodeToken(application, user, request, host)
+		if err != nil {
+			return nil, "", err
+		}
+		response.Code = token.Code
+	}
+
+	if hasResponseType(request.ResponseType, ResponseTypeToken) {
+		token, err := addImplicitToken(application, user, request.Scope, strings.Join(request.Resources, " "), host, request.Authentication)
+		if err != nil {
+			return nil, "", err
+		}
+		response.AccessToken = token.AccessToken
+		response.TokenType = token.TokenType
+		response.ExpiresIn = token.ExpiresIn
+		response.Scope = token.Scope
+	}
+
+	if hasResponseType(request.ResponseType, ResponseTypeIdToken) {
+		response.IdToken, err = generateIdToken(application, user, request.Nonce, request.Scope, host, response.AccessToken, response.Code, request.Authentication)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	return response, getResponseMode(request.ResponseType, request.ResponseMode), nil
+}
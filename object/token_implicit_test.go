@@ -0,0 +1,50 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestNormalizeResponseType(t *testing.T) {
+	scenarios := map[string]string{
+		"code":                "code",
+		"token id_token":      "id_token token",
+		"token  code":         "code token",
+		"id_token token code": "code id_token token",
+		"code code":           "",
+		"code none":           "",
+		"":                    "",
+	}
+
+	for responseType, expected := range scenarios {
+		if actual := normalizeResponseType(responseType); actual != expected {
+			t.Errorf("normalizeResponseType(%q) = %q, expected %q", responseType, actual, expected)
+		}
+	}
+}
+
+func TestCheckResponseMode(t *testing.T) {
+	if err := checkResponseMode("code", "query"); err != nil {
+		t.Error(err)
+	}
+	if err := checkResponseMode("code id_token", "form_post"); err != nil {
+		t.Error(err)
+	}
+	if err := checkResponseMode("token", "query"); err == nil {
+		t.Error("tokens should not be sent in the query")
+	}
+	if err := checkResponseMode("code", "web_message"); err == nil {
+		t.Error("unsupported response_mode should be rejected")
+	}
+}
@@ -15,33 +15,46 @@
 package object
 
 import (
+	"crypto"
 	"fmt"
 	"time"
 
-	"github.com/casdoor/casdoor/conf"
+	"github.com/casdoor/casdoor/util"
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// Claims are the claims of the access token, which is a JWT profile for OAuth 2.0 access tokens per rfc 9068.
+// The claims about the user are carried by the ID token instead, see IdTokenClaims.
 type Claims struct {
-	*User
-	Nonce string `json:"nonce,omitempty"`
-	Tag   string `json:"tag,omitempty"`
-	Scope string `json:"scope,omitempty"`
+	*UserShort
+	Scope    string    `json:"scope,omitempty"`
+	ClientId string    `json:"client_id,omitempty"`
+	AuthTime int64     `json:"auth_time,omitempty"`
+	Acr      string    `json:"acr,omitempty"`
+	Amr      []string  `json:"amr,omitempty"`
+	Act      *ActClaim `json:"act,omitempty"`
+	Cnf      *CnfClaim `json:"cnf,omitempty"`
+	Sid      string    `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ActClaim identifies the acting party of a delegated token, per rfc 8693 section 4.1.
+// A nested act claim records the prior actors of the delegation chain.
+type ActClaim struct {
+	Sub string    `json:"sub"`
+	Act *ActClaim `json:"act,omitempty"`
+}
+
+// CnfClaim binds the token to the DPoP key with the JWK SHA-256 thumbprint jkt, per rfc 9449 section 6.1
+type CnfClaim struct {
+	Jkt string `json:"jkt,omitempty"`
+}
+
 type UserShort struct {
 	Owner string `xorm:"varchar(100) notnull pk" json:"owner"`
 	Name  string `xorm:"varchar(100) notnull pk" json:"name"`
 }
 
-type ClaimsShort struct {
-	*UserShort
-	Nonce string `json:"nonce,omitempty"`
-	Scope string `json:"scope,omitempty"`
-	jwt.RegisteredClaims
-}
-
 func getShortUser(user *User) *UserShort {
 	res := &UserShort{
 		Owner: user.Owner,
@@ -50,93 +63,136 @@ func getShortUser(user *User) *UserShort {
 	return res
 }
 
-func getShortClaims(claims Claims) ClaimsShort {
-	res := ClaimsShort{
-		UserShort:        getShortUser(claims.User),
-		Nonce:            claims.Nonce,
-		Scope:            claims.Scope,
-		RegisteredClaims: claims.RegisteredClaims,
-	}
-	return res
-}
-
-func generateJwtToken(application *Application, user *User, nonce string, scope string, host string) (string, string, error) {
+// generateJwtToken returns the access token and the refresh token. The resource is the space-delimited API resources
+// that make up the audience of the tokens, if any. The authentication is when and how the user authenticated,
+// it is carried over when the tokens are refreshed. The tokens of a client rather than a user have no authentication.
+func generateJwtToken(application *Application, user *User, scope string, resource string, host string, act *ActClaim, authentication *Authentication) (string, string, error) {
 	nowTime := time.Now()
 	expireTime := nowTime.Add(time.Duration(application.ExpireInHours) * time.Hour)
 	refreshExpireTime := nowTime.Add(time.Duration(application.RefreshExpireInHours) * time.Hour)
 
-	user.Password = ""
-	origin := conf.GetConfigString("origin")
-	_, originBackend := getOriginFromHost(host)
-	if origin != "" {
-		originBackend = origin
-	}
-
 	claims := Claims{
-		User:  user,
-		Nonce: nonce,
-		// FIXME: A workaround for custom claim by reusing `tag` in user info
-		Tag:   user.Tag,
-		Scope: scope,
+		UserShort: getShortUser(user),
+		Scope:     scope,
+		ClientId:  application.ClientId,
+		Act:       act,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    originBackend,
+			Issuer:    getJwtIssuer(application, host),
 			Subject:   user.Id,
-			Audience:  []string{application.ClientId},
+			Audience:  getTokenAudience(application, resource),
 			ExpiresAt: jwt.NewNumericDate(expireTime),
 			NotBefore: jwt.NewNumericDate(nowTime),
 			IssuedAt:  jwt.NewNumericDate(nowTime),
-			ID:        "",
+			// a unique jti keeps the tokens issued within the same second distinct
+			ID: util.GenerateId(),
 		},
 	}
 
-	var token *jwt.Token
-	var refreshToken *jwt.Token
-
-	// the JWT token length in "JWT-Empty" mode will be very short, as User object only has two properties: owner and name
-	if application.TokenFormat == "JWT-Empty" {
-		claimsShort := getShortClaims(claims)
-
-		token = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsShort)
-		claimsShort.ExpiresAt = jwt.NewNumericDate(refreshExpireTime)
-		refreshToken = jwt.NewWithClaims(jwt.SigningMethodRS256, claimsShort)
-	} else {
-		token = jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-		claims.ExpiresAt = jwt.NewNumericDate(refreshExpireTime)
-		refreshToken = jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if authentication != nil {
+		claims.AuthTime = authentication.AuthTime
+		claims.Acr = authentication.GetAcr()
+		claims.Amr = authentication.Amr
 	}
 
-	cert := getCertByApplication(application)
+	// the sid lets the application match the logout tokens with its session of the user
+	appSession := getAppSession(application, user)
+	if appSession != nil {
+		claims.Sid = appSession.Name
+	}
 
-	// RSA private key
-	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cert.PrivateKey))
+	// the claims about the user are only the ones that the claim rules of the application release for the scope
+	// along with the custom claims that the claim templates of the application map
+	releasedClaims := getReleasedClaims(application, user, scope)
+	err := addCustomClaims(releasedClaims, application, user, scope, ClaimTargetJwt)
+	if err != nil {
+		return "", "", err
+	}
+	tokenClaims, err := addReleasedClaims(claims, releasedClaims)
+	if err != nil {
+		return "", "", err
+	}
+	tokenString, err := signJwtClaims(application, jwt.MapClaims(tokenClaims), AccessTokenJwtType)
 	if err != nil {
 		return "", "", err
 	}
 
-	token.Header["kid"] = cert.Name
-	tokenString, err := token.SignedString(key)
+	claims.ExpiresAt = jwt.NewNumericDate(refreshExpireTime)
+	refreshTokenClaims, err := addReleasedClaims(claims, releasedClaims)
 	if err != nil {
 		return "", "", err
 	}
-	refreshTokenString, err := refreshToken.SignedString(key)
+	refreshTokenString, err := signJwtClaims(application, jwt.MapClaims(refreshTokenClaims), "")
 
 	return tokenString, refreshTokenString, err
 }
 
-func ParseJwtToken(token string, cert *Cert) (*Claims, error) {
-	t, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
+// getJwtIssuer returns the issuer of the tokens of the application,
+// which is the issuer of its organization if the organization has its own one
+func getJwtIssuer(application *Application, host string) string {
+	_, originBackend := getOrigin(host)
+	organization := getOrganization("admin", application.Organization)
+	if organization != nil && organization.UseOrganizationIssuer {
+		return getOrganizationIssuer(originBackend, organization.Name)
+	}
+	return originBackend
+}
+
+// signJwtClaims signs the claims with the cert of the application, typ overrides the default "JWT" type header
+func signJwtClaims(application *Application, claims jwt.Claims, typ string) (string, error) {
+	return signJwtClaimsWithAlgorithm(application, claims, typ, "")
+}
+
+// signJwtClaimsWithAlgorithm is signJwtClaims with an algorithm other than the signing algorithm of the application
+func signJwtClaimsWithAlgorithm(application *Application, claims jwt.Claims, typ string, algorithm string) (string, error) {
+	cert := getCertByApplication(application)
+
+	if algorithm == "" {
+		algorithm = getSigningAlgorithm(application, cert)
+	}
+	method, err := getSigningMethod(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := getCertPrivateKey(cert)
+	if err != nil {
+		return "", err
+	}
+	if signer, ok := key.(crypto.Signer); !ok || !canKeySignWith(signer.Public(), algorithm) {
+		return "", fmt.Errorf("error: the cert: %s can't sign with the algorithm: %s", cert.Name, algorithm)
+	}
 
-		// RSA public key
-		publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cert.PublicKey))
+	token := jwt.NewWithClaims(method, claims)
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+	token.Header["kid"] = getCertKeyId(cert)
+	return token.SignedString(key)
+}
+
+// parseJwtClaims verifies the token with the key of the cert that the kid picks, the current key or the previous one.
+// The algorithms that the key can sign with are accepted.
+func parseJwtClaims(token string, claims jwt.Claims, cert *Cert, options ...jwt.ParserOption) (*jwt.Token, error) {
+	return jwt.NewParser(options...).ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		publicKeyPem, err := getCertPublicKeyByKid(cert, kid)
 		if err != nil {
 			return nil, err
 		}
 
+		publicKey, err := parsePublicKeyPem(publicKeyPem, cert.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !canKeySignWith(publicKey, token.Method.Alg()) {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
 		return publicKey, nil
 	})
+}
+
+func ParseJwtToken(token string, cert *Cert) (*Claims, error) {
+	t, err := parseJwtClaims(token, &Claims{}, cert)
 
 	if t != nil {
 		if claims, ok := t.Claims.(*Claims); ok && t.Valid {
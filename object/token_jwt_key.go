@@ -15,15 +15,68 @@
 package object
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"math/big"
 	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	signingAlgorithms    = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+	rsaSigningAlgorithms = signingAlgorithms[:6]
+
+	// the curve of the ECDSA key for each algorithm, per rfc 7518 section 3.4
+	ecdsaCurves = map[string]elliptic.Curve{
+		"ES256": elliptic.P256(),
+		"ES384": elliptic.P384(),
+		"ES512": elliptic.P521(),
+	}
 )
 
+// IsCryptoAlgorithmSupported tells whether Casdoor can generate the keys for the crypto algorithm and sign with them
+func IsCryptoAlgorithmSupported(cryptoAlgorithm string) bool {
+	if cryptoAlgorithm == "" {
+		return true
+	}
+	for _, algorithm := range signingAlgorithms {
+		if algorithm == cryptoAlgorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// generateKeys generates the certificate and the private key for the crypto algorithm of the cert:
+// an RSA key of the bit size for RS* and PS*, an ECDSA key on the matching curve for ES*, or an Ed25519 key for EdDSA.
+func generateKeys(cryptoAlgorithm string, bitSize int, expireInYears int, commonName string, organization string) (string, string, error) {
+	if curve, ok := ecdsaCurves[cryptoAlgorithm]; ok {
+		publicKey, privateKey := generateEcdsaKeys(curve, expireInYears, commonName, organization)
+		return publicKey, privateKey, nil
+	}
+
+	if cryptoAlgorithm == "EdDSA" {
+		publicKey, privateKey := generateEd25519Keys(expireInYears, commonName, organization)
+		return publicKey, privateKey, nil
+	}
+
+	if !IsCryptoAlgorithmSupported(cryptoAlgorithm) {
+		return "", "", fmt.Errorf("error: the crypto algorithm: %s is not supported", cryptoAlgorithm)
+	}
+	publicKey, privateKey := generateRsaKeys(bitSize, expireInYears, commonName, organization)
+	return publicKey, privateKey, nil
+}
+
 func generateRsaKeys(bitSize int, expireInYears int, commonName string, organization string) (string, string) {
 	// https://stackoverflow.com/questions/64104586/use-golang-to-get-rsa-key-the-same-way-openssl-genrsa
 	// https://stackoverflow.com/questions/43822945/golang-can-i-create-x509keypair-using-rsa-key
@@ -42,6 +95,42 @@ func generateRsaKeys(bitSize int, expireInYears int, commonName string, organiza
 		},
 	)
 
+	return generateCertificate(&key.PublicKey, key, expireInYears, commonName, organization), string(privateKeyPem)
+}
+
+func generateEcdsaKeys(curve elliptic.Curve, expireInYears int, commonName string, organization string) (string, string) {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	return generateCertificate(&key.PublicKey, key, expireInYears, commonName, organization), getPkcs8PrivateKeyPem(key)
+}
+
+func generateEd25519Keys(expireInYears int, commonName string, organization string) (string, string) {
+	publicKey, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	return generateCertificate(publicKey, key, expireInYears, commonName, organization), getPkcs8PrivateKeyPem(key)
+}
+
+// Encode private key to PKCS#8 ASN.1 PEM.
+func getPkcs8PrivateKeyPem(key interface{}) string {
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: privateKeyBytes,
+	}))
+}
+
+// generateCertificate returns the self-signed certificate of the public key in PEM
+func generateCertificate(publicKey interface{}, privateKey interface{}, expireInYears int, commonName string, organization string) string {
 	tml := x509.Certificate{
 		// you can add any attr that you need
 		NotBefore: time.Now(),
@@ -54,7 +143,7 @@ func generateRsaKeys(bitSize int, expireInYears int, commonName string, organiza
 		},
 		BasicConstraintsValid: true,
 	}
-	cert, err := x509.CreateCertificate(rand.Reader, &tml, &tml, &key.PublicKey, key)
+	cert, err := x509.CreateCertificate(rand.Reader, &tml, &tml, publicKey, privateKey)
 	if err != nil {
 		panic(err)
 	}
@@ -65,5 +154,108 @@ func generateRsaKeys(bitSize int, expireInYears int, commonName string, organiza
 		Bytes: cert,
 	})
 
-	return string(certPem), string(privateKeyPem)
+	return string(certPem)
+}
+
+// getCertPublicKey returns the public key of the cert, which is a certificate or a public key in PEM
+func getCertPublicKey(cert *Cert) (crypto.PublicKey, error) {
+	return parsePublicKeyPem(cert.PublicKey, cert.Name)
+}
+
+func parsePublicKeyPem(publicKeyPem string, certName string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPem))
+	if block == nil {
+		return nil, fmt.Errorf("the public key of the cert: %s is not in PEM", certName)
+	}
+
+	if x509Cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		return x509Cert.PublicKey, nil
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// getCertPrivateKey returns the private key of the cert, which is in PKCS#1 (RSA) or PKCS#8 PEM
+func getCertPrivateKey(cert *Cert) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(cert.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("the private key of the cert: %s is not in PEM", cert.Name)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// getKeySigningAlgorithms returns the JWS algorithms that the key can sign with
+func getKeySigningAlgorithms(key crypto.PublicKey) []string {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return rsaSigningAlgorithms
+	case *ecdsa.PublicKey:
+		for algorithm, curve := range ecdsaCurves {
+			if k.Curve == curve {
+				return []string{algorithm}
+			}
+		}
+	case ed25519.PublicKey:
+		return []string{"EdDSA"}
+	}
+	return []string{}
+}
+
+func canKeySignWith(key crypto.PublicKey, algorithm string) bool {
+	for _, keyAlgorithm := range getKeySigningAlgorithms(key) {
+		if keyAlgorithm == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// isCertKeyValid tells whether the keys of the cert are there and can sign with its crypto algorithm
+func isCertKeyValid(cert *Cert) bool {
+	if cert.PublicKey == "" || cert.PrivateKey == "" {
+		return false
+	}
+
+	publicKey, err := getCertPublicKey(cert)
+	if err != nil {
+		return false
+	}
+
+	cryptoAlgorithm := cert.CryptoAlgorithm
+	if cryptoAlgorithm == "" {
+		cryptoAlgorithm = "RS256"
+	}
+	return canKeySignWith(publicKey, cryptoAlgorithm)
+}
+
+// getSigningAlgorithm returns the algorithm that the application signs its tokens with: its own signing algorithm,
+// or else the crypto algorithm of its cert.
+func getSigningAlgorithm(application *Application, cert *Cert) string {
+	if application.SigningAlgorithm != "" {
+		return application.SigningAlgorithm
+	}
+	if cert.CryptoAlgorithm != "" {
+		return cert.CryptoAlgorithm
+	}
+	return "RS256"
+}
+
+func getSigningMethod(algorithm string) (jwt.SigningMethod, error) {
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("error: the signing algorithm: %s is not supported", algorithm)
+	}
+
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+		return method, nil
+	default:
+		return nil, errors.New("error: only asymmetric signing algorithms are supported for the tokens")
+	}
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/casdoor/casdoor/util"
+	"github.com/golang-jwt/jwt/v4"
 )
 
 func TestGenerateRsaKeys(t *testing.T) {
@@ -31,3 +32,36 @@ func TestGenerateRsaKeys(t *testing.T) {
 	// Write private key to file.
 	util.WriteStringToPath(privateKey, fmt.Sprintf("%s.key", fileId))
 }
+
+func TestGenerateKeys(t *testing.T) {
+	for _, cryptoAlgorithm := range []string{"RS256", "ES256", "ES384", "ES512", "EdDSA"} {
+		publicKey, privateKey, err := generateKeys(cryptoAlgorithm, 2048, 20, "Casdoor Cert", "Casdoor Organization")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cert := &Cert{Name: "cert", CryptoAlgorithm: cryptoAlgorithm, PublicKey: publicKey, PrivateKey: privateKey}
+		if !isCertKeyValid(cert) {
+			t.Errorf("the keys of %s can't sign with it", cryptoAlgorithm)
+		}
+
+		key, err := getCertPrivateKey(cert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		method, err := getSigningMethod(cryptoAlgorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := jwt.NewWithClaims(method, jwt.RegisteredClaims{Subject: "admin"}).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		claims := jwt.RegisteredClaims{}
+		_, err = parseJwtClaims(token, &claims, cert)
+		if err != nil || claims.Subject != "admin" {
+			t.Errorf("failed to verify the token signed with %s: %v", cryptoAlgorithm, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/casdoor/casdoor/util"
+	"xorm.io/core"
+)
+
+// In "Opaque" token format, the application receives random reference tokens instead of JWTs.
+// The Token row keeps the JWTs to hold the claims of the tokens, and the hashes of the opaque tokens to look them up.
+// The JWTs are never handed out, and the opaque tokens themselves are not stored.
+const OpaqueTokenFormat = "Opaque"
+
+func getOpaqueTokenHash(tokenValue string) string {
+	sum := sha256.Sum256([]byte(tokenValue))
+	return hex.EncodeToString(sum[:])
+}
+
+// getTokenValueCondition matches a token value with a token column: a JWT by itself, an opaque token by its hash.
+// The JWT that an opaque token refers to is not accepted by itself.
+func getTokenValueCondition(column string) string {
+	return fmt.Sprintf("((%[1]s = ? and (%[1]s_hash is null or %[1]s_hash = '')) or %[1]s_hash = ?)", column)
+}
+
+// GetJwtToken returns the JWT that holds the claims of the token value, which is the access token
+// or the refresh token of the Token row
+func (token *Token) GetJwtToken(tokenValue string) string {
+	tokenHash := getOpaqueTokenHash(tokenValue)
+	if token.AccessTokenHash == tokenHash {
+		return token.AccessToken
+	}
+	if token.RefreshTokenHash == tokenHash {
+		return token.RefreshToken
+	}
+	return tokenValue
+}
+
+// issueOpaqueToken replaces the access token and the refresh token of the token with opaque tokens
+// if the application is in "Opaque" token format. It is done when the tokens are handed out to the application,
+// the at_hash of the ID token is updated to the opaque access token.
+func issueOpaqueToken(application *Application, token *Token) error {
+	if application.TokenFormat != OpaqueTokenFormat || token.AccessTokenHash != "" {
+		return nil
+	}
+
+	accessToken := util.GenerateOpaqueToken()
+	idToken := token.IdToken
+	if idToken != "" {
+		var err error
+		idToken, err = getIdTokenWithAtHash(application, idToken, accessToken)
+		if err != nil {
+			return err
+		}
+	}
+
+	refreshToken := ""
+	token.RefreshTokenHash = ""
+	if token.RefreshToken != "" {
+		refreshToken = util.GenerateOpaqueToken()
+		token.RefreshTokenHash = getOpaqueTokenHash(refreshToken)
+	}
+	token.AccessTokenHash = getOpaqueTokenHash(accessToken)
+	token.IdToken = idToken
+	_, err := adapter.Engine.ID(core.PK{token.Owner, token.Name}).Cols("access_token_hash", "refresh_token_hash", "id_token").Update(token)
+	if err != nil {
+		panic(err)
+	}
+
+	token.AccessToken = accessToken
+	token.RefreshToken = refreshToken
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/casdoor/casdoor/util"
+	"xorm.io/core"
+)
+
+// Pushed Authorization Requests, per rfc 9126
+// https://datatracker.ietf.org/doc/html/rfc9126
+const (
+	RequestUriPrefix = "urn:ietf:params:oauth:request_uri:"
+
+	parExpireInSeconds = 60
+)
+
+type PushedAuthRequest struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Application string `xorm:"varchar(100)" json:"application"`
+	RequestUri  string `xorm:"varchar(200) index" json:"requestUri"`
+	Parameters  string `xorm:"mediumtext" json:"parameters"`
+	ExpireIn    int64  `json:"expireIn"`
+	IsUsed      bool   `json:"isUsed"`
+}
+
+type PushedAuthResponse struct {
+	RequestUri string `json:"request_uri"`
+	ExpiresIn  int    `json:"expires_in"`
+}
+
+func getPushedAuthRequestByRequestUri(requestUri string) *PushedAuthRequest {
+	if !strings.HasPrefix(requestUri, RequestUriPrefix) {
+		return nil
+	}
+
+	pushedAuthRequest := PushedAuthRequest{RequestUri: requestUri}
+	existed, err := adapter.Engine.Get(&pushedAuthRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	if existed {
+		return &pushedAuthRequest
+	}
+
+	return nil
+}
+
+func AddPushedAuthRequest(pushedAuthRequest *PushedAuthRequest) bool {
+	affected, err := adapter.Engine.Insert(pushedAuthRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// usePushedAuthRequest makes the request_uri one-time use, even if several requests race.
+func usePushedAuthRequest(pushedAuthRequest *PushedAuthRequest) bool {
+	pushedAuthRequest.IsUsed = true
+	affected, err := adapter.Engine.ID(core.PK{pushedAuthRequest.Owner, pushedAuthRequest.Name}).Where("is_used = ?", false).Cols("is_used").Update(pushedAuthRequest)
+	if err != nil {
+		panic(err)
+	}
+
+	return affected != 0
+}
+
+// getPushedAuthRequestParameters returns the authorization request parameters that the client
+// pushed for the request_uri, they take the place of the ones on the query string.
+func getPushedAuthRequestParameters(clientId string, requestUri string) (*PushedAuthRequest, url.Values, error) {
+	pushedAuthRequest := getPushedAuthRequestByRequestUri(requestUri)
+	if pushedAuthRequest == nil || pushedAuthRequest.IsUsed {
+		return nil, nil, errors.New("error: invalid request_uri")
+	}
+	if time.Now().Unix() > pushedAuthRequest.ExpireIn {
+		return nil, nil, errors.New("error: the request_uri has expired")
+	}
+
+	params, err := url.ParseQuery(pushedAuthRequest.Parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+	if params.Get("client_id") != clientId {
+		return nil, nil, errors.New("error: the request_uri is for wrong application (client_id)")
+	}
+
+	return pushedAuthRequest, params, nil
+}
+
+// GetPushedAuthRequestParameters returns the pushed parameters of the request_uri as a flat map,
+// so that the login page can render the authorization request it stands for.
+func GetPushedAuthRequestParameters(clientId string, requestUri string) (map[string]string, error) {
+	_, params, err := getPushedAuthRequestParameters(clientId, requestUri)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]string{}
+	for key := range params {
+		res[key] = params.Get(key)
+	}
+	return res, nil
+}
+
+// checkPushedAuthRequest validates the pushed parameters of an authorization request of the application.
+// A public client can't authenticate, so it has to prove with PKCE that it is the one that redeems the code.
+func checkPushedAuthRequest(application *Application, params url.Values) *TokenError {
+	if params.Get("request_uri") != "" {
+		return &TokenError{Error: "invalid_request", ErrorDescription: "the request_uri parameter is not allowed in a pushed authorization request"}
+	}
+
+	challengeMethod := params.Get("code_challenge_method")
+	if challengeMethod != "S256" && challengeMethod != "" {
+		return &TokenError{Error: "invalid_request", ErrorDescription: "Challenge method should be S256"}
+	}
+	if application.IsPublicClient() && params.Get("code_challenge") == "" {
+		return &TokenError{Error: "invalid_request", ErrorDescription: "code_challenge is required for public clients"}
+	}
+
+	msg := checkAuthorizationRequest(application, params.Get("response_type"), params.Get("redirect_uri"), params.Get("scope"), params.Get("state"))
+	if msg != "" {
+		return &TokenError{Error: "invalid_request", ErrorDescription: msg}
+	}
+	return nil
+}
+
+// PushAuthRequest stores the parameters of an authorization request of the client, which authenticates
+// like it does at the token endpoint, and returns the request_uri that stands for them
+func PushAuthRequest(clientId string, clientSecret string, clientAssertionType string, clientAssertion string, host string, params url.Values) (*PushedAuthResponse, *TokenError) {
+	application, err := AuthenticateClient(clientId, clientSecret, clientAssertionType, clientAssertion, host)
+	if err != nil {
+		return nil, &TokenError{Error: "invalid_client", ErrorDescription: err.Error()}
+	}
+
+	tokenError := checkPushedAuthRequest(application, params)
+	if tokenError != nil {
+		return nil, tokenError
+	}
+
+	// the client may be identified by its client_assertion only
+	params.Set("client_id", application.ClientId)
+	params.Del("client_secret")
+	params.Del("client_assertion_type")
+	params.Del("client_assertion")
+
+	pushedAuthRequest := &PushedAuthRequest{
+		Owner:       application.Owner,
+		Name:        util.GenerateId(),
+		CreatedTime: util.GetCurrentTime(),
+		Application: application.Name,
+		RequestUri:  RequestUriPrefix + util.GenerateClientSecret(),
+		Parameters:  params.Encode(),
+		ExpireIn:    time.Now().Add(time.Second * parExpireInSeconds).Unix(),
+	}
+	AddPushedAuthRequest(pushedAuthRequest)
+
+	return &PushedAuthResponse{
+		RequestUri: pushedAuthRequest.RequestUri,
+		ExpiresIn:  parExpireInSeconds,
+	}, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCheckPushedAuthRequest(t *testing.T) {
+	confidential := &Application{Name: "app-confidential", RedirectUris: []string{"https://client.example.com/callback"}}
+	public := &Application{Name: "app-public", RedirectUris: []string{"https://client.example.com/callback"}, TokenEndpointAuthMethod: ClientAuthMethodNone}
+
+	tests := []struct {
+		name        string
+		application *Application
+		params      url.Values
+		valid       bool
+	}{
+		{"confidential client", confidential, url.Values{"response_type": {"code"}, "redirect_uri": {"https://client.example.com/callback"}}, true},
+		{"public client with PKCE", public, url.Values{"response_type": {"code"}, "redirect_uri": {"https://client.example.com/callback"}, "code_challenge": {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"}, "code_challenge_method": {"S256"}}, true},
+		{"public client without PKCE", public, url.Values{"response_type": {"code"}, "redirect_uri": {"https://client.example.com/callback"}}, false},
+		{"plain challenge", confidential, url.Values{"response_type": {"code"}, "redirect_uri": {"https://client.example.com/callback"}, "code_challenge": {"verifier"}, "code_challenge_method": {"plain"}}, false},
+		{"nested request_uri", confidential, url.Values{"response_type": {"code"}, "redirect_uri": {"https://client.example.com/callback"}, "request_uri": {RequestUriPrefix + "abc"}}, false},
+		{"unregistered redirect URI", confidential, url.Values{"response_type": {"code"}, "redirect_uri": {"https://attacker.example.com/callback"}}, false},
+	}
+	for _, test := range tests {
+		tokenError := checkPushedAuthRequest(test.application, test.params)
+		if (tokenError == nil) != test.valid {
+			t.Errorf("%s: checkPushedAuthRequest() = %v", test.name, tokenError)
+		}
+		if tokenError != nil && tokenError.Error != "invalid_request" {
+			t.Errorf("%s: the error is %s", test.name, tokenError.Error)
+		}
+	}
+}
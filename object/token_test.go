@@ -0,0 +1,200 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/casdoor/casdoor/util"
+)
+
+const testHost = "door.casdoor.com"
+
+// addTestApplication adds the application to the built-in organization of the database, along with a cert
+// of its own, and removes them with their tokens at the end of the test
+func addTestApplication(t *testing.T, application *Application) *Application {
+	InitConfig()
+	InitDb()
+
+	id := util.GenerateClientId()
+	cert := &Cert{
+		Owner:           "admin",
+		Name:            fmt.Sprintf("cert-test-%s", id),
+		CreatedTime:     util.GetCurrentTime(),
+		Scope:           "JWT",
+		Type:            "x509",
+		CryptoAlgorithm: "RS256",
+		BitSize:         2048,
+		ExpireInYears:   1,
+	}
+	AddCert(cert)
+
+	application.Owner = "admin"
+	application.Name = fmt.Sprintf("app-test-%s", id)
+	application.CreatedTime = util.GetCurrentTime()
+	application.Organization = "built-in"
+	application.Cert = cert.Name
+	application.TokenFormat = "JWT"
+	application.ExpireInHours = 1
+	application.RefreshExpireInHours = 1
+	if application.Providers == nil {
+		application.Providers = []*ProviderItem{}
+	}
+	if application.SignupItems == nil {
+		application.SignupItems = []*SignupItem{}
+	}
+	AddApplication(application)
+
+	t.Cleanup(func() {
+		_, err := adapter.Engine.Where("owner = ? and application = ?", application.Owner, application.Name).Delete(&Token{})
+		if err != nil {
+			panic(err)
+		}
+		DeleteApplication(application)
+		DeleteCert(cert)
+	})
+	return application
+}
+
+func getTestUser(t *testing.T) *User {
+	user := getUser("built-in", "admin")
+	if user == nil {
+		t.Fatal("the built-in user doesn't exist")
+	}
+	return user
+}
+
+func addTestGrant(t *testing.T, application *Application, user *User) *Token {
+	token, err := addOAuthCodeToken(application, user, &AuthorizationRequest{Scope: "openid"}, testHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func refreshTestToken(application *Application, refreshToken string) *TokenWrapper {
+	return RefreshToken("refresh_token", refreshToken, "openid", application.ClientId, application.ClientSecret, testHost, "", "", "", nil)
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	application := addTestApplication(t, &Application{
+		GrantTypes:                 []string{"authorization_code", "refresh_token"},
+		EnableRefreshTokenRotation: true,
+	})
+	grant := addTestGrant(t, application, getTestUser(t))
+
+	refreshed := refreshTestToken(application, grant.RefreshToken)
+	if refreshed.Error != "" {
+		t.Fatalf("failed to refresh the token: %s", refreshed.Error)
+	}
+	if refreshed.RefreshToken == grant.RefreshToken {
+		t.Errorf("the refresh token hasn't been rotated")
+	}
+
+	// the rotated-out refresh token is kept to detect its reuse, without its access token
+	rotated := getTokenByRefreshToken(grant.RefreshToken)
+	if rotated == nil || !rotated.RefreshTokenRotated || rotated.AccessToken != "" {
+		t.Errorf("the rotated-out token is %#v", rotated)
+	}
+
+	newToken := getTokenByRefreshToken(refreshed.RefreshToken)
+	if newToken == nil || newToken.Family != grant.Name {
+		t.Fatalf("the refreshed token is %#v", newToken)
+	}
+
+	// the family follows the first grant through the later rotations
+	refreshedAgain := refreshTestToken(application, refreshed.RefreshToken)
+	if refreshedAgain.Error != "" {
+		t.Fatalf("failed to refresh the token again: %s", refreshedAgain.Error)
+	}
+	if token := getTokenByRefreshToken(refreshedAgain.RefreshToken); token == nil || token.Family != grant.Name {
+		t.Errorf("the token refreshed again is %#v", token)
+	}
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	application := addTestApplication(t, &Application{
+		GrantTypes:                 []string{"authorization_code", "refresh_token"},
+		EnableRefreshTokenRotation: true,
+	})
+	user := getTestUser(t)
+	grant := addTestGrant(t, application, user)
+	otherGrant := addTestGrant(t, application, user)
+
+	refreshed := refreshTestToken(application, grant.RefreshToken)
+	if refreshed.Error != "" {
+		t.Fatalf("failed to refresh the token: %s", refreshed.Error)
+	}
+
+	// the replayed refresh token revokes all the tokens of its grant
+	replayed := refreshTestToken(application, grant.RefreshToken)
+	if replayed.Error == "" {
+		t.Fatalf("the replayed refresh token is accepted")
+	}
+	if getTokenByRefreshToken(grant.RefreshToken) != nil || getTokenByRefreshToken(refreshed.RefreshToken) != nil {
+		t.Errorf("the tokens of the replayed grant haven't been revoked")
+	}
+	if retried := refreshTestToken(application, refreshed.RefreshToken); retried.Error == "" {
+		t.Errorf("the refresh token of the revoked grant is still accepted")
+	}
+
+	// the other sessions of the user stay signed in
+	if getTokenByRefreshToken(otherGrant.RefreshToken) == nil {
+		t.Errorf("the other grant of the user has been revoked")
+	}
+	if other := refreshTestToken(application, otherGrant.RefreshToken); other.Error != "" {
+		t.Errorf("failed to refresh the other grant: %s", other.Error)
+	}
+}
+
+// TestGetOAuthCodeOfOtt signs in to an SSO client the way the OTT login does, with the optional redirect_uri
+// of the form and without a consent page
+func TestGetOAuthCodeOfOtt(t *testing.T) {
+	application := addTestApplication(t, &Application{
+		GrantTypes:   []string{"authorization_code"},
+		RedirectUris: []string{"https://wallet.example.com/callback"},
+	})
+	user := getTestUser(t)
+	t.Cleanup(func() {
+		if appSession := getAppSession(application, user); appSession != nil {
+			deleteAppSession(appSession)
+		}
+	})
+
+	getCode := func(redirectUri string) *Code {
+		request := &AuthorizationRequest{
+			ResponseType: "code",
+			RedirectUri:  redirectUri,
+			Scope:        "read",
+			State:        "lw",
+			FirstParty:   true,
+		}
+		return GetOAuthCode(user.GetId(), application.ClientId, request, testHost, "")
+	}
+
+	// the OTT login hands the code back in its response, so the clients that send no redirect_uri get the code,
+	// while the redirect_uri that a client sends has to match
+	for _, redirectUri := range []string{"", "https://wallet.example.com/callback"} {
+		if code := getCode(redirectUri); code.Code == "" {
+			t.Errorf("failed to get the code for the redirect_uri: \"%s\": %s", redirectUri, code.Message)
+		}
+	}
+	for _, redirectUri := range []string{"https://wallet.example.com/callback.evil.com", "https://evil.com/?https://wallet.example.com/callback"} {
+		if code := getCode(redirectUri); code.Code != "" {
+			t.Errorf("got the code for the redirect_uri: \"%s\"", redirectUri)
+		}
+	}
+}
@@ -18,7 +18,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/casdoor/casdoor/conf"
 	"github.com/casdoor/casdoor/util"
 	"xorm.io/core"
 )
@@ -41,6 +40,7 @@ type User struct {
 	Email             string   `xorm:"varchar(100) index" json:"email"`
 	EmailVerified     bool     `json:"emailVerified"`
 	Phone             string   `xorm:"varchar(100) index" json:"phone"`
+	PhoneVerified     bool     `json:"phoneVerified"`
 	Location          string   `xorm:"varchar(100)" json:"location"`
 	Address           []string `json:"address"`
 	Affiliation       string   `xorm:"varchar(100)" json:"affiliation"`
@@ -104,15 +104,11 @@ type User struct {
 }
 
 type Userinfo struct {
-	Sub         string `json:"sub"`
-	Iss         string `json:"iss"`
-	Aud         string `json:"aud"`
-	Name        string `json:"name,omitempty"`
-	DisplayName string `json:"preferred_username,omitempty"`
-	Email       string `json:"email,omitempty"`
-	Avatar      string `json:"picture,omitempty"`
-	Address     string `json:"address,omitempty"`
-	Phone       string `json:"phone,omitempty"`
+	Sub string `json:"sub"`
+	Iss string `json:"iss"`
+	Aud string `json:"aud"`
+
+	UserClaims
 }
 
 func GetGlobalUserCount(field, value string) int {
@@ -331,7 +327,11 @@ func UpdateUser(id string, user *User, columns []string, isGlobalAdmin bool) boo
 			"is_admin", "is_global_admin", "is_forbidden", "is_deleted", "hash", "is_default_avatar", "properties"}
 	}
 	if isGlobalAdmin {
-		columns = append(columns, "name", "email", "phone")
+		// the phone that the administrator changes hasn't been verified by the user
+		if user.Phone != oldUser.Phone {
+			user.PhoneVerified = false
+		}
+		columns = append(columns, "name", "email", "phone", "phone_verified")
 	}
 
 	affected, err := adapter.Engine.ID(core.PK{owner, name}).Cols(columns...).Update(user)
@@ -451,37 +451,33 @@ func DeleteUser(user *User) bool {
 	return affected != 0
 }
 
-func GetUserInfo(userId string, scope string, aud string, host string) (*Userinfo, error) {
+// GetUserInfo returns the standard claims about the user for the scope, along with the claims that the claim rules
+// of the application (aud) release.
+func GetUserInfo(userId string, scope string, aud string, host string) (map[string]interface{}, error) {
 	user := GetUser(userId)
 	if user == nil {
 		return nil, fmt.Errorf("the user: %s doesn't exist", userId)
 	}
-	origin := conf.GetConfigString("origin")
-	_, originBackend := getOriginFromHost(host)
-	if origin != "" {
-		originBackend = origin
-	}
 
+	_, originBackend := getOrigin(host)
 	resp := Userinfo{
 		Sub: user.Id,
 		Iss: originBackend,
 		Aud: aud,
 	}
-	if strings.Contains(scope, "profile") {
-		resp.Name = user.Name
-		resp.DisplayName = user.DisplayName
-		resp.Avatar = user.Avatar
-	}
-	if strings.Contains(scope, "email") {
-		resp.Email = user.Email
-	}
-	if strings.Contains(scope, "address") {
-		resp.Address = user.Location
-	}
-	if strings.Contains(scope, "phone") {
-		resp.Phone = user.Phone
+	setUserClaims(&resp.UserClaims, user, scope)
+
+	releasedClaims := map[string]interface{}{}
+	application := GetApplicationByClientId(aud)
+	if application != nil {
+		resp.Iss = getJwtIssuer(application, host)
+		releasedClaims = getReleasedClaims(application, user, scope)
+		err := addCustomClaims(releasedClaims, application, user, scope, ClaimTargetUserinfo)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return &resp, nil
+	return addReleasedClaims(resp, releasedClaims)
 }
 
 func LinkUserAccount(user *User, field string, value string) bool {
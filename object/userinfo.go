@@ -0,0 +1,182 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gopkg.in/square/go-jose.v2"
+)
+
+// The userinfo response is plain JSON, unless the application asks for it signed, encrypted, or both.
+// The signed response is signed with the cert of the application, and the encrypted one is encrypted
+// with the encryption key of the application, from its JWKS or its public key.
+// https://openid.net/specs/openid-connect-core-1_0.html#UserInfoResponse
+var (
+	userinfoEncryptionAlgorithms = []string{"RSA-OAEP", "RSA-OAEP-256", "ECDH-ES", "ECDH-ES+A128KW", "ECDH-ES+A192KW", "ECDH-ES+A256KW"}
+	userinfoEncryptionEncodings  = []string{"A128CBC-HS256", "A192CBC-HS384", "A256CBC-HS512", "A128GCM", "A192GCM", "A256GCM"}
+)
+
+// the default content encryption of the encrypted responses, per OpenID Connect Dynamic Client Registration section 2
+const defaultUserinfoEncryptionEncoding = "A128CBC-HS256"
+
+// HasJwtUserinfo tells whether the application asks for the userinfo responses in a JWT instead of plain JSON
+func (application *Application) HasJwtUserinfo() bool {
+	return application.UserinfoSignedResponseAlg != "" || application.UserinfoEncryptedResponseAlg != ""
+}
+
+func isUserinfoAlgorithmSupported(algorithms []string, algorithm string) bool {
+	for _, a := range algorithms {
+		if a == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckUserinfoResponse returns an error if the application asks for a userinfo response that can't be made
+func (application *Application) CheckUserinfoResponse() error {
+	if application.UserinfoSignedResponseAlg != "" {
+		cert := getCertByApplication(application)
+		if cert == nil {
+			return fmt.Errorf("the application: %s has no cert to sign the userinfo with", application.Name)
+		}
+		publicKey, err := getCertPublicKey(cert)
+		if err != nil {
+			return err
+		}
+		if !canKeySignWith(publicKey, application.UserinfoSignedResponseAlg) {
+			return fmt.Errorf("the cert: %s can't sign the userinfo with the algorithm: %s", cert.Name, application.UserinfoSignedResponseAlg)
+		}
+	}
+
+	if application.UserinfoEncryptedResponseAlg == "" {
+		if application.UserinfoEncryptedResponseEnc != "" {
+			return errors.New("the userinfo encryption needs an algorithm for its content encryption")
+		}
+		return nil
+	}
+	if !isUserinfoAlgorithmSupported(userinfoEncryptionAlgorithms, application.UserinfoEncryptedResponseAlg) {
+		return fmt.Errorf("the userinfo encryption algorithm: %s is not supported", application.UserinfoEncryptedResponseAlg)
+	}
+	if application.UserinfoEncryptedResponseEnc != "" && !isUserinfoAlgorithmSupported(userinfoEncryptionEncodings, application.UserinfoEncryptedResponseEnc) {
+		return fmt.Errorf("the userinfo content encryption: %s is not supported", application.UserinfoEncryptedResponseEnc)
+	}
+	_, _, err := getClientEncryptionKey(application, application.UserinfoEncryptedResponseAlg)
+	return err
+}
+
+// getClientEncryptionKey returns the key of the application that the responses are encrypted with, and its kid:
+// the first key in the JWKS of the application for encryption that fits the algorithm, or else its public key
+func getClientEncryptionKey(application *Application, algorithm string) (interface{}, string, error) {
+	isRsa := strings.HasPrefix(algorithm, "RSA")
+	fits := func(key interface{}) bool {
+		switch key.(type) {
+		case *rsa.PublicKey:
+			return isRsa
+		case *ecdsa.PublicKey:
+			return !isRsa
+		}
+		return false
+	}
+
+	if application.ClientJwks != "" {
+		jwks := jose.JSONWebKeySet{}
+		err := json.Unmarshal([]byte(application.ClientJwks), &jwks)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, key := range jwks.Keys {
+			if key.Use != "sig" && fits(key.Public().Key) {
+				return key.Public().Key, key.KeyID, nil
+			}
+		}
+		return nil, "", fmt.Errorf("the JWKS of the application has no key to encrypt with the algorithm: %s", algorithm)
+	}
+
+	if application.ClientPublicKey != "" {
+		var key interface{}
+		var err error
+		if isRsa {
+			key, err = jwt.ParseRSAPublicKeyFromPEM([]byte(application.ClientPublicKey))
+		} else {
+			key, err = jwt.ParseECPublicKeyFromPEM([]byte(application.ClientPublicKey))
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("the public key of the application can't encrypt with the algorithm: %s", algorithm)
+		}
+		return key, "", nil
+	}
+
+	return nil, "", errors.New("the application has neither a JWKS nor a public key to encrypt with")
+}
+
+// encryptForClient encrypts the payload into a JWE in compact serialization for the application,
+// cty is "JWT" when the payload is a signed JWT
+func encryptForClient(application *Application, payload []byte, cty string) (string, error) {
+	algorithm := application.UserinfoEncryptedResponseAlg
+	encoding := application.UserinfoEncryptedResponseEnc
+	if encoding == "" {
+		encoding = defaultUserinfoEncryptionEncoding
+	}
+
+	key, kid, err := getClientEncryptionKey(application, algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	options := (&jose.EncrypterOptions{}).WithType("JWT")
+	if cty != "" {
+		options = options.WithContentType(jose.ContentType(cty))
+	}
+	encrypter, err := jose.NewEncrypter(jose.ContentEncryption(encoding), jose.Recipient{Algorithm: jose.KeyAlgorithm(algorithm), Key: key, KeyID: kid}, options)
+	if err != nil {
+		return "", err
+	}
+
+	jwe, err := encrypter.Encrypt(payload)
+	if err != nil {
+		return "", err
+	}
+	return jwe.CompactSerialize()
+}
+
+// GetUserinfoJwt returns the userinfo in the JWT that the application asks for: a JWS, a JWE of the claims,
+// or a JWE of the JWS if it asks for both
+func GetUserinfoJwt(application *Application, userinfo map[string]interface{}) (string, error) {
+	if application.UserinfoSignedResponseAlg != "" {
+		token, err := signJwtClaimsWithAlgorithm(application, jwt.MapClaims(userinfo), "", application.UserinfoSignedResponseAlg)
+		if err != nil {
+			return "", err
+		}
+		if application.UserinfoEncryptedResponseAlg == "" {
+			return token, nil
+		}
+		return encryptForClient(application, []byte(token), "JWT")
+	}
+
+	payload, err := json.Marshal(userinfo)
+	if err != nil {
+		return "", err
+	}
+	return encryptForClient(application, payload, "")
+}
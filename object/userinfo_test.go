@@ -0,0 +1,119 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+func TestGetUserinfoJwtEncrypted(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &privateKey.PublicKey, KeyID: "enc-1", Use: "enc"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	application := &Application{ClientJwks: string(jwks), UserinfoEncryptedResponseAlg: "RSA-OAEP-256"}
+	if err = application.CheckUserinfoResponse(); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := GetUserinfoJwt(application, map[string]interface{}{"sub": "123", "email": "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwe, err := jose.ParseEncrypted(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jwe.Header.KeyID != "enc-1" || jwe.Header.ExtraHeaders["enc"] != defaultUserinfoEncryptionEncoding {
+		t.Errorf("header = %v", jwe.Header)
+	}
+	payload, err := jwe.Decrypt(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := map[string]interface{}{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims["sub"] != "123" || claims["email"] != "alice@example.com" {
+		t.Errorf("claims = %v", claims)
+	}
+}
+
+func TestCheckUserinfoResponse(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &ecKey.PublicKey, Use: "enc"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		alg   string
+		enc   string
+		valid bool
+	}{
+		{"ECDH-ES+A128KW", "", true},
+		{"ECDH-ES", "A256GCM", true},
+		{"RSA-OAEP", "", false},
+		{"dir", "", false},
+		{"ECDH-ES", "A512GCM", false},
+		{"", "A128GCM", false},
+	}
+	for _, test := range tests {
+		application := &Application{ClientJwks: string(jwks), UserinfoEncryptedResponseAlg: test.alg, UserinfoEncryptedResponseEnc: test.enc}
+		err := application.CheckUserinfoResponse()
+		if (err == nil) != test.valid {
+			t.Errorf("CheckUserinfoResponse(%s, %s) = %v", test.alg, test.enc, err)
+		}
+	}
+}
+
+func TestSetUserClaimsOfPhone(t *testing.T) {
+	claims := &UserClaims{}
+	setUserClaims(claims, &User{Phone: "13800000000", PhoneVerified: true}, "openid phone")
+	if claims.PhoneNumber != "13800000000" || claims.PhoneNumberVerified == nil || !*claims.PhoneNumberVerified {
+		t.Errorf("the claims of the verified phone are %v, %v", claims.PhoneNumber, claims.PhoneNumberVerified)
+	}
+
+	claims = &UserClaims{}
+	setUserClaims(claims, &User{Phone: "13800000000"}, "openid phone")
+	if claims.PhoneNumberVerified == nil || *claims.PhoneNumberVerified {
+		t.Errorf("the phone that isn't verified is claimed verified")
+	}
+
+	// phone_number_verified is left out along with the phone_number of the user without a phone
+	claims = &UserClaims{}
+	setUserClaims(claims, &User{}, "openid phone")
+	if claims.PhoneNumber != "" || claims.PhoneNumberVerified != nil {
+		t.Errorf("the claims of the user without a phone are %v, %v", claims.PhoneNumber, claims.PhoneNumberVerified)
+	}
+}
@@ -109,6 +109,14 @@ func getUrlPath(urlPath string) string {
 		return "/api/login/oauth"
 	}
 
+	// the metadata of the organizations that have their own issuers
+	if strings.HasPrefix(urlPath, "/organizations/") && strings.HasSuffix(urlPath, "/.well-known/openid-configuration") {
+		return "/.well-known/openid-configuration"
+	}
+	if strings.HasPrefix(urlPath, "/.well-known/oauth-authorization-server/") {
+		return "/.well-known/oauth-authorization-server"
+	}
+
 	return urlPath
 }
 
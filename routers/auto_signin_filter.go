@@ -16,6 +16,7 @@ package routers
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/astaxie/beego/context"
 	"github.com/casdoor/casdoor/object"
@@ -28,8 +29,10 @@ func AutoSigninFilter(ctx *context.Context) {
 	//}
 
 	// GET parameter like "/page?access_token=123" or
-	// HTTP Bearer token like "Authorization: Bearer 123"
-	accessToken := util.GetMaxLenStr(ctx.Input.Query("accessToken"), ctx.Input.Query("access_token"), parseBearerToken(ctx))
+	// HTTP Bearer token like "Authorization: Bearer 123" or
+	// DPoP-bound token like "Authorization: DPoP 123"
+	dpopToken := parseDpopToken(ctx)
+	accessToken := util.GetMaxLenStr(ctx.Input.Query("accessToken"), ctx.Input.Query("access_token"), parseBearerToken(ctx), dpopToken)
 
 	if accessToken != "" {
 		token := object.GetTokenByAccessToken(accessToken)
@@ -45,6 +48,33 @@ func AutoSigninFilter(ctx *context.Context) {
 
 		userId := fmt.Sprintf("%s/%s", token.Organization, token.User)
 		application, _ := object.GetApplicationByUserId(fmt.Sprintf("app/%s", token.Application))
+
+		if token.DpopJkt != "" {
+			if dpopToken != accessToken {
+				responseError(ctx, "DPoP-bound access token should be sent with the DPoP authorization scheme")
+				return
+			}
+
+			uri := object.GetDpopRequestUri(ctx.Request.Host, ctx.Request.URL.Path)
+			jkt, err := object.CheckDpopProof(ctx.Request.Header.Get("DPoP"), ctx.Request.Method, uri, accessToken, application.RequireDpopNonce)
+			if err == object.ErrUseDpopNonce {
+				ctx.Output.Header("DPoP-Nonce", object.GetDpopNonce())
+				ctx.Output.Header("WWW-Authenticate", fmt.Sprintf("%s error=\"%s\"", object.DpopTokenType, err.Error()))
+				ctx.Output.SetStatus(http.StatusUnauthorized)
+			}
+			if err != nil {
+				responseError(ctx, err.Error())
+				return
+			}
+			if jkt != token.DpopJkt {
+				responseError(ctx, "The DPoP proof is not signed by the key that the access token is bound to")
+				return
+			}
+		} else if dpopToken == accessToken {
+			responseError(ctx, "Access token is not bound to a DPoP key")
+			return
+		}
+
 		setSessionUser(ctx, userId)
 		setSessionOidc(ctx, token.Scope, application.ClientId)
 		return
@@ -110,6 +110,15 @@ func setSessionOidc(ctx *context.Context, scope string, aud string) {
 }
 
 func parseBearerToken(ctx *context.Context) string {
+	return parseAuthorizationToken(ctx, "Bearer")
+}
+
+// parseDpopToken returns the access token sent like "Authorization: DPoP 123", per rfc 9449 section 7.1
+func parseDpopToken(ctx *context.Context) string {
+	return parseAuthorizationToken(ctx, object.DpopTokenType)
+}
+
+func parseAuthorizationToken(ctx *context.Context, scheme string) string {
 	header := ctx.Request.Header.Get("Authorization")
 	tokens := strings.Split(header, " ")
 	if len(tokens) != 2 {
@@ -117,7 +126,7 @@ func parseBearerToken(ctx *context.Context) string {
 	}
 
 	prefix := tokens[0]
-	if prefix != "Bearer" {
+	if prefix != scheme {
 		return ""
 	}
 
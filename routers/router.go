@@ -52,6 +52,7 @@ func initAPI() {
 	beego.Router("/api/ott/reset-pwd", &controllers.ApiController{}, "POST:OTTResetPassword")
 	beego.Router("/api/get-app-login", &controllers.ApiController{}, "GET:GetApplicationLogin")
 	beego.Router("/api/logout", &controllers.ApiController{}, "POST:Logout")
+	beego.Router("/api/get-frontchannel-logout-uris", &controllers.ApiController{}, "GET:GetFrontchannelLogoutUris")
 	beego.Router("/api/ott/logout", &controllers.ApiController{}, "POST:OTTLogout")
 	beego.Router("/api/get-account", &controllers.ApiController{}, "GET:GetAccount")
 	beego.Router("/api/userinfo", &controllers.ApiController{}, "GET:GetUserinfo")
@@ -88,6 +89,15 @@ func initAPI() {
 	beego.Router("/api/add-permission", &controllers.ApiController{}, "POST:AddPermission")
 	beego.Router("/api/delete-permission", &controllers.ApiController{}, "POST:DeletePermission")
 
+	beego.Router("/api/get-api-resources", &controllers.ApiController{}, "GET:GetApiResources")
+	beego.Router("/api/get-api-resource", &controllers.ApiController{}, "GET:GetApiResource")
+	beego.Router("/api/update-api-resource", &controllers.ApiController{}, "POST:UpdateApiResource")
+	beego.Router("/api/add-api-resource", &controllers.ApiController{}, "POST:AddApiResource")
+	beego.Router("/api/delete-api-resource", &controllers.ApiController{}, "POST:DeleteApiResource")
+
+	beego.Router("/api/get-user-consents", &controllers.ApiController{}, "GET:GetUserConsents")
+	beego.Router("/api/revoke-consent", &controllers.ApiController{}, "POST:RevokeConsent")
+
 	beego.Router("/api/get-models", &controllers.ApiController{}, "GET:GetModels")
 	beego.Router("/api/get-model", &controllers.ApiController{}, "GET:GetModel")
 	beego.Router("/api/update-model", &controllers.ApiController{}, "POST:UpdateModel")
@@ -141,6 +151,15 @@ func initAPI() {
 	beego.Router("/api/login/oauth/refresh_token", &controllers.ApiController{}, "POST:RefreshToken")
 	beego.Router("/api/login/oauth/introspect", &controllers.ApiController{}, "POST:IntrospectToken")
 	beego.Router("/api/login/oauth/logout", &controllers.ApiController{}, "GET:TokenLogout")
+	beego.Router("/api/login/oauth/end_session", &controllers.ApiController{}, "GET:EndSession;POST:EndSession")
+	beego.Router("/api/login/oauth/revoke", &controllers.ApiController{}, "POST:RevokeToken")
+	beego.Router("/api/login/oauth/par", &controllers.ApiController{}, "POST:PushAuthRequest")
+	beego.Router("/api/login/oauth/device_authorization", &controllers.ApiController{}, "POST:DeviceAuthorization")
+	beego.Router("/api/login/oauth/device", &controllers.ApiController{}, "GET:GetDeviceAuth;POST:VerifyDeviceAuth")
+	beego.Router("/api/login/oauth/bc-authorize", &controllers.ApiController{}, "POST:BackchannelAuthorize")
+	beego.Router("/api/login/oauth/ciba", &controllers.ApiController{}, "GET:GetCibaRequests;POST:VerifyCibaRequest")
+	beego.Router("/api/login/oauth/consent", &controllers.ApiController{}, "POST:GrantConsent")
+	beego.Router("/api/oauth/register", &controllers.ApiController{}, "POST:RegisterClient;GET:GetRegisteredClient;PUT:UpdateRegisteredClient;DELETE:DeleteRegisteredClient")
 
 	beego.Router("/api/get-records", &controllers.ApiController{}, "GET:GetRecords")
 	beego.Router("/api/get-records-filter", &controllers.ApiController{}, "POST:GetRecordsByFilter")
@@ -163,6 +182,10 @@ func initAPI() {
 	beego.Router("/api/update-cert", &controllers.ApiController{}, "POST:UpdateCert")
 	beego.Router("/api/add-cert", &controllers.ApiController{}, "POST:AddCert")
 	beego.Router("/api/delete-cert", &controllers.ApiController{}, "POST:DeleteCert")
+	beego.Router("/api/prepare-cert-next-key", &controllers.ApiController{}, "POST:PrepareCertNextKey")
+	beego.Router("/api/rotate-cert", &controllers.ApiController{}, "POST:RotateCert")
+
+	beego.Router("/api/run-janitor", &controllers.ApiController{}, "POST:RunJanitor")
 
 	beego.Router("/api/get-products", &controllers.ApiController{}, "GET:GetProducts")
 	beego.Router("/api/get-product", &controllers.ApiController{}, "GET:GetProduct")
@@ -184,6 +207,9 @@ func initAPI() {
 	beego.Router("/api/send-sms", &controllers.ApiController{}, "POST:SendSms")
 
 	beego.Router("/.well-known/openid-configuration", &controllers.RootController{}, "GET:GetOidcDiscovery")
+	beego.Router("/organizations/:organization/.well-known/openid-configuration", &controllers.RootController{}, "GET:GetOrganizationOidcDiscovery")
+	beego.Router("/.well-known/oauth-authorization-server", &controllers.RootController{}, "GET:GetAuthorizationServerMetadata")
+	beego.Router("/.well-known/oauth-authorization-server/organizations/:organization", &controllers.RootController{}, "GET:GetAuthorizationServerMetadata")
 	beego.Router("/.well-known/jwks", &controllers.RootController{}, "*:GetJwks")
 
 	beego.Router("/cas/:organization/:application/serviceValidate", &controllers.RootController{}, "GET:CasServiceValidate")
@@ -23,3 +23,15 @@ func GenerateClientId() string {
 func GenerateClientSecret() string {
 	return randstr.Hex(20)
 }
+
+// GenerateUserCode returns a short, human-typeable code such as "WDJB-MJHT",
+// using only consonants to avoid ambiguous characters, per rfc 8628 section 6.1
+func GenerateUserCode() string {
+	code := randstr.String(8, "BCDFGHJKLMNPQRSTVWXZ")
+	return code[:4] + "-" + code[4:]
+}
+
+// GenerateOpaqueToken returns a random reference token that carries no information, with 256 bits of entropy
+func GenerateOpaqueToken() string {
+	return randstr.Hex(32)
+}
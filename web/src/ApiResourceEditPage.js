@@ -0,0 +1,206 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Card, Col, Input, Row, Select, Switch} from 'antd';
+import * as ApiResourceBackend from "./backend/ApiResourceBackend";
+import * as OrganizationBackend from "./backend/OrganizationBackend";
+import * as Setting from "./Setting";
+import i18next from "i18next";
+
+const { Option } = Select;
+
+class ApiResourceEditPage extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+      organizationName: props.organizationName !== undefined ? props.organizationName : props.match.params.organizationName,
+      apiResourceName: props.match.params.apiResourceName,
+      apiResource: null,
+      organizations: [],
+      mode: props.location.mode !== undefined ? props.location.mode : "edit",
+    };
+  }
+
+  UNSAFE_componentWillMount() {
+    this.getApiResource();
+    this.getOrganizations();
+  }
+
+  getApiResource() {
+    ApiResourceBackend.getApiResource(this.state.organizationName, this.state.apiResourceName)
+      .then((apiResource) => {
+        this.setState({
+          apiResource: apiResource,
+        });
+      });
+  }
+
+  getOrganizations() {
+    OrganizationBackend.getOrganizations("admin")
+      .then((res) => {
+        this.setState({
+          organizations: (res.msg === undefined) ? res : [],
+        });
+      });
+  }
+
+  parseApiResourceField(key, value) {
+    if ([""].includes(key)) {
+      value = Setting.myParseInt(value);
+    }
+    return value;
+  }
+
+  updateApiResourceField(key, value) {
+    value = this.parseApiResourceField(key, value);
+
+    let apiResource = this.state.apiResource;
+    apiResource[key] = value;
+    this.setState({
+      apiResource: apiResource,
+    });
+  }
+
+  renderApiResource() {
+    return (
+      <Card size="small" title={
+        <div>
+          {this.state.mode === "add" ? i18next.t("apiResource:New API Resource") : i18next.t("apiResource:Edit API Resource")}&nbsp;&nbsp;&nbsp;&nbsp;
+          <Button onClick={() => this.submitApiResourceEdit(false)}>{i18next.t("general:Save")}</Button>
+          <Button style={{marginLeft: '20px'}} type="primary" onClick={() => this.submitApiResourceEdit(true)}>{i18next.t("general:Save & Exit")}</Button>
+          {this.state.mode === "add" ? <Button style={{marginLeft: '20px'}} onClick={() => this.deleteApiResource()}>{i18next.t("general:Cancel")}</Button> : null}
+        </div>
+      } style={(Setting.isMobile())? {margin: '5px'}:{}} type="inner">
+        <Row style={{marginTop: '10px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("general:Organization"), i18next.t("general:Organization - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: '100%'}} value={this.state.apiResource.owner} onChange={(value => {this.updateApiResourceField('owner', value);})}>
+              {
+                this.state.organizations.map((organization, index) => <Option key={index} value={organization.name}>{organization.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("general:Name"), i18next.t("general:Name - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.apiResource.name} onChange={e => {
+              this.updateApiResourceField('name', e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("general:Display name"), i18next.t("general:Display name - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.apiResource.displayName} onChange={e => {
+              this.updateApiResourceField('displayName', e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("apiResource:Identifier"), i18next.t("apiResource:Identifier - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input placeholder="https://api.example.com" value={this.state.apiResource.identifier} onChange={e => {
+              this.updateApiResourceField('identifier', e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("apiResource:Scopes"), i18next.t("apiResource:Scopes - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="tags" style={{width: '100%'}} value={this.state.apiResource.scopes} onChange={(value => {this.updateApiResourceField('scopes', value);})}>
+              {
+                (this.state.apiResource.scopes || []).map((scope, index) => <Option key={index} value={scope}>{scope}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("general:Is enabled"), i18next.t("general:Is enabled - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.apiResource.isEnabled} onChange={checked => {
+              this.updateApiResourceField('isEnabled', checked);
+            }} />
+          </Col>
+        </Row>
+      </Card>
+    )
+  }
+
+  submitApiResourceEdit(willExist) {
+    let apiResource = Setting.deepCopy(this.state.apiResource);
+    ApiResourceBackend.updateApiResource(this.state.organizationName, this.state.apiResourceName, apiResource)
+      .then((res) => {
+        if (res.msg === "") {
+          Setting.showMessage("success", `Successfully saved`);
+          this.setState({
+            apiResourceName: this.state.apiResource.name,
+          });
+
+          if (willExist) {
+            this.props.history.push(`/api-resources`);
+          } else {
+            this.props.history.push(`/api-resources/${this.state.apiResource.owner}/${this.state.apiResource.name}`);
+          }
+        } else {
+          Setting.showMessage("error", res.msg);
+          this.updateApiResourceField('name', this.state.apiResourceName);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `Failed to connect to server: ${error}`);
+      });
+  }
+
+  deleteApiResource() {
+    ApiResourceBackend.deleteApiResource(this.state.apiResource)
+      .then(() => {
+        this.props.history.push(`/api-resources`);
+      })
+      .catch(error => {
+        Setting.showMessage("error", `API resource failed to delete: ${error}`);
+      });
+  }
+
+  render() {
+    return (
+      <div>
+        {
+          this.state.apiResource !== null ? this.renderApiResource() : null
+        }
+        <div style={{marginTop: '20px', marginLeft: '40px'}}>
+          <Button size="large" onClick={() => this.submitApiResourceEdit(false)}>{i18next.t("general:Save")}</Button>
+          <Button style={{marginLeft: '20px'}} type="primary" size="large" onClick={() => this.submitApiResourceEdit(true)}>{i18next.t("general:Save & Exit")}</Button>
+          {this.state.mode === "add" ? <Button style={{marginLeft: '20px'}} size="large" onClick={() => this.deleteApiResource()}>{i18next.t("general:Cancel")}</Button> : null}
+        </div>
+      </div>
+    );
+  }
+}
+
+export default ApiResourceEditPage;
@@ -27,6 +27,8 @@ import RoleListPage from "./RoleListPage";
 import RoleEditPage from "./RoleEditPage";
 import PermissionListPage from "./PermissionListPage";
 import PermissionEditPage from "./PermissionEditPage";
+import ApiResourceListPage from "./ApiResourceListPage";
+import ApiResourceEditPage from "./ApiResourceEditPage";
 import ProviderListPage from "./ProviderListPage";
 import ProviderEditPage from "./ProviderEditPage";
 import ApplicationListPage from "./ApplicationListPage";
@@ -66,6 +68,9 @@ import AuthCallback from "./auth/AuthCallback";
 import SelectLanguageBox from './SelectLanguageBox';
 import i18next from 'i18next';
 import PromptPage from "./auth/PromptPage";
+import DeviceAuthPage from "./auth/DeviceAuthPage";
+import CibaAuthPage from "./auth/CibaAuthPage";
+import ConsentPage from "./auth/ConsentPage";
 import OdicDiscoveryPage from "./auth/OidcDiscoveryPage";
 import SamlCallback from './auth/SamlCallback';
 import CasLogout from "./auth/CasLogout";
@@ -120,6 +125,8 @@ class App extends Component {
       this.setState({ selectedMenuKey: '/roles' });
     } else if (uri.includes('/permissions')) {
       this.setState({ selectedMenuKey: '/permissions' });
+    } else if (uri.includes('/api-resources')) {
+      this.setState({ selectedMenuKey: '/api-resources' });
     } else if (uri.includes('/models')) {
       this.setState({ selectedMenuKey: '/models' });
     } else if (uri.includes('/providers')) {
@@ -386,6 +393,13 @@ class App extends Component {
           </Link>
         </Menu.Item>
       );
+      res.push(
+        <Menu.Item key="/api-resources">
+          <Link to="/api-resources">
+            {i18next.t("general:API Resources")}
+          </Link>
+        </Menu.Item>
+      );
       res.push(
         <Menu.Item key="/models">
           <Link to="/models">
@@ -492,7 +506,7 @@ class App extends Component {
 
   renderLoginIfNotLoggedIn(component) {
     if (this.state.account === null) {
-      sessionStorage.setItem("from", window.location.pathname);
+      sessionStorage.setItem("from", window.location.pathname + window.location.search);
       return <Redirect to='/login' />
     } else if (this.state.account === undefined) {
       return null;
@@ -525,6 +539,8 @@ class App extends Component {
           <Route exact path="/roles/:organizationName/:roleName" render={(props) => this.renderLoginIfNotLoggedIn(<RoleEditPage account={this.state.account} {...props} />)}/>
           <Route exact path="/permissions" render={(props) => this.renderLoginIfNotLoggedIn(<PermissionListPage account={this.state.account} {...props} />)}/>
           <Route exact path="/permissions/:organizationName/:permissionName" render={(props) => this.renderLoginIfNotLoggedIn(<PermissionEditPage account={this.state.account} {...props} />)}/>
+          <Route exact path="/api-resources" render={(props) => this.renderLoginIfNotLoggedIn(<ApiResourceListPage account={this.state.account} {...props} />)}/>
+          <Route exact path="/api-resources/:organizationName/:apiResourceName" render={(props) => this.renderLoginIfNotLoggedIn(<ApiResourceEditPage account={this.state.account} {...props} />)}/>
           <Route exact path="/models" render={(props) => this.renderLoginIfNotLoggedIn(<ModelListPage account={this.state.account} {...props} />)}/>
           <Route exact path="/models/:organizationName/:modelName" render={(props) => this.renderLoginIfNotLoggedIn(<ModelEditPage account={this.state.account} {...props} />)}/>
           <Route exact path="/providers" render={(props) => this.renderLoginIfNotLoggedIn(<ProviderListPage account={this.state.account} {...props} />)}/>
@@ -669,6 +685,9 @@ class App extends Component {
           <Route exact path="/login" render={(props) => this.renderHomeIfLoggedIn(<SelfLoginPage account={this.state.account} {...props} />)}/>
           <Route exact path="/signup/oauth/authorize" render={(props) => <LoginPage account={this.state.account} type={"code"} mode={"signup"} {...props} onUpdateAccount={(account) => {this.onUpdateAccount(account)}} />}/>
           <Route exact path="/login/oauth/authorize" render={(props) => <LoginPage account={this.state.account} type={"code"} mode={"signin"} {...props} onUpdateAccount={(account) => {this.onUpdateAccount(account)}} />}/>
+          <Route exact path="/login/oauth/device" render={(props) => this.renderLoginIfNotLoggedIn(<DeviceAuthPage account={this.state.account} {...props} />)}/>
+          <Route exact path="/login/oauth/ciba" render={(props) => this.renderLoginIfNotLoggedIn(<CibaAuthPage account={this.state.account} {...props} />)}/>
+          <Route exact path="/login/oauth/consent" render={(props) => this.renderLoginIfNotLoggedIn(<ConsentPage account={this.state.account} {...props} />)}/>
           <Route exact path="/login/saml/authorize/:owner/:applicationName" render={(props) => <LoginPage account={this.state.account} type={"saml"} mode={"signin"} {...props} onUpdateAccount={(account) => {this.onUpdateAccount(account)}} />}/>
           <Route exact path="/cas/:owner/:casApplicationName/logout" render={(props) => this.renderHomeIfLoggedIn(<CasLogout clearAccount={() => this.setState({account: null})} {...props} />)} />
           <Route exact path="/cas/:owner/:casApplicationName/login" render={(props) => {return (<LoginPage type={"cas"} mode={"signup"} account={this.state.account} {...props} />)}} />
@@ -27,6 +27,8 @@ import i18next from "i18next";
 import UrlTable from "./UrlTable";
 import ProviderTable from "./ProviderTable";
 import SignupTable from "./SignupTable";
+import ClaimRuleTable from "./ClaimRuleTable";
+import ClaimTemplateTable from "./ClaimTemplateTable";
 import PromptPage from "./auth/PromptPage";
 
 import {Controlled as CodeMirror} from 'react-codemirror2';
@@ -279,6 +281,18 @@ class ApplicationEditPage extends React.Component {
             />
           </Col>
         </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Post logout redirect URLs"), i18next.t("application:Post logout redirect URLs - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <UrlTable
+              title={i18next.t("application:Post logout redirect URLs")}
+              table={this.state.application.postLogoutRedirectUris !== null ? this.state.application.postLogoutRedirectUris : []}
+              onUpdateTable={(value) => { this.updateApplicationField('postLogoutRedirectUris', value)}}
+            />
+          </Col>
+        </Row>
         <Row style={{marginTop: '20px'}} >
           <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("application:Token format"), i18next.t("application:Token format - Tooltip"))} :
@@ -286,12 +300,70 @@ class ApplicationEditPage extends React.Component {
           <Col span={22} >
             <Select virtual={false} style={{width: '100%'}} value={this.state.application.tokenFormat} onChange={(value => {this.updateApplicationField('tokenFormat', value);})}>
               {
-                ['JWT', 'JWT-Empty']
+                ['JWT', 'JWT-Empty', 'Opaque']
                   .map((item, index) => <Option key={index} value={item}>{item}</Option>)
               }
             </Select>
           </Col>
         </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Signing algorithm"), i18next.t("application:Signing algorithm - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: '100%'}} value={this.state.application.signingAlgorithm} onChange={(value => {this.updateApplicationField('signingAlgorithm', value);})}>
+              {
+                [
+                  {id: '', name: i18next.t("application:Same as the cert")},
+                  {id: 'RS256', name: 'RS256'},
+                  {id: 'RS384', name: 'RS384'},
+                  {id: 'RS512', name: 'RS512'},
+                  {id: 'PS256', name: 'PS256'},
+                  {id: 'PS384', name: 'PS384'},
+                  {id: 'PS512', name: 'PS512'},
+                  {id: 'ES256', name: 'ES256'},
+                  {id: 'ES384', name: 'ES384'},
+                  {id: 'ES512', name: 'ES512'},
+                  {id: 'EdDSA', name: 'EdDSA'},
+                ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Claim rules"), i18next.t("application:Claim rules - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <ClaimRuleTable
+              title={i18next.t("application:Claim rules")}
+              table={this.state.application.claimRules}
+              onUpdateTable={(value) => { this.updateApplicationField('claimRules', value)}}
+            />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Claim namespace"), i18next.t("application:Claim namespace - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input placeholder="https://example.com/claims/" value={this.state.application.claimNamespace} onChange={e => {
+              this.updateApplicationField('claimNamespace', e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Custom claims"), i18next.t("application:Custom claims - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <ClaimTemplateTable
+              title={i18next.t("application:Custom claims")}
+              table={this.state.application.claimTemplates}
+              onUpdateTable={(value) => { this.updateApplicationField('claimTemplates', value)}}
+            />
+          </Col>
+        </Row>
         <Row style={{marginTop: '20px'}} >
           <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("application:Token expire"), i18next.t("application:Token expire - Tooltip"))} :
@@ -312,6 +384,207 @@ class ApplicationEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Refresh token rotation"), i18next.t("application:Refresh token rotation - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.enableRefreshTokenRotation} onChange={checked => {
+              this.updateApplicationField('enableRefreshTokenRotation', checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Require PAR"), i18next.t("application:Require PAR - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.requirePushedAuthorizationRequests} onChange={checked => {
+              this.updateApplicationField('requirePushedAuthorizationRequests', checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Require DPoP nonce"), i18next.t("application:Require DPoP nonce - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.requireDpopNonce} onChange={checked => {
+              this.updateApplicationField('requireDpopNonce', checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Backchannel logout URI"), i18next.t("application:Backchannel logout URI - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input prefix={<LinkOutlined/>} value={this.state.application.backchannelLogoutUri} onChange={e => {
+              this.updateApplicationField('backchannelLogoutUri', e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Frontchannel logout URI"), i18next.t("application:Frontchannel logout URI - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Input prefix={<LinkOutlined/>} value={this.state.application.frontchannelLogoutUri} onChange={e => {
+              this.updateApplicationField('frontchannelLogoutUri', e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Frontchannel logout session required"), i18next.t("application:Frontchannel logout session required - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.frontchannelLogoutSessionRequired} onChange={checked => {
+              this.updateApplicationField('frontchannelLogoutSessionRequired', checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Backchannel token delivery mode"), i18next.t("application:Backchannel token delivery mode - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: '100%'}} value={this.state.application.backchannelTokenDeliveryMode} onChange={(value => {this.updateApplicationField('backchannelTokenDeliveryMode', value);})}>
+              {
+                [
+                  {id: '', name: 'Poll'},
+                  {id: 'ping', name: 'Ping'},
+                ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        {
+          this.state.application.backchannelTokenDeliveryMode !== "ping" ? null : (
+            <Row style={{marginTop: '20px'}} >
+              <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("application:Client notification endpoint"), i18next.t("application:Client notification endpoint - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <Input prefix={<LinkOutlined/>} value={this.state.application.backchannelClientNotificationEndpoint} onChange={e => {
+                  this.updateApplicationField('backchannelClientNotificationEndpoint', e.target.value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Userinfo signing algorithm"), i18next.t("application:Userinfo signing algorithm - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: '100%'}} value={this.state.application.userinfoSignedResponseAlg} onChange={(value => {this.updateApplicationField('userinfoSignedResponseAlg', value);})}>
+              {
+                [
+                  {id: '', name: i18next.t("application:Not signed")},
+                  {id: 'RS256', name: 'RS256'},
+                  {id: 'RS384', name: 'RS384'},
+                  {id: 'RS512', name: 'RS512'},
+                  {id: 'PS256', name: 'PS256'},
+                  {id: 'PS384', name: 'PS384'},
+                  {id: 'PS512', name: 'PS512'},
+                  {id: 'ES256', name: 'ES256'},
+                  {id: 'ES384', name: 'ES384'},
+                  {id: 'ES512', name: 'ES512'},
+                  {id: 'EdDSA', name: 'EdDSA'},
+                ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Userinfo encryption algorithm"), i18next.t("application:Userinfo encryption algorithm - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: '100%'}} value={this.state.application.userinfoEncryptedResponseAlg} onChange={(value => {this.updateApplicationField('userinfoEncryptedResponseAlg', value);})}>
+              {
+                [
+                  {id: '', name: i18next.t("application:Not encrypted")},
+                  {id: 'RSA-OAEP', name: 'RSA-OAEP'},
+                  {id: 'RSA-OAEP-256', name: 'RSA-OAEP-256'},
+                  {id: 'ECDH-ES', name: 'ECDH-ES'},
+                  {id: 'ECDH-ES+A128KW', name: 'ECDH-ES+A128KW'},
+                  {id: 'ECDH-ES+A192KW', name: 'ECDH-ES+A192KW'},
+                  {id: 'ECDH-ES+A256KW', name: 'ECDH-ES+A256KW'},
+                ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        {
+          !this.state.application.userinfoEncryptedResponseAlg ? null : (
+            <Row style={{marginTop: '20px'}} >
+              <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("application:Userinfo content encryption"), i18next.t("application:Userinfo content encryption - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <Select virtual={false} style={{width: '100%'}} value={this.state.application.userinfoEncryptedResponseEnc} onChange={(value => {this.updateApplicationField('userinfoEncryptedResponseEnc', value);})}>
+                  {
+                    [
+                      {id: '', name: 'A128CBC-HS256'},
+                      {id: 'A192CBC-HS384', name: 'A192CBC-HS384'},
+                      {id: 'A256CBC-HS512', name: 'A256CBC-HS512'},
+                      {id: 'A128GCM', name: 'A128GCM'},
+                      {id: 'A192GCM', name: 'A192GCM'},
+                      {id: 'A256GCM', name: 'A256GCM'},
+                    ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
+                  }
+                </Select>
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Token endpoint auth method"), i18next.t("application:Token endpoint auth method - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: '100%'}} value={this.state.application.tokenEndpointAuthMethod} onChange={(value => {this.updateApplicationField('tokenEndpointAuthMethod', value);})}>
+              {
+                [
+                  {id: '', name: 'client_secret_basic / client_secret_post'},
+                  {id: 'client_secret_basic', name: 'client_secret_basic'},
+                  {id: 'client_secret_post', name: 'client_secret_post'},
+                  {id: 'client_secret_jwt', name: 'client_secret_jwt'},
+                  {id: 'private_key_jwt', name: 'private_key_jwt'},
+                  {id: 'none', name: 'none'},
+                ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
+              }
+            </Select>
+          </Col>
+        </Row>
+        {
+          (this.state.application.tokenEndpointAuthMethod !== 'private_key_jwt' && !this.state.application.userinfoEncryptedResponseAlg) ? null : (
+            <React.Fragment>
+              <Row style={{marginTop: '20px'}} >
+                <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("application:Client JWKS"), i18next.t("application:Client JWKS - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <TextArea rows={6} value={this.state.application.clientJwks} onChange={e => {
+                    this.updateApplicationField('clientJwks', e.target.value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: '20px'}} >
+                <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {Setting.getLabel(i18next.t("application:Client public key"), i18next.t("application:Client public key - Tooltip"))} :
+                </Col>
+                <Col span={22} >
+                  <TextArea rows={6} value={this.state.application.clientPublicKey} onChange={e => {
+                    this.updateApplicationField('clientPublicKey', e.target.value);
+                  }} />
+                </Col>
+              </Row>
+            </React.Fragment>
+          )
+        }
         <Row style={{marginTop: '20px'}} >
           <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 19 : 2}>
             {Setting.getLabel(i18next.t("application:Password ON"), i18next.t("application:Password ON - Tooltip"))} :
@@ -342,6 +615,16 @@ class ApplicationEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 19 : 2}>
+            {Setting.getLabel(i18next.t("application:Skip consent"), i18next.t("application:Skip consent - Tooltip"))} :
+          </Col>
+          <Col span={1} >
+            <Switch checked={this.state.application.skipConsent} onChange={checked => {
+              this.updateApplicationField('skipConsent', checked);
+            }} />
+          </Col>
+        </Row>
         <Row style={{marginTop: '20px'}} >
           <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 19 : 2}>
             {Setting.getLabel(i18next.t("application:Enable code signin"), i18next.t("application:Enable code signin - Tooltip"))} :
@@ -468,11 +751,44 @@ class ApplicationEditPage extends React.Component {
                           {id: "token", name: "Token"},
                           {id: "id_token", name: "ID Token"},
                           {id: "refresh_token", name: "Refresh Token"},
+                          {id: "urn:ietf:params:oauth:grant-type:device_code", name: "Device Code"},
+                          {id: "urn:openid:params:grant-type:ciba", name: "CIBA"},
+                          {id: "urn:ietf:params:oauth:grant-type:token-exchange", name: "Token Exchange"},
                         ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
                       }
             </Select>
           </Col>
         </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Response types"), i18next.t("application:Response types - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="multiple" style={{width: '100%'}}
+                    value={this.state.application.responseTypes ?? []}
+                    onChange={(value => {
+                      this.updateApplicationField('responseTypes', value);
+                    })} >
+                      {
+                        ["code", "token", "id_token", "id_token token", "code id_token", "code token", "code id_token token"]
+                          .map((item, index) => <Option key={index} value={item}>{item}</Option>)
+                      }
+            </Select>
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("application:Token exchange audiences"), i18next.t("application:Token exchange audiences - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} mode="tags" style={{width: '100%'}}
+                    value={this.state.application.tokenExchangeAudiences}
+                    onChange={(value => {
+                      this.updateApplicationField('tokenExchangeAudiences', value);
+                    })} >
+            </Select>
+          </Col>
+        </Row>
         <Row style={{marginTop: '20px'}} >
           <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("application:SAML metadata"), i18next.t("application:SAML metadata - Tooltip"))} :
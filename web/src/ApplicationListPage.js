@@ -49,6 +49,8 @@ class ApplicationListPage extends BaseListPage {
       ],
       cert: "cert-built-in",
       redirectUris: ["http://localhost:9000/callback"],
+      postLogoutRedirectUris: [],
+      claimRules: [],
       tokenFormat: "JWT",
       expireInHours: 24 * 7,
     }
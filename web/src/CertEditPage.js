@@ -137,6 +137,15 @@ class CertEditPage extends React.Component {
               {
                 [
                   {id: 'RS256', name: 'RS256'},
+                  {id: 'RS384', name: 'RS384'},
+                  {id: 'RS512', name: 'RS512'},
+                  {id: 'PS256', name: 'PS256'},
+                  {id: 'PS384', name: 'PS384'},
+                  {id: 'PS512', name: 'PS512'},
+                  {id: 'ES256', name: 'ES256 (ECDSA P-256)'},
+                  {id: 'ES384', name: 'ES384 (ECDSA P-384)'},
+                  {id: 'ES512', name: 'ES512 (ECDSA P-521)'},
+                  {id: 'EdDSA', name: 'EdDSA (Ed25519)'},
                 ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
               }
             </Select>
@@ -162,6 +171,30 @@ class CertEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("cert:Rotation interval"), i18next.t("cert:Rotation interval - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <InputNumber min={0} value={this.state.cert.rotationIntervalDays} formatter={value => `${value} days`} parser={value => value.replace(' days', '')} onChange={value => {
+              this.updateCertField('rotationIntervalDays', value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: '20px'}} >
+          <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+            {Setting.getLabel(i18next.t("cert:Key rotation"), i18next.t("cert:Key rotation - Tooltip"))} :
+          </Col>
+          <Col span={22} >
+            <div style={{marginTop: '5px'}}>
+              {i18next.t("cert:Current key")}: {this.state.cert.keyId !== "" ? this.state.cert.keyId : this.state.cert.name}
+              {this.state.cert.nextKeyId !== "" ? `, ${i18next.t("cert:Next key")}: ${this.state.cert.nextKeyId}` : null}
+              {this.state.cert.previousKeyId !== "" ? `, ${i18next.t("cert:Previous key")}: ${this.state.cert.previousKeyId} (${i18next.t("cert:Expire at")} ${Setting.getFormattedDate(this.state.cert.previousKeyExpireTime)})` : null}
+            </div>
+            <Button style={{marginTop: '10px', marginRight: '10px'}} disabled={this.state.mode === "add"} onClick={() => this.prepareCertNextKey()}>{i18next.t("cert:Prepare next key")}</Button>
+            <Button style={{marginTop: '10px'}} type="primary" disabled={this.state.mode === "add"} onClick={() => this.rotateCert()}>{i18next.t("cert:Rotate now")}</Button>
+          </Col>
+        </Row>
         <Row style={{marginTop: '20px'}} >
           <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
             {Setting.getLabel(i18next.t("cert:Public key"), i18next.t("cert:Public key - Tooltip"))} :
@@ -190,14 +223,14 @@ class CertEditPage extends React.Component {
             {Setting.getLabel(i18next.t("cert:Private key"), i18next.t("cert:Private key - Tooltip"))} :
           </Col>
           <Col span={9} >
-            <Button style={{marginRight: '10px', marginBottom: '10px'}} onClick={() => {
+            <Button style={{marginRight: '10px', marginBottom: '10px'}} disabled={this.state.cert.privateKey === "***"} onClick={() => {
               copy(this.state.cert.privateKey);
               Setting.showMessage("success", i18next.t("cert:Private key copied to clipboard successfully"));
             }}
             >
               {i18next.t("cert:Copy private key")}
             </Button>
-            <Button type="primary" onClick={() => {
+            <Button type="primary" disabled={this.state.cert.privateKey === "***"} onClick={() => {
               const blob = new Blob([this.state.cert.privateKey], {type: "text/plain;charset=utf-8"});
               FileSaver.saveAs(blob, "token_jwt_key.key");
             }}
@@ -238,6 +271,36 @@ class CertEditPage extends React.Component {
       });
   }
 
+  prepareCertNextKey() {
+    CertBackend.prepareCertNextKey(this.state.cert.owner, this.state.certName)
+      .then((res) => {
+        if (res.msg === "") {
+          Setting.showMessage("success", `Successfully prepared`);
+          this.getCert();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `Failed to connect to server: ${error}`);
+      });
+  }
+
+  rotateCert() {
+    CertBackend.rotateCert(this.state.cert.owner, this.state.certName)
+      .then((res) => {
+        if (res.msg === "") {
+          Setting.showMessage("success", `Successfully rotated`);
+          this.getCert();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `Failed to connect to server: ${error}`);
+      });
+  }
+
   deleteCert() {
     CertBackend.deleteCert(this.state.cert)
       .then(() => {
@@ -0,0 +1,204 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {DownOutlined, DeleteOutlined, UpOutlined} from '@ant-design/icons';
+import {Button, Col, Input, Row, Select, Table, Tooltip} from 'antd';
+import * as Setting from "./Setting";
+import i18next from "i18next";
+import {userFields} from "./ClaimRuleTable";
+
+const { Option } = Select;
+
+class ClaimTemplateTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+    };
+  }
+
+  updateTable(table) {
+    this.props.onUpdateTable(table);
+  }
+
+  updateField(table, index, key, value) {
+    table[index][key] = value;
+    this.updateTable(table);
+  }
+
+  addRow(table) {
+    let row = {name: "", source: "User", value: "", scope: "", target: ""};
+    if (table === undefined || table === null) {
+      table = [];
+    }
+    table = Setting.addRow(table, row);
+    this.updateTable(table);
+  }
+
+  deleteRow(table, i) {
+    table = Setting.deleteRow(table, i);
+    this.updateTable(table);
+  }
+
+  upRow(table, i) {
+    table = Setting.swapRow(table, i - 1, i);
+    this.updateTable(table);
+  }
+
+  downRow(table, i) {
+    table = Setting.swapRow(table, i, i + 1);
+    this.updateTable(table);
+  }
+
+  renderTable(table) {
+    const columns = [
+      {
+        title: i18next.t("general:Name"),
+        dataIndex: 'name',
+        key: 'name',
+        width: '200px',
+        render: (text, record, index) => {
+          return (
+            <Input value={text} onChange={e => {
+              this.updateField(table, index, 'name', e.target.value);
+            }} />
+          )
+        }
+      },
+      {
+        title: i18next.t("application:Source"),
+        dataIndex: 'source',
+        key: 'source',
+        width: '150px',
+        render: (text, record, index) => {
+          return (
+            <Select virtual={false} style={{width: '100%'}} value={text} onChange={(value => {
+              this.updateField(table, index, 'source', value);
+            })}>
+              {
+                ["User", "Property", "Roles", "Permissions", "Static"].map((item, index) => <Option key={index} value={item}>{item}</Option>)
+              }
+            </Select>
+          )
+        }
+      },
+      {
+        title: i18next.t("application:Value"),
+        dataIndex: 'value',
+        key: 'value',
+        render: (text, record, index) => {
+          if (record.source === "User") {
+            return (
+              <Select virtual={false} showSearch style={{width: '100%'}} value={text} onChange={(value => {
+                this.updateField(table, index, 'value', value);
+              })}>
+                {
+                  userFields.map((item, index) => <Option key={index} value={item}>{item}</Option>)
+                }
+              </Select>
+            )
+          }
+
+          return (
+            <Input value={text} disabled={record.source === "Roles" || record.source === "Permissions"} onChange={e => {
+              this.updateField(table, index, 'value', e.target.value);
+            }} />
+          )
+        }
+      },
+      {
+        title: i18next.t("application:Scope"),
+        dataIndex: 'scope',
+        key: 'scope',
+        width: '150px',
+        render: (text, record, index) => {
+          return (
+            <Input value={text} onChange={e => {
+              this.updateField(table, index, 'scope', e.target.value);
+            }} />
+          )
+        }
+      },
+      {
+        title: i18next.t("application:Target"),
+        dataIndex: 'target',
+        key: 'target',
+        width: '150px',
+        render: (text, record, index) => {
+          return (
+            <Select virtual={false} style={{width: '100%'}} value={text} onChange={(value => {
+              this.updateField(table, index, 'target', value);
+            })}>
+              {
+                [
+                  {id: '', name: 'All'},
+                  {id: 'JWT', name: 'JWT'},
+                  {id: 'Userinfo', name: 'Userinfo'},
+                ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
+              }
+            </Select>
+          )
+        }
+      },
+      {
+        title: i18next.t("general:Action"),
+        key: 'action',
+        width: '100px',
+        render: (text, record, index) => {
+          return (
+            <div>
+              <Tooltip placement="bottomLeft" title={i18next.t("general:Up")}>
+                <Button style={{marginRight: "5px"}} disabled={index === 0} icon={<UpOutlined />} size="small" onClick={() => this.upRow(table, index)} />
+              </Tooltip>
+              <Tooltip placement="topLeft" title={i18next.t("general:Down")}>
+                <Button style={{marginRight: "5px"}} disabled={index === table.length - 1} icon={<DownOutlined />} size="small" onClick={() => this.downRow(table, index)} />
+              </Tooltip>
+              <Tooltip placement="topLeft" title={i18next.t("general:Delete")}>
+                <Button icon={<DeleteOutlined />} size="small" onClick={() => this.deleteRow(table, index)} />
+              </Tooltip>
+            </div>
+          );
+        }
+      },
+    ];
+
+    return (
+      <Table rowKey="index" columns={columns} dataSource={table} size="middle" bordered pagination={false}
+             title={() => (
+               <div>
+                 {this.props.title}&nbsp;&nbsp;&nbsp;&nbsp;
+                 <Button style={{marginRight: "5px"}} type="primary" size="small" onClick={() => this.addRow(table)}>{i18next.t("general:Add")}</Button>
+               </div>
+             )}
+      />
+    );
+  }
+
+  render() {
+    return (
+      <div>
+        <Row style={{marginTop: '20px'}} >
+          <Col span={24}>
+            {
+              this.renderTable(this.props.table)
+            }
+          </Col>
+        </Row>
+      </div>
+    )
+  }
+}
+
+export default ClaimTemplateTable;
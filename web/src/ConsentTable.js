@@ -0,0 +1,104 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Popconfirm, Table} from 'antd';
+import * as ConsentBackend from "./backend/ConsentBackend";
+import * as Setting from "./Setting";
+import i18next from "i18next";
+
+class ConsentTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+      consents: [],
+    };
+  }
+
+  UNSAFE_componentWillMount() {
+    this.getUserConsents();
+  }
+
+  getUserConsents() {
+    ConsentBackend.getUserConsents(this.props.userId)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            consents: res.data,
+          });
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  revokeConsent(consent) {
+    ConsentBackend.revokeConsent(consent)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("user:The access of the application has been revoked"));
+          this.getUserConsents();
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("general:Application"),
+        dataIndex: 'application',
+        key: 'application',
+        width: '200px',
+      },
+      {
+        title: i18next.t("application:Scope"),
+        dataIndex: 'scopes',
+        key: 'scopes',
+        render: (text, record, index) => {
+          return (record.scopes || []).concat(record.resources || []).join(" ");
+        }
+      },
+      {
+        title: i18next.t("general:Updated time"),
+        dataIndex: 'updatedTime',
+        key: 'updatedTime',
+        width: '160px',
+        render: (text, record, index) => {
+          return Setting.getFormattedDate(text);
+        }
+      },
+      {
+        title: i18next.t("general:Action"),
+        key: 'action',
+        width: '100px',
+        render: (text, record, index) => {
+          return (
+            <Popconfirm title={i18next.t("user:Revoke the access of the application and sign it out?")} onConfirm={() => this.revokeConsent(record)}>
+              <Button size="small" type="danger">{i18next.t("user:Revoke")}</Button>
+            </Popconfirm>
+          );
+        }
+      },
+    ];
+
+    return (
+      <Table rowKey="name" columns={columns} dataSource={this.state.consents} size="middle" bordered pagination={false} />
+    );
+  }
+}
+
+export default ConsentTable;
@@ -27,6 +27,7 @@ import AffiliationSelect from "./common/AffiliationSelect";
 import OAuthWidget from "./common/OAuthWidget";
 import SamlWidget from "./common/SamlWidget";
 import SelectRegionBox from "./SelectRegionBox";
+import ConsentTable from "./ConsentTable";
 
 import {Controlled as CodeMirror} from 'react-codemirror2';
 import "codemirror/lib/codemirror.css";
@@ -371,6 +372,18 @@ class UserEditPage extends React.Component {
             </Row>
           )
         }
+        {
+          !this.isSelfOrAdmin() ? null : (
+            <Row style={{marginTop: '20px'}} >
+              <Col style={{marginTop: '5px'}} span={(Setting.isMobile()) ? 22 : 2}>
+                {Setting.getLabel(i18next.t("user:Authorized applications"), i18next.t("user:Authorized applications - Tooltip"))} :
+              </Col>
+              <Col span={22} >
+                <ConsentTable userId={`${this.state.user.owner}/${this.state.user.name}`} />
+              </Col>
+            </Row>
+          )
+        }
         {
           !Setting.isAdminUser(this.props.account) ? null : (
             <React.Fragment>
@@ -44,7 +44,7 @@ function oAuthParamsToQuery(oAuthParams) {
   }
 
   // code
-  return `?clientId=${oAuthParams.clientId}&responseType=${oAuthParams.responseType}&redirectUri=${oAuthParams.redirectUri}&scope=${oAuthParams.scope}&state=${oAuthParams.state}&nonce=${oAuthParams.nonce}&code_challenge_method=${oAuthParams.challengeMethod}&code_challenge=${oAuthParams.codeChallenge}`;
+  return `?clientId=${oAuthParams.clientId}&responseType=${oAuthParams.responseType}&redirectUri=${oAuthParams.redirectUri}&scope=${oAuthParams.scope}&state=${oAuthParams.state}&nonce=${oAuthParams.nonce}&code_challenge_method=${oAuthParams.challengeMethod}&code_challenge=${oAuthParams.codeChallenge}&response_mode=${oAuthParams.responseMode}&request_uri=${encodeURIComponent(oAuthParams.requestUri)}&prompt=${encodeURIComponent(oAuthParams.prompt || "")}&acr_values=${encodeURIComponent(oAuthParams.acrValues || "")}&max_age=${encodeURIComponent(oAuthParams.maxAge || "")}${(oAuthParams.resources || []).map(resource => `&resource=${encodeURIComponent(resource)}`).join("")}`;
 }
 
 export function getApplicationLogin(oAuthParams) {
@@ -62,6 +62,13 @@ export function login(values, oAuthParams) {
   }).then(res => res.json());
 }
 
+export function grantConsent(oAuthParams) {
+  return fetch(`${authConfig.serverUrl}/api/login/oauth/consent${oAuthParamsToQuery(oAuthParams)}`, {
+    method: 'POST',
+    credentials: "include",
+  }).then(res => res.json());
+}
+
 export function loginCas(values, params) {
   return fetch(`${authConfig.serverUrl}/api/login?service=${params.service}`, {
     method: 'POST',
@@ -70,13 +77,56 @@ export function loginCas(values, params) {
   }).then(res => res.json());
 }
 
-export function logout() {
-  return fetch(`${authConfig.serverUrl}/api/logout`, {
-    method: 'POST',
+export function getFrontchannelLogoutUris() {
+  return fetch(`${authConfig.serverUrl}/api/get-frontchannel-logout-uris`, {
+    method: 'GET',
     credentials: "include",
   }).then(res => res.json());
 }
 
+// loads the front-channel logout URIs in hidden iframes, and resolves when they are loaded or after a timeout
+function loadFrontchannelLogoutUris(uris) {
+  return new Promise(resolve => {
+    let pending = uris.length;
+    if (pending === 0) {
+      resolve();
+      return;
+    }
+
+    const done = () => {
+      pending--;
+      if (pending <= 0) {
+        resolve();
+      }
+    };
+    uris.forEach(uri => {
+      const iframe = document.createElement("iframe");
+      iframe.style.display = "none";
+      iframe.src = uri;
+      iframe.onload = done;
+      iframe.onerror = done;
+      document.body.appendChild(iframe);
+    });
+    setTimeout(resolve, 5000);
+  });
+}
+
+export function logout() {
+  return getFrontchannelLogoutUris()
+    .then(res => res.status === 'ok' && res.data !== null ? res.data : [])
+    .catch(() => [])
+    .then(uris => fetch(`${authConfig.serverUrl}/api/logout`, {
+      method: 'POST',
+      credentials: "include",
+    }).then(res => res.json())
+      .then(res => {
+        if (res.status !== 'ok') {
+          return res;
+        }
+        return loadFrontchannelLogoutUris(uris).then(() => res);
+      }));
+}
+
 export function unlink(values) {
   return fetch(`${authConfig.serverUrl}/api/unlink`, {
     method: 'POST',
@@ -98,4 +148,31 @@ export function loginWithSaml(values, param) {
     credentials: "include",
     body: JSON.stringify(values),
   }).then(res => res.json());
-}
\ No newline at end of file
+}
+export function getDeviceAuth(userCode) {
+  return fetch(`${authConfig.serverUrl}/api/login/oauth/device?user_code=${encodeURIComponent(userCode)}`, {
+    method: 'GET',
+    credentials: 'include',
+  }).then(res => res.json());
+}
+
+export function verifyDeviceAuth(userCode, approved) {
+  return fetch(`${authConfig.serverUrl}/api/login/oauth/device?user_code=${encodeURIComponent(userCode)}&approved=${approved}`, {
+    method: 'POST',
+    credentials: 'include',
+  }).then(res => res.json());
+}
+
+export function getCibaRequests() {
+  return fetch(`${authConfig.serverUrl}/api/login/oauth/ciba`, {
+    method: 'GET',
+    credentials: 'include',
+  }).then(res => res.json());
+}
+
+export function verifyCibaRequest(id, approved) {
+  return fetch(`${authConfig.serverUrl}/api/login/oauth/ciba?id=${encodeURIComponent(id)}&approved=${approved}`, {
+    method: 'POST',
+    credentials: 'include',
+  }).then(res => res.json());
+}
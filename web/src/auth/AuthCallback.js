@@ -127,9 +127,8 @@ class AuthCallback extends React.Component {
             const code = res.data;
             Setting.goToLink(`${oAuthParams.redirectUri}${concatChar}code=${code}&state=${oAuthParams.state}`);
             // Util.showMessage("success", `Authorization code: ${res.data}`);
-          } else if (responseType === "token" || responseType === "id_token"){
-            const token = res.data;
-            Setting.goToLink(`${oAuthParams.redirectUri}${concatChar}${responseType}=${token}&state=${oAuthParams.state}&token_type=bearer`);
+          } else if (Util.isImplicitResponseType(responseType)) {
+            Util.sendAuthorizationResponse(oAuthParams.redirectUri, res.data2, res.data);
           } else if (responseType === "link") {
             const from = innerParams.get("from");
             Setting.goToLinkSoft(this, from);
@@ -138,6 +137,10 @@ class AuthCallback extends React.Component {
             const redirectUri = res.data2;
             Setting.goToLink(`${redirectUri}?SAMLResponse=${encodeURIComponent(SAMLResponse)}&RelayState=${oAuthParams.relayState}`);
           }
+        } else if (res.data === "consent_required") {
+          Util.goToConsentPage(this, oAuthParams);
+        } else if (res.data === "login_required" || res.data === "unmet_authentication_requirements") {
+          Util.goToLoginPage(this, oAuthParams, res.data);
         } else {
           this.setState({
             msg: res.msg,
@@ -0,0 +1,135 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Col, Result, Row} from "antd";
+import * as AuthBackend from "./AuthBackend";
+import * as Setting from "../Setting";
+import i18next from "i18next";
+
+// the page where the signed-in user approves or denies the sign in requests that the applications
+// started on the user's behalf, through the backchannel authentication (CIBA)
+class CibaAuthPage extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+      cibaRequests: null,
+      result: null,
+    };
+  }
+
+  UNSAFE_componentWillMount() {
+    this.getCibaRequests();
+  }
+
+  getCibaRequests() {
+    AuthBackend.getCibaRequests()
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            cibaRequests: res.data,
+          });
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  verifyCibaRequest(cibaRequest, approved) {
+    AuthBackend.verifyCibaRequest(`${cibaRequest.owner}/${cibaRequest.name}`, approved)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            cibaRequests: this.state.cibaRequests.filter(item => item.name !== cibaRequest.name),
+            result: approved ? "approved" : "denied",
+          });
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  renderConfirm(cibaRequest) {
+    const application = cibaRequest.applicationObj;
+    return (
+      <div key={cibaRequest.name} style={{width: "320px", marginBottom: "40px"}}>
+        {
+          Setting.renderLogo(application)
+        }
+        <div style={{marginBottom: "20px"}}>
+          {i18next.t("login:Do you want to sign in to this application?")}
+        </div>
+        <div style={{fontSize: "20px", fontWeight: "bold", marginBottom: "20px"}}>
+          {application?.displayName}
+        </div>
+        {
+          cibaRequest.bindingMessage === "" ? null : (
+            <div style={{marginBottom: "20px"}}>
+              {i18next.t("login:Make sure the code matches the one you were given")}
+              <div style={{fontSize: "20px", fontWeight: "bold"}}>
+                {cibaRequest.bindingMessage}
+              </div>
+            </div>
+          )
+        }
+        <Button type="primary" size="large" style={{width: "100%"}} onClick={() => this.verifyCibaRequest(cibaRequest, true)}>
+          {i18next.t("login:Allow")}
+        </Button>
+        <Button size="large" style={{marginTop: "10px", width: "100%"}} onClick={() => this.verifyCibaRequest(cibaRequest, false)}>
+          {i18next.t("login:Deny")}
+        </Button>
+      </div>
+    )
+  }
+
+  render() {
+    if (this.state.cibaRequests === null) {
+      return null;
+    }
+
+    if (this.state.cibaRequests.length === 0) {
+      if (this.state.result !== null) {
+        return (
+          <Result
+            status={this.state.result === "approved" ? "success" : "warning"}
+            title={this.state.result === "approved" ? i18next.t("login:The sign in has been approved") : i18next.t("login:The sign in has been denied")}
+            subTitle={i18next.t("login:You can close this page now")}
+          />
+        )
+      }
+
+      return (
+        <Result
+          status="info"
+          title={i18next.t("login:There is no sign in request to approve")}
+        />
+      )
+    }
+
+    return (
+      <Row>
+        <Col span={24} style={{display: "flex", justifyContent: "center"}}>
+          <div style={{marginTop: "80px", marginBottom: "50px", textAlign: "center"}}>
+            {
+              this.state.cibaRequests.map(cibaRequest => this.renderConfirm(cibaRequest))
+            }
+          </div>
+        </Col>
+      </Row>
+    )
+  }
+}
+
+export default CibaAuthPage;
@@ -0,0 +1,127 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Col, List, Row} from "antd";
+import * as AuthBackend from "./AuthBackend";
+import * as Setting from "../Setting";
+import * as Util from "./Util";
+import i18next from "i18next";
+
+class ConsentPage extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      classes: props,
+      oAuthParams: Util.getOAuthGetParameters(),
+      application: null,
+      scope: "",
+      resources: [],
+    };
+  }
+
+  UNSAFE_componentWillMount() {
+    if (this.state.oAuthParams !== null) {
+      this.getApplicationLogin();
+    }
+  }
+
+  getApplicationLogin() {
+    AuthBackend.getApplicationLogin(this.state.oAuthParams)
+      .then((res) => {
+        if (res.status === "ok") {
+          // the parameters of a pushed authorization request are only known to the backend
+          const params = res.data2 ? new URLSearchParams(res.data2) : null;
+          this.setState({
+            application: res.data,
+            scope: params !== null ? params.get("scope") || "" : this.state.oAuthParams.scope,
+            resources: params !== null ? params.getAll("resource") : this.state.oAuthParams.resources,
+          });
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  grantConsent() {
+    const oAuthParams = this.state.oAuthParams;
+    AuthBackend.grantConsent(oAuthParams)
+      .then((res) => {
+        if (res.status !== "ok") {
+          Setting.showMessage("error", res.msg);
+          return;
+        }
+
+        // sign in with the consent, which stands for the one that "prompt=consent" asks for
+        const application = this.state.application;
+        const values = {application: application.name, organization: application.organization, type: oAuthParams.responseType};
+        AuthBackend.login(values, oAuthParams)
+          .then((res) => {
+            if (res.status !== "ok") {
+              Setting.showMessage("error", `Failed to sign in: ${res.msg}`);
+            } else if (Util.isImplicitResponseType(oAuthParams.responseType)) {
+              Util.sendAuthorizationResponse(oAuthParams.redirectUri, res.data2, res.data);
+            } else {
+              const concatChar = oAuthParams.redirectUri.includes('?') ? '&' : '?';
+              Setting.goToLink(`${oAuthParams.redirectUri}${concatChar}code=${res.data}&state=${oAuthParams.state}`);
+            }
+          });
+      });
+  }
+
+  renderConsent(application) {
+    const items = this.state.scope.split(" ").filter(scope => scope !== "").concat(this.state.resources);
+
+    return (
+      <div style={{width: "320px"}}>
+        {
+          Setting.renderLogo(application)
+        }
+        <div style={{fontSize: "20px", fontWeight: "bold", marginBottom: "20px"}}>
+          {application.displayName}
+        </div>
+        <div style={{marginBottom: "20px"}}>
+          {i18next.t("login:The application wants to access your account with the following permissions")}
+        </div>
+        <List size="small" bordered style={{marginBottom: "20px", textAlign: "left"}} dataSource={items} renderItem={item => <List.Item>{item}</List.Item>} />
+        <Button type="primary" size="large" style={{width: "100%"}} onClick={() => this.grantConsent()}>
+          {i18next.t("login:Allow")}
+        </Button>
+        <Button size="large" style={{marginTop: "10px", width: "100%"}} onClick={() => Util.sendAuthorizationError(this.state.oAuthParams, "access_denied")}>
+          {i18next.t("login:Deny")}
+        </Button>
+      </div>
+    )
+  }
+
+  render() {
+    if (this.state.oAuthParams === null || this.state.application === null) {
+      return null;
+    }
+
+    return (
+      <Row>
+        <Col span={24} style={{display: "flex", justifyContent: "center"}}>
+          <div style={{marginTop: "80px", marginBottom: "50px", textAlign: "center"}}>
+            {
+              this.renderConsent(this.state.application)
+            }
+          </div>
+        </Col>
+      </Row>
+    )
+  }
+}
+
+export default ConsentPage;
@@ -0,0 +1,130 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import React from "react";
+import {Button, Col, Input, Result, Row} from "antd";
+import * as AuthBackend from "./AuthBackend";
+import * as Setting from "../Setting";
+import i18next from "i18next";
+
+class DeviceAuthPage extends React.Component {
+  constructor(props) {
+    super(props);
+    const params = new URLSearchParams(props.location.search);
+    this.state = {
+      classes: props,
+      userCode: params.get("user_code") !== null ? params.get("user_code") : "",
+      application: null,
+      result: null,
+    };
+  }
+
+  UNSAFE_componentWillMount() {
+    if (this.state.userCode !== "") {
+      this.getDeviceAuth();
+    }
+  }
+
+  getDeviceAuth() {
+    AuthBackend.getDeviceAuth(this.state.userCode)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            application: res.data,
+          });
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  verifyDeviceAuth(approved) {
+    AuthBackend.verifyDeviceAuth(this.state.userCode, approved)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            result: approved ? "approved" : "denied",
+          });
+        } else {
+          Setting.showMessage("error", res.msg);
+        }
+      });
+  }
+
+  renderUserCodeInput() {
+    return (
+      <div style={{width: "320px"}}>
+        <div style={{marginBottom: "20px"}}>
+          {i18next.t("login:Enter the code displayed on your device")}
+        </div>
+        <Input size="large" value={this.state.userCode} placeholder="XXXX-XXXX" style={{textAlign: "center"}} onChange={e => {
+          this.setState({
+            userCode: e.target.value.toUpperCase(),
+          });
+        }} onPressEnter={() => this.getDeviceAuth()} />
+        <Button type="primary" size="large" style={{marginTop: "20px", width: "100%"}} onClick={() => this.getDeviceAuth()}>
+          {i18next.t("code:Next")}
+        </Button>
+      </div>
+    )
+  }
+
+  renderConfirm(application) {
+    return (
+      <div style={{width: "320px"}}>
+        {
+          Setting.renderLogo(application)
+        }
+        <div style={{marginBottom: "20px"}}>
+          {i18next.t("login:Do you want to sign in to this application on your device?")}
+        </div>
+        <div style={{fontSize: "20px", fontWeight: "bold", marginBottom: "20px"}}>
+          {application.displayName}
+        </div>
+        <Button type="primary" size="large" style={{width: "100%"}} onClick={() => this.verifyDeviceAuth(true)}>
+          {i18next.t("login:Allow")}
+        </Button>
+        <Button size="large" style={{marginTop: "10px", width: "100%"}} onClick={() => this.verifyDeviceAuth(false)}>
+          {i18next.t("login:Deny")}
+        </Button>
+      </div>
+    )
+  }
+
+  render() {
+    if (this.state.result !== null) {
+      return (
+        <Result
+          status={this.state.result === "approved" ? "success" : "warning"}
+          title={this.state.result === "approved" ? i18next.t("login:Your device is now signed in") : i18next.t("login:The device sign in has been denied")}
+          subTitle={i18next.t("login:You can close this page and return to your device")}
+        />
+      )
+    }
+
+    return (
+      <Row>
+        <Col span={24} style={{display: "flex", justifyContent: "center"}}>
+          <div style={{marginTop: "80px", marginBottom: "50px", textAlign: "center"}}>
+            {
+              this.state.application === null ? this.renderUserCodeInput() : this.renderConfirm(this.state.application)
+            }
+          </div>
+        </Col>
+      </Row>
+    )
+  }
+}
+
+export default DeviceAuthPage;
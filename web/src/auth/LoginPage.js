@@ -89,6 +89,13 @@ class LoginPage extends React.Component {
     AuthBackend.getApplicationLogin(oAuthParams)
       .then((res) => {
         if (res.status === "ok") {
+          if (oAuthParams?.requestUri && res.data2) {
+            // pushed authorization request: show the pushed parameters in place of the request_uri ones,
+            // the backend still only trusts the parameters stored for the request_uri
+            const params = new URLSearchParams(res.data2);
+            params.set("request_uri", oAuthParams.requestUri);
+            window.history.replaceState(null, "", `${window.location.pathname}?${params.toString()}`);
+          }
           this.setState({
             application: res.data,
           });
@@ -222,14 +229,17 @@ class LoginPage extends React.Component {
               }
 
               // Util.showMessage("success", `Authorization code: ${res.data}`);
-            } else if (responseType === "token" || responseType === "id_token") {
-              const accessToken = res.data;
-              Setting.goToLink(`${oAuthParams.redirectUri}#${responseType}=${accessToken}?state=${oAuthParams.state}&token_type=bearer`);
+            } else if (Util.isImplicitResponseType(responseType)) {
+              Util.sendAuthorizationResponse(oAuthParams.redirectUri, res.data2, res.data);
             } else if (responseType === "saml") {
               const SAMLResponse = res.data;
               const redirectUri = res.data2;
               Setting.goToLink(`${redirectUri}?SAMLResponse=${encodeURIComponent(SAMLResponse)}&RelayState=${oAuthParams.relayState}`);
             }
+          } else if (res.data === "consent_required") {
+            Util.goToConsentPage(ths, oAuthParams);
+          } else if (res.data === "login_required" || res.data === "unmet_authentication_requirements") {
+            Util.goToLoginPage(ths, oAuthParams, res.data);
           } else {
             Util.showMessage("error", `Failed to log in: ${res.msg}`);
           }
@@ -587,6 +597,12 @@ class LoginPage extends React.Component {
       return null;
     }
 
+    // the request asks the user to sign in again rather than continue as the signed-in user
+    const oAuthParams = Util.getOAuthGetParameters();
+    if (oAuthParams !== null && oAuthParams.prompt.split(" ").includes("login")) {
+      return null;
+    }
+
     const params = new URLSearchParams(this.props.location.search);
     let silentSignin = params.get("silentSignin");
     if (silentSignin !== null) {
@@ -14,6 +14,8 @@
 
 import React from "react";
 import {Alert, Button, message, Result} from "antd";
+import * as Setting from "../Setting";
+import i18next from "i18next";
 
 export function showMessage(type, text) {
   if (type === "success") {
@@ -95,6 +97,7 @@ export function getOAuthGetParameters(params) {
   const queries = (params !== undefined) ? params : new URLSearchParams(window.location.search);
   const clientId = getRefinedValue(queries.get("client_id"));
   const responseType = getRefinedValue(queries.get("response_type"));
+  const responseMode = getRefinedValue(queries.get("response_mode"));
   const redirectUri = getRefinedValue(queries.get("redirect_uri"));
   const scope = getRefinedValue(queries.get("scope"));
   const state = getRefinedValue(queries.get("state"));
@@ -103,6 +106,11 @@ export function getOAuthGetParameters(params) {
   const codeChallenge = getRefinedValue(queries.get("code_challenge"));
   const samlRequest = getRefinedValue(queries.get("SAMLRequest"));
   const relayState = getRefinedValue(queries.get("RelayState"));
+  const requestUri = getRefinedValue(queries.get("request_uri"));
+  const resources = queries.getAll("resource");
+  const prompt = getRefinedValue(queries.get("prompt"));
+  const acrValues = getRefinedValue(queries.get("acr_values"));
+  const maxAge = getRefinedValue(queries.get("max_age"));
 
   if ((clientId === undefined || clientId === null || clientId === "") && (samlRequest === "" || samlRequest === undefined)) {
     // login
@@ -112,7 +120,9 @@ export function getOAuthGetParameters(params) {
     return {
       clientId: clientId,
       responseType: responseType,
+      responseMode: responseMode,
       redirectUri: redirectUri,
+      resources: resources,
       scope: scope,
       state: state,
       nonce: nonce,
@@ -120,10 +130,110 @@ export function getOAuthGetParameters(params) {
       codeChallenge: codeChallenge,
       samlRequest: samlRequest,
       relayState: relayState,
+      requestUri: requestUri,
+      prompt: prompt,
+      acrValues: acrValues,
+      maxAge: maxAge,
     };
   }
 }
 
+// returns the query of the authorization request in the parameter names of the authorization endpoint
+export function getOAuthQuery(oAuthParams) {
+  const params = new URLSearchParams();
+  const names = {
+    clientId: "client_id",
+    responseType: "response_type",
+    responseMode: "response_mode",
+    redirectUri: "redirect_uri",
+    scope: "scope",
+    state: "state",
+    nonce: "nonce",
+    challengeMethod: "code_challenge_method",
+    codeChallenge: "code_challenge",
+    requestUri: "request_uri",
+    prompt: "prompt",
+    acrValues: "acr_values",
+    maxAge: "max_age",
+  };
+  for (const key in names) {
+    if (oAuthParams[key]) {
+      params.set(names[key], oAuthParams[key]);
+    }
+  }
+  (oAuthParams.resources || []).forEach(resource => params.append("resource", resource));
+  return `?${params.toString()}`;
+}
+
+// sends an error of the authorization request to the redirect URI, in the response mode of the request
+export function sendAuthorizationError(oAuthParams, error) {
+  let responseMode = oAuthParams.responseMode;
+  if (!responseMode) {
+    responseMode = isImplicitResponseType(oAuthParams.responseType) ? "fragment" : "query";
+  }
+  sendAuthorizationResponse(oAuthParams.redirectUri, responseMode, {error: error, state: oAuthParams.state});
+}
+
+// the authorization request needs the consent of the user: ask the user for it on the consent page,
+// or send the consent_required error to the client if the request shouldn't show any page (prompt=none)
+export function goToConsentPage(ths, oAuthParams) {
+  if (oAuthParams.prompt.split(" ").includes("none")) {
+    sendAuthorizationError(oAuthParams, "consent_required");
+    return;
+  }
+
+  Setting.goToLinkSoft(ths, `/login/oauth/consent${getOAuthQuery(oAuthParams)}`);
+}
+
+// the authorization request needs the user to sign in again, or with another factor: send the error to the client
+// if the request shouldn't show any page (prompt=none), or ask the user to sign in on the login page
+export function goToLoginPage(ths, oAuthParams, error) {
+  if (oAuthParams.prompt.split(" ").includes("none")) {
+    sendAuthorizationError(oAuthParams, error);
+    return;
+  }
+
+  if (error === "login_required") {
+    showMessage("error", i18next.t("login:Please sign in again to continue"));
+  } else {
+    showMessage("error", i18next.t("login:Please sign in with another method as well to continue"));
+  }
+  Setting.goToLinkSoft(ths, `/login/oauth/authorize${getOAuthQuery(oAuthParams)}`);
+}
+
+export function isImplicitResponseType(responseType) {
+  const values = responseType.split(" ");
+  return values.includes("token") || values.includes("id_token");
+}
+
+// sends the authorization response of the implicit flow and the hybrid flow to the redirect URI,
+// in the fragment or by an auto-submitted form for the "form_post" response mode
+export function sendAuthorizationResponse(redirectUri, responseMode, params) {
+  if (responseMode === "form_post") {
+    const form = document.createElement("form");
+    form.method = "post";
+    form.action = redirectUri;
+    for (const key in params) {
+      const input = document.createElement("input");
+      input.type = "hidden";
+      input.name = key;
+      input.value = params[key];
+      form.appendChild(input);
+    }
+    document.body.appendChild(form);
+    form.submit();
+    return;
+  }
+
+  const query = new URLSearchParams(params).toString();
+  if (responseMode === "query") {
+    const concatChar = redirectUri.includes('?') ? '&' : '?';
+    window.location.href = `${redirectUri}${concatChar}${query}`;
+  } else {
+    window.location.href = `${redirectUri}#${query}`;
+  }
+}
+
 export function getQueryParamsToState(applicationName, providerName, method) {
   let query = window.location.search;
   query = `${query}&application=${applicationName}&provider=${providerName}&method=${method}`;
@@ -0,0 +1,56 @@
+// Copyright 2022 The Casdoor Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import * as Setting from "../Setting";
+
+export function getApiResources(owner, page = "", pageSize = "", field = "", value = "", sortField = "", sortOrder = "") {
+  return fetch(`${Setting.ServerUrl}/api/get-api-resources?owner=${owner}&p=${page}&pageSize=${pageSize}&field=${field}&value=${value}&sortField=${sortField}&sortOrder=${sortOrder}`, {
+    method: "GET",
+    credentials: "include"
+  }).then(res => res.json());
+}
+
+export function getApiResource(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/get-api-resource?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "GET",
+    credentials: "include"
+  }).then(res => res.json());
+}
+
+export function updateApiResource(owner, name, apiResource) {
+  let newApiResource = Setting.deepCopy(apiResource);
+  return fetch(`${Setting.ServerUrl}/api/update-api-resource?id=${owner}/${encodeURIComponent(name)}`, {
+    method: 'POST',
+    credentials: 'include',
+    body: JSON.stringify(newApiResource),
+  }).then(res => res.json());
+}
+
+export function addApiResource(apiResource) {
+  let newApiResource = Setting.deepCopy(apiResource);
+  return fetch(`${Setting.ServerUrl}/api/add-api-resource`, {
+    method: 'POST',
+    credentials: 'include',
+    body: JSON.stringify(newApiResource),
+  }).then(res => res.json());
+}
+
+export function deleteApiResource(apiResource) {
+  let newApiResource = Setting.deepCopy(apiResource);
+  return fetch(`${Setting.ServerUrl}/api/delete-api-resource`, {
+    method: 'POST',
+    credentials: 'include',
+    body: JSON.stringify(newApiResource),
+  }).then(res => res.json());
+}
@@ -54,3 +54,17 @@ export function deleteCert(cert) {
     body: JSON.stringify(newCert),
   }).then(res => res.json());
 }
+
+export function prepareCertNextKey(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/prepare-cert-next-key?id=${owner}/${encodeURIComponent(name)}`, {
+    method: 'POST',
+    credentials: 'include',
+  }).then(res => res.json());
+}
+
+export function rotateCert(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/rotate-cert?id=${owner}/${encodeURIComponent(name)}`, {
+    method: 'POST',
+    credentials: 'include',
+  }).then(res => res.json());
+}